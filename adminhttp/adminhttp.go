@@ -0,0 +1,126 @@
+// Package adminhttp provides an http.Handler exposing a configuration parsed by skyconf, and the state of its
+// Refresher, for the admin endpoints of services: the current values of the fields, where they come from, the changes
+// awaiting approval, and endpoints to refresh the fields or roll one back.
+package adminhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/redmatter/go-skyconf"
+	"net/http"
+)
+
+// Handler serves the configuration and the state of the Refresher; see New.
+type Handler struct {
+	cfg  interface{}
+	r    skyconf.Refresher
+	opts []skyconf.Option
+	mux  *http.ServeMux
+}
+
+// New returns a Handler exposing the configuration struct cfg, parsed with the options, and the Refresher r returned
+// by parsing it. It serves:
+//
+//	GET  /config       the current values of the fields, as by skyconf.MarshalConfig, with the values of the fields
+//	                   tagged with `secret` redacted; in YAML if the format query parameter is "yaml", and in JSON
+//	                   otherwise
+//	GET  /status       the state of the fields, including the source, parameter and provenance of their values, as by
+//	                   Refresher.Status
+//	GET  /pending      the changes staged until approved, as by Refresher.Pending
+//	POST /refresh      refreshes all the fields once, as by Refresher.RefreshOnce
+//	POST /rollback     rolls back the field whose ID is given by the id query parameter, as by Refresher.Rollback
+//
+// The paths are relative to the root of the handler; use http.StripPrefix to mount it under another path. The
+// handler does not authenticate the requests, which must be restricted to administrators by the caller.
+func New(cfg interface{}, r skyconf.Refresher, opts ...skyconf.Option) *Handler {
+	h := &Handler{cfg: cfg, r: r, opts: opts, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("GET /config", h.config)
+	h.mux.HandleFunc("GET /status", h.status)
+	h.mux.HandleFunc("GET /pending", h.pending)
+	h.mux.HandleFunc("POST /refresh", h.refresh)
+	h.mux.HandleFunc("POST /rollback", h.rollback)
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+func (h *Handler) config(w http.ResponseWriter, req *http.Request) {
+	format, contentType := skyconf.FormatJSON, "application/json"
+	if req.URL.Query().Get("format") == "yaml" {
+		format, contentType = skyconf.FormatYAML, "application/yaml"
+	}
+
+	data, err := skyconf.MarshalConfig(h.cfg, format, true, h.opts...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+func (h *Handler) status(w http.ResponseWriter, _ *http.Request) {
+	status := h.r.Status()
+	if status == nil {
+		status = []skyconf.FieldStatus{}
+	}
+
+	writeJSON(w, status)
+}
+
+func (h *Handler) pending(w http.ResponseWriter, _ *http.Request) {
+	pending := h.r.Pending()
+	if pending == nil {
+		pending = []skyconf.PendingChange{}
+	}
+
+	writeJSON(w, pending)
+}
+
+func (h *Handler) refresh(w http.ResponseWriter, req *http.Request) {
+	if err := h.r.RefreshOnce(req.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) rollback(w http.ResponseWriter, req *http.Request) {
+	id := req.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.r.Rollback(id); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, skyconf.ErrFieldNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, skyconf.ErrNoHistory):
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON writes the value as an indented JSON document.
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
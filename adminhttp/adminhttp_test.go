@@ -0,0 +1,88 @@
+package adminhttp
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/redmatter/go-skyconf"
+	"github.com/redmatter/go-skyconf/skyconftest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type config struct {
+	DB struct {
+		Host     string `sky:"host,refresh:1m"`
+		Password string `sky:"password,secret"`
+	} `sky:"db"`
+	Level string `sky:"level,default:info"`
+}
+
+func TestHandler(t *testing.T) {
+	source := skyconftest.MapSource(map[string]string{
+		"/db/host":     "db1",
+		"/db/password": "hunter2",
+	})
+	cfg := &config{}
+	r, err := skyconf.Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	server := httptest.NewServer(http.StripPrefix("/debug/skyconf", New(cfg, r)))
+	defer server.Close()
+
+	do := func(method, path string) (*http.Response, string) {
+		req, err := http.NewRequest(method, server.URL+"/debug/skyconf"+path, nil)
+		if !assert.NoError(t, err) {
+			return nil, ""
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			return nil, ""
+		}
+		defer resp.Body.Close()
+
+		var body json.RawMessage
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp, string(body)
+	}
+
+	// The values of secret fields are redacted
+	resp, body := do(http.MethodGet, "/config")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"db": {"host": "db1", "password": "[REDACTED]"}, "level": "info"}`, body)
+
+	resp, body = do(http.MethodGet, "/status")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var status []skyconf.FieldStatus
+	assert.NoError(t, json.Unmarshal([]byte(body), &status))
+	if assert.Len(t, status, 3) {
+		assert.Equal(t, "map", status[0].Source)
+		assert.Equal(t, "/db/host", status[0].Parameter)
+		assert.Equal(t, skyconf.ProvenanceDefault, status[2].Provenance)
+	}
+
+	resp, body = do(http.MethodGet, "/pending")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "[]", body)
+
+	// Refreshes and rollbacks are triggered by POST requests
+	source.Set("/db/host", "db2")
+	resp, _ = do(http.MethodGet, "/refresh")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	resp, _ = do(http.MethodPost, "/refresh")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "db2", cfg.DB.Host)
+
+	resp, _ = do(http.MethodPost, "/rollback?id=host")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "db1", cfg.DB.Host)
+
+	resp, _ = do(http.MethodPost, "/rollback?id=host")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp, _ = do(http.MethodPost, "/rollback?id=unknown")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(http.MethodPost, "/rollback")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,83 @@
+package skyconf
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// AtomicString is a string field that can be read while it is refreshed, without locking the configuration struct.
+// The zero value is an empty string.
+type AtomicString struct {
+	v atomic.Value
+}
+
+// Set implements Setter.
+func (a *AtomicString) Set(value string) error {
+	a.v.Store(value)
+	return nil
+}
+
+// Load returns the current value.
+func (a *AtomicString) Load() string {
+	s, _ := a.v.Load().(string)
+	return s
+}
+
+// String implements fmt.Stringer.
+func (a *AtomicString) String() string {
+	return a.Load()
+}
+
+// AtomicInt is an integer field that can be read while it is refreshed, without locking the configuration struct.
+// Values are parsed as by strconv.ParseInt with base 0.
+type AtomicInt struct {
+	v atomic.Int64
+}
+
+// Set implements Setter.
+func (a *AtomicInt) Set(value string) error {
+	i, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return err
+	}
+
+	a.v.Store(i)
+	return nil
+}
+
+// Load returns the current value.
+func (a *AtomicInt) Load() int64 {
+	return a.v.Load()
+}
+
+// String implements fmt.Stringer.
+func (a *AtomicInt) String() string {
+	return strconv.FormatInt(a.Load(), 10)
+}
+
+// AtomicBool is a boolean field that can be read while it is refreshed, without locking the configuration struct.
+// Values are parsed as by strconv.ParseBool.
+type AtomicBool struct {
+	v atomic.Bool
+}
+
+// Set implements Setter.
+func (a *AtomicBool) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	a.v.Store(b)
+	return nil
+}
+
+// Load returns the current value.
+func (a *AtomicBool) Load() bool {
+	return a.v.Load()
+}
+
+// String implements fmt.Stringer.
+func (a *AtomicBool) String() string {
+	return strconv.FormatBool(a.Load())
+}
@@ -0,0 +1,65 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestAtomicFields(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/banner":  "hello",
+			"/path/limit":   "0x10",
+			"/path/enabled": "true",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	// The struct is not lockable; the atomic fields can be read while they are refreshed
+	cfg := &struct {
+		Banner  AtomicString `sky:"banner,refresh:1m"`
+		Limit   AtomicInt    `sky:"limit,refresh:1m"`
+		Enabled AtomicBool   `sky:"enabled,refresh:1m"`
+		Retries AtomicInt    `sky:"retries,default:3"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "hello", cfg.Banner.Load())
+	assert.Equal(t, int64(16), cfg.Limit.Load())
+	assert.True(t, cfg.Enabled.Load())
+	assert.Equal(t, int64(3), cfg.Retries.Load())
+
+	source.set("/path/banner", "bye")
+	source.set("/path/enabled", "false")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_, _ = cfg.Banner.Load(), cfg.Enabled.Load()
+		}
+	}()
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	wg.Wait()
+
+	assert.Equal(t, "bye", cfg.Banner.Load())
+	assert.False(t, cfg.Enabled.Load())
+
+	str, err := String(cfg, false, true, source)
+	assert.NoError(t, err)
+	assert.Contains(t, str, "id:banner} = bye")
+	assert.Contains(t, str, "id:limit} = 16")
+
+	// Invalid values are rejected, leaving the current value
+	source.set("/path/limit", "many")
+	assert.Error(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, int64(16), cfg.Limit.Load())
+}
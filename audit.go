@@ -0,0 +1,76 @@
+package skyconf
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+)
+
+// AuditEvent describes a value set in a field of the configuration struct from a source, when parsing or on refresh.
+type AuditEvent struct {
+	// Time is the time the value was set.
+	Time time.Time
+	// Field is the name of the field, qualified with those of the enclosing structs, as in "DB.Port".
+	Field string
+	// ID is the id of the field; see the `id` tag.
+	ID string
+	// Source is the ID of the source the value was taken from.
+	Source string
+	// Parameter is the name of the parameter in the source.
+	Parameter string
+	// Stage is StageParse or StageRefresh.
+	Stage Stage
+	// OldHash is the hex-encoded hash of the previous value of the parameter, using the hash function set with
+	// WithHash; empty if the field was not set from a source before.
+	OldHash string
+	// NewHash is the hex-encoded hash of the value of the parameter; empty if the parameter was deleted.
+	NewHash string
+	// Value is the value of the parameter; empty for fields tagged with `secret`, whose changes can only be told by
+	// their hashes.
+	Value string
+	// Secret is true if the field is tagged with `secret`.
+	Secret bool
+	// Deleted is true if the parameter was deleted from the source, and the field reset as set by its `ondelete` tag.
+	Deleted bool
+}
+
+// AuditFunc is called with every value set in a field from a source.
+type AuditFunc func(ctx context.Context, e AuditEvent)
+
+// WithAudit calls the audit function whenever a field is set from a source when parsing, or changed on refresh,
+// including by Receive, so that the changes to the configuration can be recorded in an audit trail. The function is
+// called synchronously, after the field is set; it should not block.
+func WithAudit(fn AuditFunc) Option {
+	return func(o *options) {
+		o.audit = fn
+	}
+}
+
+// audit reports the value of the parameter set in the field from the source, whose previous value hashed to oldHash.
+func (u *updater) audit(ctx context.Context, f *refreshedField, stage Stage, source Source, key, oldHash, value string,
+	deleted bool) {
+
+	if u.opts.audit == nil {
+		return
+	}
+
+	e := AuditEvent{
+		Time:      time.Now(),
+		Field:     f.field.path,
+		ID:        f.field.options.id,
+		Source:    source.ID(),
+		Parameter: key,
+		Stage:     stage,
+		OldHash:   hex.EncodeToString([]byte(oldHash)),
+		Secret:    f.field.options.secret,
+		Deleted:   deleted,
+	}
+	if !deleted {
+		e.NewHash = hex.EncodeToString([]byte(u.opts.hashValue(value)))
+		if !e.Secret {
+			e.Value = value
+		}
+	}
+
+	u.opts.audit(ctx, e)
+}
@@ -0,0 +1,71 @@
+package skyconf
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAudit(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/app/host": "db1", "/app/password": "secret1", "/app/user": "admin"},
+		path:        "/app/",
+		id:          "app",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Host     string `sky:"host,refresh:1m"`
+		Password string `sky:"password,secret,refresh:1m"`
+		User     string `sky:"user,refresh:1m,ondelete:zero"`
+	}{}
+
+	var m sync.Mutex
+	var events []AuditEvent
+	audit := func(_ context.Context, e AuditEvent) {
+		assert.WithinDuration(t, time.Now(), e.Time, time.Second)
+		e.Time = time.Time{}
+		m.Lock()
+		defer m.Unlock()
+		events = append(events, e)
+	}
+	takeEvents := func() (e []AuditEvent) {
+		m.Lock()
+		defer m.Unlock()
+		e, events = events, nil
+		return
+	}
+
+	o := makeOptions(nil)
+	hash := func(value string) string {
+		return hex.EncodeToString([]byte(o.hashValue(value)))
+	}
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithAudit(audit))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.ElementsMatch(t, []AuditEvent{
+		{Field: "Host", ID: "host", Source: "app", Parameter: "/app/host", Stage: StageParse,
+			NewHash: hash("db1"), Value: "db1"},
+		{Field: "Password", ID: "password", Source: "app", Parameter: "/app/password", Stage: StageParse,
+			NewHash: hash("secret1"), Secret: true},
+		{Field: "User", ID: "user", Source: "app", Parameter: "/app/user", Stage: StageParse,
+			NewHash: hash("admin"), Value: "admin"},
+	}, takeEvents())
+
+	// Only the changes are audited on refresh, as are the deletions
+	source.ps = mockParameterStore{"/app/host": "db1", "/app/password": "secret2"}
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	assert.ElementsMatch(t, []AuditEvent{
+		{Field: "Password", ID: "password", Source: "app", Parameter: "/app/password", Stage: StageRefresh,
+			OldHash: hash("secret1"), NewHash: hash("secret2"), Secret: true},
+		{Field: "User", ID: "user", Source: "app", Parameter: "/app/user", Stage: StageRefresh,
+			OldHash: hash("admin"), Deleted: true},
+	}, takeEvents())
+}
@@ -0,0 +1,117 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ssmBatchSize is the maximum number of parameters requested by a call to the GetParameters API.
+const ssmBatchSize = 10
+
+// WithBatchSize sets the number of parameters an SSM source requests in each call to the GetParameters API. It is 10,
+// the maximum allowed by the API, if n is not between 1 and 10.
+func WithBatchSize(n int) SourceOption {
+	return func(o *sourceOptions) {
+		o.batchSize = n
+	}
+}
+
+// WithGetParameterFallback makes an SSM source fetch the parameters of a batch one by one, using the GetParameter API,
+// when the batch fails, such as when access to one of them is denied, so that only the parameters that fail are not
+// fetched. The source then returns the values of the others along with a *PartialFetchError listing those that failed,
+// whose error joins a *ParameterError for each. Batches failing as they are throttled, or as the context is done, are
+// not fetched one by one.
+func WithGetParameterFallback() SourceOption {
+	return func(o *sourceOptions) {
+		o.getParameterFallback = true
+	}
+}
+
+// ParameterError is the error of fetching a parameter on its own, after the batch it was requested with failed; see
+// WithGetParameterFallback.
+type ParameterError struct {
+	// Name is the name of the parameter, followed by its version or label selector, if any.
+	Name string
+	// Err is the error returned by the GetParameter API.
+	Err error
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("failed to get parameter %s: %s", e.Name, e.Err)
+}
+
+func (e *ParameterError) Unwrap() error {
+	return e.Err
+}
+
+// fallBack returns the client to fetch the parameters of the batch that failed with the error one by one; nil if they
+// are not to be, or if the client cannot fetch a single parameter.
+func (s *ssmSource) fallBack(ctx context.Context, client SSMClient, err error) ssmGetParameterClient {
+	if !s.fallback || ctx.Err() != nil || isSSMThrottling(err) {
+		return nil
+	}
+
+	getter, _ := client.(ssmGetParameterClient)
+	return getter
+}
+
+// getEach fetches the parameters one by one using the GetParameter API, adding their values and metadata to those given.
+// Parameters not found are left out, as by GetParameters; the errors of the others are returned in failed. err is only
+// set if the context is done, the parameters left then being unfetched.
+func (s *ssmSource) getEach(ctx context.Context, client ssmGetParameterClient, keys []string, values map[string]string,
+	metadata map[string]Metadata) (failed []*ParameterError, err error) {
+
+	for _, key := range keys {
+		var output *ssmpkg.GetParameterOutput
+		err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+			output, err = client.GetParameter(ctx, &ssmpkg.GetParameterInput{
+				Name:           aws.String(key),
+				WithDecryption: aws.Bool(true),
+			})
+			return
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			var notFound *types.ParameterNotFound
+			var versionNotFound *types.ParameterVersionNotFound
+			if !errors.As(err, &notFound) && !errors.As(err, &versionNotFound) {
+				failed = append(failed, &ParameterError{Name: key, Err: err})
+			}
+			err = nil
+			continue
+		}
+
+		values[key] = aws.ToString(output.Parameter.Value)
+		metadata[key] = parameterMetadata(*output.Parameter)
+	}
+
+	return
+}
+
+// parameterMetadata returns the version, last modified time, type and ARN of the parameter.
+func parameterMetadata(p types.Parameter) Metadata {
+	return Metadata{
+		Version:      p.Version,
+		LastModified: aws.ToTime(p.LastModifiedDate),
+		Type:         string(p.Type),
+		ARN:          aws.ToString(p.ARN),
+	}
+}
+
+// parameterErrors returns the names of the parameters that failed, and their errors joined; nil if none failed.
+func parameterErrors(failed []*ParameterError) (names []string, err error) {
+	errs := make([]error, len(failed))
+	for i, e := range failed {
+		names = append(names, e.Name)
+		errs[i] = e
+	}
+
+	return names, errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSSMSourceBatches(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{})
+	var keys []string
+	for i := range 12 {
+		fake.put(fmt.Sprintf("/app/p%02d", i), fmt.Sprintf("value%d", i))
+		keys = append(keys, fmt.Sprintf("/app/p%02d", i))
+	}
+	keys = append(keys, "/app/missing")
+
+	// The parameters are requested in batches of the size set
+	for _, tt := range []struct {
+		size      int
+		wantCalls int
+	}{
+		{size: 0, wantCalls: 2},
+		{size: 5, wantCalls: 3},
+		{size: 20, wantCalls: 2},
+	} {
+		fake.calls = nil
+		source := SSMSourceWithOptions(fake.client(), "/app", "ssm", WithBatchSize(tt.size)).(MetadataSource)
+		values, _, err := source.SourceWithMetadata(context.Background(), keys)
+		assert.NoError(t, err)
+		assert.Len(t, values, 12)
+		assert.Len(t, fake.calls, tt.wantCalls, "batch size %d", tt.size)
+	}
+
+	// A batch failing fails the fetch
+	fake.denied = map[string]bool{"/app/p03": true}
+	values, _, err := SSMSourceWithOptions(fake.client(), "/app", "ssm").(MetadataSource).
+		SourceWithMetadata(context.Background(), keys)
+	assert.Error(t, err)
+	assert.Nil(t, values)
+
+	// With the fallback, the parameters of the batch are fetched one by one, and only those that fail are not fetched
+	fake.calls = nil
+	source := SSMSourceWithOptions(fake.client(), "/app", "ssm", WithBatchSize(5), WithGetParameterFallback())
+	values, _, err = source.(MetadataSource).SourceWithMetadata(context.Background(), keys)
+	assert.ErrorIs(t, err, ErrPartialFetch)
+	assert.Len(t, values, 11)
+	assert.Equal(t, "value4", values["/app/p04"])
+	assert.NotContains(t, values, "/app/p03")
+	assert.Equal(t, []string{"GetParameters", "GetParameter", "GetParameter", "GetParameter", "GetParameter",
+		"GetParameter", "GetParameters", "GetParameters"}, fake.calls)
+
+	var pe *PartialFetchError
+	if assert.ErrorAs(t, err, &pe) {
+		assert.Equal(t, []string{"/app/p03"}, pe.Unfetched)
+	}
+	var paramErr *ParameterError
+	if assert.ErrorAs(t, err, &paramErr) {
+		assert.Equal(t, "/app/p03", paramErr.Name)
+	}
+	var apiErr smithy.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "AccessDeniedException", apiErr.ErrorCode())
+	}
+
+	// In best-effort mode, only the fields of the parameters that failed fail
+	var cfg struct {
+		P02 string `sky:"p02"`
+		P03 string `sky:"p03,default:fallback"`
+		P04 string `sky:"p04"`
+	}
+	_, err = ParseWithOptions(context.Background(), &cfg, []Source{source}, WithBestEffort(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "value2", cfg.P02)
+	assert.Equal(t, "fallback", cfg.P03)
+	assert.Equal(t, "value4", cfg.P04)
+}
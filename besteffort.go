@@ -0,0 +1,27 @@
+package skyconf
+
+import "context"
+
+// SourceErrorFunc is called with the error that occurred fetching the parameters from a source.
+type SourceErrorFunc func(ctx context.Context, sourceID string, err error)
+
+// WithBestEffort makes parsing carry on when the parameters cannot be fetched from a source, populating the
+// configuration struct from the other sources. Fields that could only be taken from the failed source fall back to
+// their default values, or are left untouched if optional; otherwise parsing fails with the error of the source. The
+// error of each failed source is passed to the report function; if it is nil, the error is logged as a warning.
+func WithBestEffort(report SourceErrorFunc) Option {
+	return func(o *options) {
+		o.bestEffort = true
+		o.reportSource = report
+	}
+}
+
+// reportSourceError reports that the parameters could not be fetched from the source.
+func (o *options) reportSourceError(ctx context.Context, sourceID string, err error) {
+	if o.reportSource == nil {
+		o.logger.WarnContext(ctx, "failed to fetch parameters; carrying on without them", "source", sourceID, "error", err)
+		return
+	}
+
+	o.reportSource(ctx, sourceID, err)
+}
@@ -0,0 +1,56 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBestEffort(t *testing.T) {
+	// A nil parameter store fails every fetch
+	failing := &mockSource{path: "/failing/", id: "failing"}
+	working := &mockSource{ps: mockParameterStore{"/working/param1": "value1"}, path: "/working/", id: "working"}
+
+	var reported []string
+	report := func(_ context.Context, sourceID string, err error) {
+		assert.ErrorIs(t, err, errInvalidSource)
+		reported = append(reported, sourceID)
+	}
+
+	t.Run("fields fall back to defaults", func(t *testing.T) {
+		reported = nil
+		cfg := &struct {
+			Param1 string `sky:"param1"`
+			Param2 string `sky:"param2,source:failing,default:value2"`
+			Param3 string `sky:"param3,source:failing,optional"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{failing, working}, WithBestEffort(report))
+		assert.NoError(t, err)
+		assert.Equal(t, "value1", cfg.Param1)
+		assert.Equal(t, "value2", cfg.Param2)
+		assert.Equal(t, []string{"failing"}, reported)
+	})
+
+	t.Run("required fields fail", func(t *testing.T) {
+		reported = nil
+		cfg := &struct {
+			Param1 string `sky:"param1"`
+			Param2 string `sky:"param2,source:failing"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{failing, working}, WithBestEffort(report))
+		assert.ErrorIs(t, err, ErrGetParameters)
+		assert.ErrorIs(t, err, errInvalidSource)
+		assert.Equal(t, []string{"failing"}, reported)
+	})
+
+	t.Run("without best effort", func(t *testing.T) {
+		cfg := &struct {
+			Param1 string `sky:"param1"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{failing, working})
+		assert.ErrorIs(t, err, ErrGetParameters)
+	})
+}
@@ -0,0 +1,219 @@
+package skyconf
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Binder is implemented by configuration structs with setters generated by skyconfgen; see GenerateBinder. Parse and
+// refreshes set the fields bound to a setter using it instead of reflection.
+type Binder interface {
+	SkyconfBindings() []Binding
+}
+
+// Binding binds the field of a parameter to a setter.
+type Binding struct {
+	// Name is the name of the parameter as tagged, the parts joined by slashes, without the parts set by WithPrefix.
+	Name string
+	// Set decodes the value of the parameter and sets the field.
+	Set func(value string) error
+}
+
+// bindingName returns the name of the parameter of the field in a binding; that of the field as tagged, without the
+// parts the keys of the fields are prefixed with, as the bindings are generated without them.
+func (f fieldInfo) bindingName(prefix []string) string {
+	return strings.Join(f.nameParts[len(prefix):], "/")
+}
+
+// decode decodes the value into the field, using its bound setter if there is one.
+func (f fieldInfo) decode(value string) error {
+	if f.set != nil {
+		return f.set(value)
+	}
+
+	return decodeFieldValue(false, value, f.structField, f.options)
+}
+
+// bind sets the setters of the fields bound by the configuration struct, if it implements Binder, wrapping the errors of
+// numbers out of range into ErrValueOutOfRange. If a binding does not match any field, the generated code is out of
+// date; all the bindings are ignored, and a warning logged.
+func (o *options) bind(cfg interface{}, fields []fieldInfo) {
+	binder, ok := cfg.(Binder)
+	if !ok {
+		return
+	}
+
+	bindings := binder.SkyconfBindings()
+	index := make(map[string]int, len(fields))
+	for i, field := range fields {
+		index[field.bindingName(o.prefix)] = i
+	}
+
+	for _, b := range bindings {
+		if _, ok := index[b.Name]; !ok {
+			o.logger.Warn("ignoring out of date bindings; run skyconfgen to regenerate them", "parameter", b.Name)
+			return
+		}
+	}
+
+	for _, b := range bindings {
+		i, set := index[b.Name], b.Set
+		t := fields[i].structField.Type()
+		fields[i].set = func(value string) error {
+			// Report the numbers out of the range of the field as when it is set using reflection
+			return rangeError(value, t, set(value))
+		}
+	}
+}
+
+// fieldPath identifies a field by its address and type, as the first field of a struct shares its address.
+type fieldPath struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// GenerateBinder writes the source of a SkyconfBindings method for the configuration struct, implementing Binder
+// with a setter for each field that can be set without reflection: strings, booleans, numbers and durations,
+// including named types declared in the package of the struct, that are not reached through a pointer and have no
+// `encoding` tag. It is used by skyconfgen; the tags are validated in the same way as by Parse.
+func GenerateBinder(w io.Writer, pkg string, cfg interface{}, withUntagged bool) (err error) {
+	var fields []fieldInfo
+	fields, err = extractFields(withUntagged, nil, cfg, fieldOptions{})
+	if err != nil {
+		return
+	}
+
+	root := reflect.ValueOf(cfg).Elem()
+	paths := make(map[fieldPath]string)
+	goPaths(root, "c", paths)
+
+	var imports []string
+	used := make(map[string]bool)
+	var body strings.Builder
+	for _, field := range fields {
+		if field.subtree || field.options.encoding != "" || !field.structField.CanAddr() {
+			continue
+		}
+
+		path, ok := paths[fieldPath{field.structField.UnsafeAddr(), field.structField.Type()}]
+		if !ok {
+			continue
+		}
+
+		setter, pkgs, ok := generateSetter(path, field.structField, root.Type().PkgPath())
+		if !ok {
+			continue
+		}
+
+		for _, p := range pkgs {
+			if !used[p] {
+				used[p] = true
+				imports = append(imports, p)
+			}
+		}
+
+		body.WriteString("{Name: " + strconv.Quote(field.bindingName(nil)) + ", Set: " + setter + "},\n")
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by skyconfgen. DO NOT EDIT.\n\npackage " + pkg + "\n\nimport (\n")
+	for _, p := range imports {
+		src.WriteString(strconv.Quote(p) + "\n")
+	}
+	src.WriteString("\n\"github.com/redmatter/go-skyconf\"\n)\n\n")
+
+	name := root.Type().Name()
+	src.WriteString("// SkyconfBindings returns the setters of the fields of " + name + " bound to their parameters.\n")
+	src.WriteString("func (c *" + name + ") SkyconfBindings() []skyconf.Binding {\nreturn []skyconf.Binding{\n")
+	src.WriteString(body.String())
+	src.WriteString("}\n}\n")
+
+	var formatted []byte
+	if formatted, err = format.Source(src.Bytes()); err != nil {
+		err = fmt.Errorf("failed to format the generated source: %w", err)
+		return
+	}
+
+	_, err = w.Write(formatted)
+	return
+}
+
+// goPaths records the Go expressions of the exported fields of the struct, and of the structs nested in it, that are
+// not reached through a pointer.
+func goPaths(s reflect.Value, prefix string, paths map[fieldPath]string) {
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+
+		path := prefix + "." + s.Type().Field(i).Name
+		paths[fieldPath{f.UnsafeAddr(), f.Type()}] = path
+
+		if f.Kind() == reflect.Struct {
+			goPaths(f, path, paths)
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// generateSetter returns the source of a setter of the field at the path, and the packages it uses. It returns false
+// if the field cannot be set without reflection.
+func generateSetter(path string, field reflect.Value, pkgPath string) (setter string, pkgs []string, ok bool) {
+	t := field.Type()
+
+	// Types that decode themselves, or have a registered decoder, are left to reflection
+	if decodesItself(field) {
+		return
+	}
+
+	// The type must be predeclared, time.Duration, or declared in the package of the struct
+	typ := t.Name()
+	switch {
+	case t == durationType:
+		setter = "func(v string) error {\nd, err := time.ParseDuration(v)\nif err == nil {\n" + path +
+			" = d\n}\nreturn err\n}"
+		return setter, []string{"time"}, true
+	case t.PkgPath() == "":
+	case t.PkgPath() == pkgPath:
+	default:
+		return
+	}
+
+	// parsed is the type of the value parsed, which is converted to that of the field if it differs
+	var parse, parsed string
+	switch t.Kind() {
+	case reflect.String:
+		value := "v"
+		if typ != "string" {
+			value = typ + "(v)"
+		}
+		return "func(v string) error {\n" + path + " = " + value + "\nreturn nil\n}", nil, true
+	case reflect.Bool:
+		parse, parsed = "strconv.ParseBool(v)", "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parse, parsed = "strconv.ParseInt(v, 0, "+strconv.Itoa(t.Bits())+")", "int64"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parse, parsed = "strconv.ParseUint(v, 0, "+strconv.Itoa(t.Bits())+")", "uint64"
+	case reflect.Float32, reflect.Float64:
+		parse, parsed = "strconv.ParseFloat(v, "+strconv.Itoa(t.Bits())+")", "float64"
+	default:
+		return
+	}
+
+	value := "x"
+	if typ != parsed {
+		value = typ + "(x)"
+	}
+
+	setter = "func(v string) error {\nx, err := " + parse + "\nif err == nil {\n" + path + " = " + value +
+		"\n}\nreturn err\n}"
+	return setter, []string{"strconv"}, true
+}
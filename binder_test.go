@@ -0,0 +1,157 @@
+package skyconf
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type generatedLevel string
+
+type generatedConfig struct {
+	Level   generatedLevel `sky:"level"`
+	Timeout time.Duration  `sky:"timeout"`
+	DB      struct {
+		Host string `sky:"host"`
+		Port uint16 `sky:"port"`
+		Key  []byte `sky:"key,encoding:hex"`
+	} `sky:"db"`
+	Cache *struct {
+		TTL int `sky:"ttl"`
+	} `sky:"cache"`
+	Ratio float64
+}
+
+func TestGenerateBinder(t *testing.T) {
+	var buf bytes.Buffer
+	if !assert.NoError(t, GenerateBinder(&buf, "config", &generatedConfig{}, true)) {
+		return
+	}
+
+	assert.Equal(t, `// Code generated by skyconfgen. DO NOT EDIT.
+
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/redmatter/go-skyconf"
+)
+
+// SkyconfBindings returns the setters of the fields of generatedConfig bound to their parameters.
+func (c *generatedConfig) SkyconfBindings() []skyconf.Binding {
+	return []skyconf.Binding{
+		{Name: "level", Set: func(v string) error {
+			c.Level = generatedLevel(v)
+			return nil
+		}},
+		{Name: "timeout", Set: func(v string) error {
+			d, err := time.ParseDuration(v)
+			if err == nil {
+				c.Timeout = d
+			}
+			return err
+		}},
+		{Name: "db/host", Set: func(v string) error {
+			c.DB.Host = v
+			return nil
+		}},
+		{Name: "db/port", Set: func(v string) error {
+			x, err := strconv.ParseUint(v, 0, 16)
+			if err == nil {
+				c.DB.Port = uint16(x)
+			}
+			return err
+		}},
+		{Name: "Ratio", Set: func(v string) error {
+			x, err := strconv.ParseFloat(v, 64)
+			if err == nil {
+				c.Ratio = x
+			}
+			return err
+		}},
+	}
+}
+`, buf.String())
+
+	// Tag errors are reported when generating
+	err := GenerateBinder(&buf, "config", &struct {
+		Level string `sky:"level,refresh:soon"`
+	}{}, false)
+	assert.ErrorIs(t, err, ErrBadTags)
+}
+
+// boundConfig implements Binder, counting the values set by its setters.
+type boundConfig struct {
+	Level string `sky:"level"`
+	Port  int    `sky:"port"`
+	bound int
+	name  string
+}
+
+func (c *boundConfig) SkyconfBindings() []Binding {
+	return []Binding{{Name: c.name, Set: func(v string) error {
+		c.Level = v
+		c.bound++
+		return nil
+	}}}
+}
+
+func TestParseWithBinder(t *testing.T) {
+	source := &mockSource{
+		ps:   mockParameterStore{"/path/level": "info", "/path/port": "80"},
+		path: "/path/",
+	}
+
+	cfg := &boundConfig{name: "level"}
+	_, err := Parse(context.Background(), cfg, false, source)
+	assert.NoError(t, err)
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, 80, cfg.Port)
+	assert.Equal(t, 1, cfg.bound)
+
+	// The bindings match the fields whose keys are prefixed
+	cfg = &boundConfig{name: "level"}
+	_, err = ParseWithOptions(context.Background(), cfg, []Source{&mockSource{
+		ps:   mockParameterStore{"/svc/level": "debug", "/svc/port": "81"},
+		path: "/",
+	}}, WithPrefix("svc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, 1, cfg.bound)
+
+	// Bindings that do not match the fields are ignored
+	cfg = &boundConfig{name: "renamed"}
+	_, err = Parse(context.Background(), cfg, false, source)
+	assert.NoError(t, err)
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, 0, cfg.bound)
+}
+
+// narrowConfig implements Binder as generated by skyconfgen.
+type narrowConfig struct {
+	Small int8 `sky:"small"`
+}
+
+func (c *narrowConfig) SkyconfBindings() []Binding {
+	return []Binding{{Name: "small", Set: func(v string) error {
+		x, err := strconv.ParseInt(v, 0, 8)
+		if err == nil {
+			c.Small = int8(x)
+		}
+		return err
+	}}}
+}
+
+func TestBinderRangeError(t *testing.T) {
+	source := &mockSource{ps: mockParameterStore{"/path/small": "300"}, path: "/path/"}
+
+	// Numbers out of range are reported in the same way as for the fields set using reflection
+	_, err := Parse(context.Background(), &narrowConfig{}, false, source)
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
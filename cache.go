@@ -0,0 +1,154 @@
+package skyconf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrCacheMiss is returned by a Cache when it holds no values for a source.
+var ErrCacheMiss = errors.New("no cached values")
+
+// Cache stores the values last fetched from each source, so that parsing can fall back to them when a source is
+// unreachable; see WithCache.
+type Cache interface {
+	// Load returns the values stored for the source, and the time they were stored. It returns ErrCacheMiss if no
+	// values are stored for the source.
+	Load(ctx context.Context, sourceID string) (values map[string]string, stored time.Time, err error)
+	// Store replaces the values stored for the source.
+	Store(ctx context.Context, sourceID string, values map[string]string) error
+}
+
+// WithCache makes parsing store the values fetched from each source in the cache, and fall back to the values stored
+// for a source when fetching from it fails, provided they are no older than maxAge; they are used whatever their age
+// if maxAge is 0. Fields set from cached values are reported as stale by Refresher.Status until they are refreshed.
+// Note that the values of fields tagged with `secret` are cached along with the others.
+func WithCache(c Cache, maxAge time.Duration) Option {
+	return func(o *options) {
+		o.cache = c
+		o.cacheMaxAge = maxAge
+	}
+}
+
+// storeCached stores the values fetched from the source in the cache, if any; failing to do so is only logged.
+func (o *options) storeCached(ctx context.Context, source Source, values map[string]string) {
+	if o.cache == nil {
+		return
+	}
+
+	if err := o.cache.Store(ctx, source.ID(), values); err != nil {
+		o.logger.WarnContext(ctx, "failed to cache parameters", "source", source.ID(), "error", err)
+	}
+}
+
+// loadCached returns the values of the keys stored in the cache for the source, if any, and not older than the
+// maximum age. ok is false if there are no such values.
+func (o *options) loadCached(ctx context.Context, source Source, keys []string) (values map[string]string, ok bool) {
+	if o.cache == nil {
+		return
+	}
+
+	cached, stored, err := o.cache.Load(ctx, source.ID())
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			o.logger.WarnContext(ctx, "failed to load cached parameters", "source", source.ID(), "error", err)
+		}
+		return
+	}
+
+	if age := time.Since(stored); o.cacheMaxAge > 0 && age > o.cacheMaxAge {
+		o.logger.WarnContext(ctx, "cached parameters are too old", "source", source.ID(), "age", age)
+		return
+	}
+
+	values = make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, found := cached[key]; found {
+			values[key] = value
+		}
+	}
+
+	o.logger.WarnContext(ctx, "using cached parameters", "source", source.ID(), "stored", stored)
+
+	return values, true
+}
+
+// fileCache is a Cache storing the values of each source in a JSON file.
+type fileCache struct {
+	dir string
+}
+
+// cachedValues is the content of a file of a fileCache.
+type cachedValues struct {
+	Stored time.Time         `json:"stored"`
+	Values map[string]string `json:"values"`
+}
+
+// FileCache returns a Cache storing the values of each source in a JSON file in the directory, readable only by the
+// current user. The directory is created if it does not exist.
+func FileCache(dir string) Cache {
+	return &fileCache{dir: dir}
+}
+
+func (c *fileCache) path(sourceID string) string {
+	return filepath.Join(c.dir, url.PathEscape(sourceID)+".json")
+}
+
+func (c *fileCache) Load(_ context.Context, sourceID string) (values map[string]string, stored time.Time, err error) {
+	var data []byte
+	data, err = os.ReadFile(c.path(sourceID))
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrCacheMiss
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	var cv cachedValues
+	if err = json.Unmarshal(data, &cv); err != nil {
+		err = fmt.Errorf("invalid cache file for source '%s' : %w", sourceID, err)
+		return
+	}
+
+	return cv.Values, cv.Stored, nil
+}
+
+func (c *fileCache) Store(_ context.Context, sourceID string, values map[string]string) (err error) {
+	if err = os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+
+	var data []byte
+	data, err = json.Marshal(cachedValues{Stored: time.Now(), Values: values})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first, so that the file is replaced atomically
+	var f *os.File
+	f, err = os.CreateTemp(c.dir, ".skyconf-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return
+	}
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(f.Name(), c.path(sourceID))
+}
@@ -0,0 +1,81 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := FileCache(filepath.Join(dir, "cache"))
+
+	type config struct {
+		Param1 string `sky:"param1,refresh:1m"`
+	}
+
+	working := &mockSource{ps: mockParameterStore{"/path/param1": "value1"}, path: "/path/", refreshable: true}
+	failing := &mockSource{path: "/path/", refreshable: true}
+
+	// Nothing cached yet
+	_, err := ParseWithOptions(context.Background(), &config{}, []Source{failing}, WithCache(cache, 0))
+	assert.ErrorIs(t, err, errInvalidSource)
+
+	// The values fetched are cached, with the file readable only by the user
+	cfg := &config{}
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{working}, WithCache(cache, time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, r.Status()[0].Stale)
+
+	info, err := os.Stat(filepath.Join(dir, "cache", "mock.json"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	// Parsing falls back to the cached values, which are stale until refreshed
+	cfg = &config{}
+	r, err = ParseWithOptions(context.Background(), cfg, []Source{failing}, WithCache(cache, time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "value1", cfg.Param1)
+	assert.True(t, r.Status()[0].Stale)
+
+	failing.ps = mockParameterStore{"/path/param1": "value1"}
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.False(t, r.Status()[0].Stale)
+
+	// Cached values older than the maximum age are not used
+	time.Sleep(10 * time.Millisecond)
+	failing.ps = nil
+	_, err = ParseWithOptions(context.Background(), &config{}, []Source{failing}, WithCache(cache, time.Millisecond))
+	assert.ErrorIs(t, err, errInvalidSource)
+}
+
+func TestWithCacheConditional(t *testing.T) {
+	cache := FileCache(filepath.Join(t.TempDir(), "cache"))
+
+	type config struct {
+		Auth       string `sky:"auth"`
+		AuthSecret string `sky:"auth_secret,when:Auth=token"`
+	}
+
+	// The values fetched in all the rounds are cached together
+	source := &mockSource{path: "/", ps: mockParameterStore{"/auth": "token", "/auth_secret": "s3cr3t"}}
+	_, err := ParseWithOptions(context.Background(), &config{}, []Source{source}, WithCache(cache, time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	source.ps = nil
+	var cfg config
+	_, err = ParseWithOptions(context.Background(), &cfg, []Source{source}, WithCache(cache, time.Hour))
+	if assert.NoError(t, err) {
+		assert.Equal(t, config{Auth: "token", AuthSecret: "s3cr3t"}, cfg)
+	}
+}
@@ -0,0 +1,143 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"strings"
+	"sync"
+)
+
+// WithChangeDetection makes an SSM source check the version of the parameters using the DescribeParameters API before
+// fetching them, and only fetch the parameters that changed since they were last fetched. For large sets of refreshed
+// parameters, this reduces the number of values decrypted, and therefore KMS calls made, for SecureString parameters.
+// Parameters requested by version or label are always fetched.
+func WithChangeDetection() SourceOption {
+	return func(o *sourceOptions) {
+		o.detectChanges = true
+	}
+}
+
+// cachedParameter is the value of a parameter as last fetched.
+type cachedParameter struct {
+	value    string
+	metadata Metadata
+}
+
+// parameterCache holds the values of the parameters last fetched by a source.
+type parameterCache struct {
+	m          sync.Mutex
+	parameters map[string]cachedParameter
+}
+
+func newParameterCache() *parameterCache {
+	return &parameterCache{parameters: make(map[string]cachedParameter)}
+}
+
+// versions returns the versions of the cached parameters among the keys.
+func (c *parameterCache) versions(keys []string) map[string]int64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	versions := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		if p, ok := c.parameters[key]; ok {
+			versions[key] = p.metadata.Version
+		}
+	}
+
+	return versions
+}
+
+// update records the values of the fetched keys, forgetting those that were not found, and returns the values of all
+// the keys that are known.
+func (c *parameterCache) update(keys, fetched []string, values map[string]string,
+	metadata map[string]Metadata) (map[string]string, map[string]Metadata) {
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for _, key := range fetched {
+		if value, ok := values[key]; ok {
+			c.parameters[key] = cachedParameter{value: value, metadata: metadata[key]}
+		} else {
+			delete(c.parameters, key)
+		}
+	}
+
+	values = make(map[string]string, len(keys))
+	metadata = make(map[string]Metadata, len(keys))
+	for _, key := range keys {
+		if p, ok := c.parameters[key]; ok {
+			values[key] = p.value
+			metadata[key] = p.metadata
+		}
+	}
+
+	return values, metadata
+}
+
+// changedKeys returns the keys of the parameters that are not cached, or whose version has changed since they were
+// cached, using the DescribeParameters API; all the keys if the client does not implement it.
+func (s *ssmSource) changedKeys(ctx context.Context, client SSMClient, keys []string) (changed []string, err error) {
+	describer, ok := client.(ssmpkg.DescribeParametersAPIClient)
+	if !ok {
+		return keys, nil
+	}
+
+	versions := s.cache.versions(keys)
+
+	// Collect the names of the cached parameters to check; parameters requested by version or label are not checked
+	var names []string
+	for _, key := range keys {
+		if _, ok := versions[key]; ok && !strings.Contains(key, ":") {
+			names = append(names, key)
+		} else {
+			changed = append(changed, key)
+		}
+	}
+
+	// Loop over the names in batches of 50; AWS SSM DescribeParameters API has a limit of 50 values per filter
+	current := make(map[string]int64, len(names))
+	for i := 0; i < len(names); i += 50 {
+		end := i + 50
+		if end > len(names) {
+			end = len(names)
+		}
+
+		paginator := ssmpkg.NewDescribeParametersPaginator(describer, &ssmpkg.DescribeParametersInput{
+			ParameterFilters: []types.ParameterStringFilter{{
+				Key:    aws.String("Name"),
+				Option: aws.String("Equals"),
+				Values: names[i:end],
+			}},
+		})
+
+		for paginator.HasMorePages() {
+			var output *ssmpkg.DescribeParametersOutput
+			err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+				output, err = paginator.NextPage(ctx)
+				return
+			})
+			if err != nil {
+				err = fmt.Errorf("failed to describe parameters: %w", err)
+				return
+			}
+
+			for _, p := range output.Parameters {
+				current[aws.ToString(p.Name)] = p.Version
+			}
+		}
+	}
+
+	// Fetch the parameters whose version has changed, including those that no longer exist
+	for _, name := range names {
+		if version, ok := current[name]; !ok || version != versions[name] {
+			changed = append(changed, name)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,112 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"strings"
+)
+
+// SSMClient is the part of the SSM API that SSM sources require, implemented by *ssm.Client. It can be implemented by
+// wrappers of the client, such as for caching, metrics or mocking, to create sources using SSMSourceWithClient. The
+// client is also used to check the versions of the parameters, as set by WithChangeDetection, if it implements
+// ssm.DescribeParametersAPIClient, and to fetch the parameters one by one, as set by WithGetParameterFallback, if it
+// implements the GetParameter method of *ssm.Client; parameters are otherwise always fetched in batches.
+type SSMClient interface {
+	ssmpkg.GetParametersByPathAPIClient
+	GetParameters(ctx context.Context, params *ssmpkg.GetParametersInput,
+		optFns ...func(*ssmpkg.Options)) (*ssmpkg.GetParametersOutput, error)
+}
+
+// ssmGetParameterClient is an SSMClient that can fetch a single parameter; see WithGetParameterFallback.
+type ssmGetParameterClient interface {
+	GetParameter(ctx context.Context, params *ssmpkg.GetParameterInput,
+		optFns ...func(*ssmpkg.Options)) (*ssmpkg.GetParameterOutput, error)
+}
+
+// ssmClientRef holds the client of an SSM source, which may be nil.
+type ssmClientRef struct {
+	client SSMClient
+}
+
+// SSMSourceWithClient creates a new SSM source with a custom ID, fetching the parameters using the client, configured
+// using the provided options. The options set by WithSSMClientOptions only apply if the client is an *ssm.Client.
+func SSMSourceWithClient(client SSMClient, path, id string, opts ...SourceOption) Source {
+	// ensure path ends with a slash
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	o := makeSourceOptions(opts)
+	s := &ssmSource{
+		path:           path,
+		id:             id,
+		verbatim:       o.verbatimKeys,
+		streaming:      o.streaming,
+		partialResults: o.partialResults,
+		batchSize:      o.batchSize,
+		fallback:       o.getParameterFallback,
+		limiter:        newLimiter(o),
+		clientOptions:  o.clientOptions,
+	}
+	if s.batchSize < 1 || s.batchSize > ssmBatchSize {
+		s.batchSize = ssmBatchSize
+	}
+	s.limiter.throttling = isSSMThrottling
+	s.bind(client)
+
+	if o.detectChanges {
+		s.cache = newParameterCache()
+	}
+	if o.negativeRecheck > 0 {
+		s.missing = newMissingCache(o.negativeRecheck)
+	}
+
+	return s
+}
+
+// RebindSSMClient replaces the client of the SSM source with the given one, as RebindSSM does.
+func RebindSSMClient(source Source, client SSMClient) error {
+	switch s := source.(type) {
+	case *ssmSource:
+		s.bind(client)
+		return nil
+	case *mergeSource:
+		// Rebind the layers of an SSMLayeredSource, which share their client; other merged sources may use several
+		if !s.ssmLayered {
+			return fmt.Errorf("%w: %s", ErrNotSSMSource, source.ID())
+		}
+		for _, layer := range s.sources {
+			layer.(*ssmSource).bind(client)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrNotSSMSource, source.ID())
+	}
+}
+
+// bind makes the source use the client, with the client options of the source applied if it is an *ssm.Client.
+func (s *ssmSource) bind(client SSMClient) {
+	if c, ok := client.(*ssmpkg.Client); ok {
+		switch {
+		case c == nil:
+			client = nil
+		case len(s.clientOptions) > 0:
+			client = ssmpkg.New(c.Options(), s.clientOptions...)
+		}
+	}
+
+	s.client.Store(&ssmClientRef{client: client})
+}
+
+// ssmClient returns the client of the source, or an error if it is nil.
+func (s *ssmSource) ssmClient() (client SSMClient, err error) {
+	if ref := s.client.Load(); ref != nil {
+		client = ref.client
+	}
+	if client == nil {
+		err = fmt.Errorf("ssm client is nil")
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package skyconf
+
+import (
+	"context"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// countingClient is an SSMClient wrapping another, counting the calls made; it implements the minimal interface only.
+type countingClient struct {
+	client SSMClient
+	calls  int
+}
+
+func (c *countingClient) GetParameters(ctx context.Context, params *ssmpkg.GetParametersInput,
+	optFns ...func(*ssmpkg.Options)) (*ssmpkg.GetParametersOutput, error) {
+
+	c.calls++
+	return c.client.GetParameters(ctx, params, optFns...)
+}
+
+func (c *countingClient) GetParametersByPath(ctx context.Context, params *ssmpkg.GetParametersByPathInput,
+	optFns ...func(*ssmpkg.Options)) (*ssmpkg.GetParametersByPathOutput, error) {
+
+	c.calls++
+	return c.client.GetParametersByPath(ctx, params, optFns...)
+}
+
+func TestSSMSourceWithClient(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{
+		"/app/host": {Value: "db1", Version: 1},
+		"/app/port": {Value: "5432", Version: 1},
+	})
+	client := &countingClient{client: fake.client()}
+
+	var cfg struct {
+		Host string `sky:"host,refresh:1m"`
+		Port int    `sky:"port"`
+	}
+	r, err := Parse(context.Background(), &cfg, false, SSMSourceWithClient(client, "/app", "ssm", WithChangeDetection()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "db1", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, 1, client.calls)
+
+	// Changes cannot be detected without the DescribeParameters API, so the parameters are fetched again
+	fake.put("/app/host", "db2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "db2", cfg.Host)
+	assert.NotContains(t, fake.calls, "DescribeParameters")
+
+	// Sources can be rebound to clients of any type
+	source := SSMSource(fake.client(), "/app")
+	assert.NoError(t, RebindSSMClient(source, client))
+	calls := client.calls
+	_, err = source.Source(context.Background(), []string{"/app/host"})
+	assert.NoError(t, err)
+	assert.Equal(t, calls+1, client.calls)
+
+	// A nil client fails the fetches
+	_, err = SSMSourceWithClient(nil, "/app", "ssm").Source(context.Background(), []string{"/app/host"})
+	assert.ErrorContains(t, err, "ssm client is nil")
+}
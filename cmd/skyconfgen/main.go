@@ -0,0 +1,117 @@
+// Command skyconfgen generates the setters of the fields of a configuration struct, so that Parse and refreshes set
+// them without reflection. It is meant to be run by go generate, from the package declaring the struct:
+//
+//	//go:generate go run github.com/redmatter/go-skyconf/cmd/skyconfgen -type Config
+//
+// The tags of the struct are validated while generating, so tag errors are reported by go generate rather than by
+// Parse at runtime. The package of the struct must be importable; structs declared in main packages are not supported.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var program = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redmatter/go-skyconf"
+	pkg {{ .ImportPath }}
+)
+
+func main() {
+	if err := skyconf.GenerateBinder(os.Stdout, {{ .Package }}, &pkg.{{ .Type }}{}, {{ .Untagged }}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+func main() {
+	typeName := flag.String("type", "", "name of the configuration struct; required")
+	untagged := flag.Bool("untagged", false, "include the fields without a sky tag, as Parse does withUntagged")
+	output := flag.String("output", "", "output file name; default <type>_skyconf.go")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *output == "" {
+		*output = strings.ToLower(*typeName) + "_skyconf.go"
+	}
+
+	if err := generate(*typeName, *untagged, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "skyconfgen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate builds and runs a program calling skyconf.GenerateBinder for the type, writing its output to the file.
+func generate(typeName string, untagged bool, output string) (err error) {
+	// The previous output is removed first; if out of date, it may not compile
+	if err = os.Remove(output); err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	var importPath, pkgName []byte
+	if importPath, err = goList("{{.ImportPath}}"); err != nil {
+		return
+	}
+	if pkgName, err = goList("{{.Name}}"); err != nil {
+		return
+	}
+
+	// The program is created in the package directory, so that it builds within the same module
+	var dir string
+	if dir, err = os.MkdirTemp(".", ".skyconfgen"); err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var src bytes.Buffer
+	err = program.Execute(&src, map[string]string{
+		"ImportPath": strconv.Quote(string(importPath)),
+		"Package":    strconv.Quote(string(pkgName)),
+		"Type":       typeName,
+		"Untagged":   strconv.FormatBool(untagged),
+	})
+	if err != nil {
+		return
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0o644); err != nil {
+		return
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("failed to generate the bindings of %s: %w", typeName, err)
+	}
+
+	return os.WriteFile(output, stdout.Bytes(), 0o644)
+}
+
+// goList returns the field of the package in the current directory formatted by go list.
+func goList(format string) ([]byte, error) {
+	out, err := exec.Command("go", "list", "-f", format, ".").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the package: %w", err)
+	}
+
+	return bytes.TrimSpace(out), nil
+}
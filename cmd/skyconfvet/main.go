@@ -0,0 +1,16 @@
+// Command skyconfvet checks the `sky` tags of the structs of the packages given, reporting the tag errors that Parse
+// would otherwise only report at runtime; see package skyconfvet for the checks. It can be run on its own, or as a vet
+// tool:
+//
+//	go run github.com/redmatter/go-skyconf/cmd/skyconfvet ./...
+//	go vet -vettool=$(which skyconfvet) ./...
+package main
+
+import (
+	"github.com/redmatter/go-skyconf/skyconfvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(skyconfvet.Analyzer)
+}
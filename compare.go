@@ -0,0 +1,57 @@
+package skyconf
+
+import (
+	"hash"
+	"hash/crc32"
+	"reflect"
+)
+
+// WithHash sets the hash function used to detect changes to the values obtained from the sources on refresh; a field
+// is only set, and an update notified, if the hash of its value has changed. The default is CRC32, which is cheap but
+// prone to collisions; a cryptographic hash such as SHA-256 can be used to rule them out.
+func WithHash(h func() hash.Hash) Option {
+	return func(o *options) {
+		o.hash = h
+	}
+}
+
+// WithValueComparator sets a function comparing the current value of a field with the value decoded from a changed
+// value obtained from a source on refresh. If it returns true, the values are considered equivalent; the field is left
+// untouched and no update is notified. For example, reflect.DeepEqual can be used to ignore changes that do not affect
+// the decoded value, such as whitespace in a JSON document unmarshalled into a struct.
+func WithValueComparator(equal func(current, updated any) bool) Option {
+	return func(o *options) {
+		o.equal = equal
+	}
+}
+
+// defaultHash is the hash function used to detect changes, unless one is set using WithHash.
+func defaultHash() hash.Hash {
+	return crc32.NewIEEE()
+}
+
+// hashValue returns the hash of the value, used to detect changes.
+func (o *options) hashValue(value string) string {
+	h := o.hash()
+	h.Write([]byte(value))
+	return string(h.Sum(nil))
+}
+
+// setFieldValue decodes the value into the field. If a value comparator is set, the field is only set if the decoded
+// value is not equivalent to its current value, in which case same is true.
+func (o *options) setFieldValue(value string, field fieldInfo) (same bool, err error) {
+	if o.equal != nil {
+		updated := reflect.New(field.structField.Type()).Elem()
+		if err = decodeFieldValue(false, value, updated, field.options); err != nil {
+			return
+		}
+
+		if o.equal(field.structField.Interface(), updated.Interface()) {
+			same = true
+			return
+		}
+	}
+
+	err = field.decode(value)
+	return
+}
@@ -0,0 +1,61 @@
+package skyconf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// jsonConfig is a struct decoded from a JSON document.
+type jsonConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func (j *jsonConfig) UnmarshalText(text []byte) error {
+	type plain jsonConfig
+	return json.Unmarshal(text, (*plain)(j))
+}
+
+func TestValueComparator(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/path/server": `{"host":"localhost","port":80}`},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Server jsonConfig `sky:",refresh:1m"`
+	}{}
+
+	metrics := &mockMetrics{}
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source},
+		WithValueComparator(reflect.DeepEqual), WithHash(sha256.New), WithMetrics(metrics))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, jsonConfig{Host: "localhost", Port: 80}, cfg.Server)
+
+	// A change that does not affect the decoded value is not an update
+	source.set("/path/server", `{ "host": "localhost", "port": 80 }`)
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Empty(t, metrics.updated)
+
+	source.set("/path/server", `{"host":"localhost","port":8080}`)
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, jsonConfig{Host: "localhost", Port: 8080}, cfg.Server)
+	assert.Equal(t, []string{"Server"}, metrics.updated)
+}
+
+func Test_hashValue(t *testing.T) {
+	o := makeOptions(nil)
+	assert.Equal(t, o.hashValue("value"), o.hashValue("value"))
+	assert.NotEqual(t, o.hashValue("value"), o.hashValue("other-value"))
+	assert.Len(t, o.hashValue("value"), 4)
+
+	o = makeOptions([]Option{WithHash(sha256.New)})
+	assert.Len(t, o.hashValue("value"), sha256.Size)
+}
@@ -0,0 +1,59 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRefreshTimeout is the cause of the error passed to the error function of Refresh when fetching the parameters of
+// a refresh takes longer than the timeout set with WithRefreshTimeout.
+var ErrRefreshTimeout = errors.New("refresh timed out")
+
+// WithMaxConcurrentRefreshes bounds the number of refreshes of groups of fields from a source that run at once, across
+// all the sources and refresh intervals, to n. Refreshes beyond the bound wait for a running one to complete. There is
+// no bound by default.
+//
+// Regardless of the bound, a group of fields is not refreshed again at its interval while its previous refresh is still
+// in flight, for example because the source is slow; the tick is skipped instead, so that refreshes do not pile up.
+func WithMaxConcurrentRefreshes(n int) Option {
+	return func(o *options) {
+		o.maxRefreshes = n
+	}
+}
+
+// WithRefreshTimeout bounds the time taken to fetch the parameters of each refresh from a source to d, so that a hung
+// source does not hold the fields until the next tick. A fetch that times out is reported to the error function of
+// Refresh as a *SourceError matching ErrRefreshTimeout and context.DeadlineExceeded, with the time elapsed; the fields
+// keep their values. There is no timeout by default, other than that of the context passed to Refresh.
+func WithRefreshTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.refreshTimeout = d
+	}
+}
+
+// cycleContext returns the context of a refresh, bounded by the refresh timeout, if any.
+func (u *updater) cycleContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if u.opts.refreshTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeoutCause(ctx, u.opts.refreshTimeout,
+		fmt.Errorf("%w after %s", ErrRefreshTimeout, u.opts.refreshTimeout))
+}
+
+// acquire waits for a slot to refresh fields, if the number of concurrent refreshes is bounded, returning a function
+// releasing the slot. It returns false if the context is done first.
+func (u *updater) acquire(ctx context.Context) (release func(), ok bool) {
+	if u.slots == nil {
+		return func() {}, true
+	}
+
+	select {
+	case u.slots <- struct{}{}:
+		return func() { <-u.slots }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
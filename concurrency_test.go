@@ -0,0 +1,157 @@
+package skyconf
+
+import (
+	"bytes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a buffer safe for concurrent use.
+type lockedBuffer struct {
+	m   sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.buf.String()
+}
+
+func TestMaxConcurrentRefreshes(t *testing.T) {
+	u := newUpdater(makeOptions([]Option{WithMaxConcurrentRefreshes(2)}), nil, nil)
+
+	release1, ok := u.acquire(context.Background())
+	assert.True(t, ok)
+	_, ok = u.acquire(context.Background())
+	assert.True(t, ok)
+
+	// A third refresh waits for a slot
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, ok = u.acquire(ctx)
+	assert.False(t, ok)
+
+	release1()
+	_, ok = u.acquire(context.Background())
+	assert.True(t, ok)
+
+	// There is no bound by default
+	u = newUpdater(makeOptions(nil), nil, nil)
+	for i := 0; i < 10; i++ {
+		_, ok = u.acquire(ctx)
+		assert.True(t, ok)
+	}
+}
+
+func TestOverlappingRefreshesSkipped(t *testing.T) {
+	source := &blockingSource{
+		mockSource: &mockSource{
+			ps:          mockParameterStore{"/path/param1": "value1"},
+			path:        "/path/",
+			refreshable: true,
+		},
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1s"`
+	}{}
+
+	var logs lockedBuffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithLogger(logger))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	clock := fakeclock.NewFakeClock(time.Now())
+	r.(*updater).clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Refresh(ctx, nil)
+
+	// Trigger a refresh and wait until it is in flight
+	clock.WaitForWatcherAndIncrement(time.Second + time.Millisecond)
+	<-source.entered
+
+	// The next tick is skipped while the refresh is in flight
+	clock.Increment(time.Second)
+	assert.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "skipping refresh still in flight")
+	}, time.Second, time.Millisecond)
+
+	close(source.release)
+	assert.Eventually(t, func() bool {
+		return !r.(*updater).timings[time.Second][source].running.Load()
+	}, time.Second, time.Millisecond)
+}
+
+// hangingSource is a source whose fetches hang, once set to, until the context is done.
+type hangingSource struct {
+	mockSource
+	hang atomic.Bool
+}
+
+func (h *hangingSource) Source(ctx context.Context, params []string) (map[string]string, error) {
+	if h.hang.Load() {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return h.mockSource.Source(ctx, params)
+}
+
+func TestRefreshTimeout(t *testing.T) {
+	source := &hangingSource{
+		mockSource: mockSource{
+			ps:          mockParameterStore{"/path/param1": "value1"},
+			path:        "/path/",
+			refreshable: true,
+		},
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1s"`
+	}{}
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithRefreshTimeout(20*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	source.hang.Store(true)
+	err = r.RefreshOnce(context.Background())
+	assert.ErrorIs(t, err, ErrRefreshTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var se *SourceError
+	if assert.ErrorAs(t, err, &se) {
+		assert.Equal(t, "mock", se.Source)
+		assert.Equal(t, []string{"Param1"}, se.Fields)
+		assert.GreaterOrEqual(t, se.Elapsed, 20*time.Millisecond)
+	}
+	assert.Equal(t, "value1", cfg.Param1)
+
+	// Cancelling the context of the refresh is not a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = r.RefreshOnce(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrRefreshTimeout)
+}
@@ -3,6 +3,7 @@ package skyconf
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -15,6 +16,11 @@ func (a anyFormatter) Source(_ context.Context, _ []string) (values map[string]s
 }
 
 func (a anyFormatter) ParameterName(parts []string) string {
+	return a.fieldName(fieldInfo{nameParts: parts})
+}
+
+// fieldName returns the names of the parameter of the field in all the sources.
+func (a anyFormatter) fieldName(field fieldInfo) string {
 	var sb strings.Builder
 
 	sb.WriteString("[ ")
@@ -24,7 +30,7 @@ func (a anyFormatter) ParameterName(parts []string) string {
 		if !first {
 			sb.WriteString(", ")
 		}
-		sb.WriteString(f.ID() + ":" + f.ParameterName(parts))
+		sb.WriteString(f.ID() + ":" + field.name(f))
 		first = false
 	}
 	sb.WriteString(" ]")
@@ -40,39 +46,77 @@ func (a anyFormatter) Refreshable() bool {
 	return true
 }
 
+// parameterFormatter returns a function formatting the name of the parameter of a field, prefixed with the ID of the
+// source it is fetched from; the names in all the sources if the field does not specify a source, or in those it
+// specifies, in order, if it specifies several.
+func parameterFormatter(sources []Source) func(field fieldInfo) (string, error) {
+	af := anyFormatter{sources}
+
+	return func(field fieldInfo) (string, error) {
+		ids := field.options.sourceIDs()
+		if len(ids) == 0 {
+			return af.ID() + ":" + af.fieldName(field), nil
+		}
+
+		// Get the formatters for the sources specified.
+		var listed []Source
+		for _, id := range ids {
+			// If we didn't find a formatter, fall back to the last source.
+			i := slices.IndexFunc(sources, func(s Source) bool { return s.ID() == id })
+			if i < 0 {
+				i = len(sources) - 1
+			}
+			listed = append(listed, sources[i])
+		}
+
+		if len(listed) > 1 {
+			lf := anyFormatter{listed}
+			return lf.ID() + ":" + lf.fieldName(field), nil
+		}
+
+		return listed[0].ID() + ":" + field.name(listed[0]), nil
+	}
+}
+
 // String returns a string representation of the provided configuration struct, describing source and parameter name for
-// each field. If withCurrentValue is true, the current value of the field is also included.
+// each field. If withCurrentValue is true, the current value of the field is also included, formatted using
+// encoding.TextMarshaler or fmt.Stringer when the field implements them; values of fields tagged with `secret` are
+// redacted. The description of the field, if any, is appended as a comment. Use StringWithProvenance to also show
+// where the value of each field comes from.
 func String(cfg interface{}, withUntagged bool, withCurrentValue bool, sources ...Source) (str string, err error) {
+	return describeFields(cfg, withUntagged, withCurrentValue, nil, sources)
+}
+
+// StringWithProvenance is like String, but the provenance of the value of each field, as recorded by the Refresher
+// returned when parsing the configuration struct, is also included after the value, if any, as in:
+//
+//	source:parameter -> options = value <- source:parameter # description
+//
+// The provenance is the source and parameter the value was last set from, or where else it comes from, such as
+// "(default)"; see FieldStatus.String. Fields unknown to the Refresher are shown without provenance.
+func StringWithProvenance(cfg interface{}, r Refresher, withUntagged bool, withCurrentValue bool, sources ...Source) (
+	str string, err error) {
+
+	provenance := make(map[string]string)
+	for _, s := range r.Status() {
+		provenance[s.ID] = s.from()
+	}
+
+	return describeFields(cfg, withUntagged, withCurrentValue, provenance, sources)
+}
+
+// describeFields returns the string representation of the configuration struct of String, including the provenance
+// of the fields, by ID, if not nil.
+func describeFields(cfg interface{}, withUntagged bool, withCurrentValue bool, provenance map[string]string,
+	sources []Source) (str string, err error) {
+
 	// Ensure we have a formatter.
 	if len(sources) == 0 {
 		err = fmt.Errorf("no sources provided")
 		return
 	}
 
-	af := anyFormatter{sources}
-
-	// Make formatter func
-	format := func(source string, parts []string, sb *strings.Builder) error {
-		// Get the formatter for the source if specified.
-		var f Source
-		if source != "" {
-			for _, f = range sources {
-				if f.ID() == source {
-					break
-				}
-			}
-
-			// If we didn't find a formatter, return an error.
-			if f == nil {
-				return fmt.Errorf("no formatter found for source %s", source)
-			}
-		} else {
-			f = af
-		}
-
-		sb.WriteString(f.ID() + ":" + f.ParameterName(parts))
-		return nil
-	}
+	format := parameterFormatter(sources)
 
 	var fields []fieldInfo
 	fields, err = extractFields(withUntagged, nil, cfg, fieldOptions{})
@@ -87,15 +131,32 @@ func String(cfg interface{}, withUntagged bool, withCurrentValue bool, sources .
 			sb.Write([]byte{'\n'})
 		}
 
-		if err = format(field.options.source, field.nameParts, &sb); err != nil {
+		var name string
+		if name, err = format(field); err != nil {
 			return
 		}
+		sb.WriteString(name)
 		sb.WriteString(" -> ")
 		sb.WriteString(field.options.String())
 
 		if withCurrentValue {
+			var value string
+			if value, err = formatFieldValue(field.structField); err != nil {
+				return
+			}
+
 			sb.WriteString(" = ")
-			sb.WriteString(fmt.Sprintf("%v", field.structField.Interface()))
+			sb.WriteString(field.logValue(value))
+		}
+
+		if from, ok := provenance[field.options.id]; ok {
+			sb.WriteString(" <- ")
+			sb.WriteString(from)
+		}
+
+		if field.options.doc != "" {
+			sb.WriteString(" # ")
+			sb.WriteString(field.options.description())
 		}
 
 		first = false
@@ -104,3 +165,98 @@ func String(cfg interface{}, withUntagged bool, withCurrentValue bool, sources .
 	str = sb.String()
 	return
 }
+
+// StringWithValues returns a string representation of the provided configuration struct, describing for each field
+// the source and parameter its value is taken from, the value of the parameter in the source and the current value of
+// the field, followed by its description, if any, as in:
+//
+//	id -> source:parameter = source-value -> current-value # description
+//
+// The values are fetched from the sources in the same way as Parse does; the configuration struct is not modified, and
+// is locked for reading while its fields are read, if it implements RLocker or sync.Locker. Values of fields tagged
+// with `secret` are redacted. Fields not found in any source are shown with the parameter names in all the sources they
+// could be taken from, and "(not found)" in place of the value.
+func StringWithValues(ctx context.Context, cfg interface{}, withUntagged bool, sources ...Source) (str string, err error) {
+	var opts []Option
+	if withUntagged {
+		opts = append(opts, WithUntagged())
+	}
+
+	return StringWithValuesWithOptions(ctx, cfg, sources, opts...)
+}
+
+// StringWithValuesWithOptions is like StringWithValues, but the fields are those parsed by ParseWithOptions with the
+// same options.
+func StringWithValuesWithOptions(ctx context.Context, cfg interface{}, sources []Source, opts ...Option) (str string,
+	err error) {
+
+	o := makeOptions(opts)
+
+	if len(sources) == 0 {
+		err = ErrNoSource
+		return
+	}
+
+	// Extract the fields to read them, leaving the nil pointers of the configuration struct untouched
+	l := readLock(cfg)
+	l.Lock()
+	var fields []fieldInfo
+	fields, err = o.readFields(cfg)
+	l.Unlock()
+	if err != nil {
+		return
+	}
+
+	var resolved map[int]resolvedValue
+	resolved, err = resolveValues(ctx, o, sources, fields, func(idx int, _ Source) bool {
+		// Fields populated from subtrees of parameters have no value of their own
+		return !fields[idx].subtree
+	})
+	if err != nil {
+		return
+	}
+
+	// Read the current values while holding the read lock of the configuration struct, if it is lockable
+	l.Lock()
+	defer l.Unlock()
+
+	format := parameterFormatter(sources)
+
+	var sb strings.Builder
+	for idx, field := range fields {
+		if idx > 0 {
+			sb.WriteByte('\n')
+		}
+
+		sb.WriteString(field.options.id)
+		sb.WriteString(" -> ")
+
+		if rv, ok := resolved[idx]; ok {
+			sb.WriteString(rv.source.ID() + ":" + rv.key)
+			sb.WriteString(" = ")
+			sb.WriteString(field.logValue(rv.value))
+		} else {
+			var name string
+			if name, err = format(field); err != nil {
+				return
+			}
+			sb.WriteString(name)
+			sb.WriteString(" = (not found)")
+		}
+
+		var current string
+		if current, err = formatFieldValue(field.structField); err != nil {
+			return
+		}
+		sb.WriteString(" -> ")
+		sb.WriteString(field.logValue(current))
+
+		if field.options.doc != "" {
+			sb.WriteString(" # ")
+			sb.WriteString(field.options.description())
+		}
+	}
+
+	str = sb.String()
+	return
+}
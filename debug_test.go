@@ -1,8 +1,11 @@
 package skyconf
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"testing"
+	"time"
 )
 
 func TestString(t *testing.T) {
@@ -70,6 +73,60 @@ func TestString(t *testing.T) {
 			wantStr: "regional:/path/region1/level -> {defaultValue: optional:false flatten:false source:regional refresh:0s id:Level} = info",
 			wantErr: assert.NoError,
 		},
+		{
+			name: "secret current value redacted",
+			args: args{
+				cfg: &struct {
+					Password string `sky:",source:regional,secret"`
+				}{
+					Password: "hunter2",
+				},
+				withUntagged:     false,
+				withCurrentValue: true,
+				sources: []Source{
+					SSMSourceWithID(nil, "/path/region1", "regional"),
+				},
+			},
+			wantStr: "regional:/path/region1/password -> {defaultValue: optional:false flatten:false source:regional refresh:0s id:Password} = [REDACTED]",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "description",
+			args: args{
+				cfg: &struct {
+					Level string `sky:",source:regional" skydoc:"Log level; one of debug, info, warn or error."`
+					Port  int    `sky:",source:regional,desc:Port to listen on"`
+				}{},
+				withUntagged:     false,
+				withCurrentValue: false,
+				sources: []Source{
+					SSMSourceWithID(nil, "/path/region1", "regional"),
+				},
+			},
+			wantStr: "regional:/path/region1/level -> {defaultValue: optional:false flatten:false source:regional refresh:0s id:Level} # Log level; one of debug, info, warn or error.\n" +
+				"regional:/path/region1/port -> {defaultValue: optional:false flatten:false source:regional refresh:0s id:Port} # Port to listen on",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "current value using text marshaler",
+			args: args{
+				cfg: &struct {
+					Address net.IP        `sky:",source:regional"`
+					Timeout time.Duration `sky:",source:regional"`
+				}{
+					Address: net.IPv4(10, 0, 0, 1),
+					Timeout: 5 * time.Second,
+				},
+				withUntagged:     false,
+				withCurrentValue: true,
+				sources: []Source{
+					SSMSourceWithID(nil, "/path/region1", "regional"),
+				},
+			},
+			wantStr: "regional:/path/region1/address -> {defaultValue: optional:false flatten:false source:regional refresh:0s id:Address} = 10.0.0.1\n" +
+				"regional:/path/region1/timeout -> {defaultValue: optional:false flatten:false source:regional refresh:0s id:Timeout} = 5s",
+			wantErr: assert.NoError,
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,3 +140,52 @@ func TestString(t *testing.T) {
 		})
 	}
 }
+
+func TestStringWithValues(t *testing.T) {
+	sources := []Source{
+		&mockSource{
+			ps:   mockParameterStore{"/global/level": "debug", "/global/db/password": "secret"},
+			path: "/global/",
+			id:   "global",
+		},
+		&mockSource{
+			ps:   mockParameterStore{"/regional/level": "warn"},
+			path: "/regional/",
+			id:   "regional",
+		},
+	}
+
+	cfg := &struct {
+		Level string `sky:",default:info"`
+		DB    struct {
+			Password string `sky:",secret"`
+			Port     int    `sky:",default:5432,optional"`
+		} `sky:"db"`
+	}{Level: "info"}
+	cfg.DB.Password = "old-secret"
+	cfg.DB.Port = 5432
+
+	str, err := StringWithValues(context.Background(), cfg, false, sources...)
+	assert.NoError(t, err)
+	assert.Equal(t, "Level -> regional:/regional/level = warn -> info\n"+
+		"Password -> global:/global/db/password = [REDACTED] -> [REDACTED]\n"+
+		"Port -> anyOf:[ global:/global/db/port, regional:/regional/db/port ] = (not found) -> 5432", str)
+
+	// The configuration struct is not modified
+	assert.Equal(t, "info", cfg.Level)
+
+	_, err = StringWithValues(context.Background(), cfg, false)
+	assert.ErrorIs(t, err, ErrNoSource)
+
+	// The fields are those parsed with the options, leaving the nil pointers untouched
+	type limits struct {
+		CPU int `sky:"cpu"`
+	}
+	withPointer := &struct {
+		Limits *limits `sky:"limits"`
+	}{}
+	str, err = StringWithValuesWithOptions(context.Background(), withPointer, sources[:1], WithPrefix("db"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu -> anyOf:[ global:/global/db/limits/cpu ] = (not found) -> 0", str)
+	assert.Nil(t, withPointer.Limits)
+}
@@ -0,0 +1,128 @@
+package skyconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecoderFunc decodes a value obtained from a source into a value of the type it is registered for, or a pointer to
+// one; see RegisterDecoder.
+type DecoderFunc func(value string) (interface{}, error)
+
+var (
+	decodersM sync.RWMutex
+	decoders  = make(map[reflect.Type]DecoderFunc)
+)
+
+// RegisterDecoder registers a function decoding the values of the fields of the given type, including pointers to,
+// slices, arrays and maps of such values. It allows types that cannot implement Setter or encoding.TextUnmarshaler,
+// such as types of third-party packages, to be decoded without wrapping them in another type for every field. The
+// decoder takes precedence over the built-in decoding of the type. Registering a nil decoder removes it, restoring the
+// built-in decoding.
+func RegisterDecoder(t reflect.Type, decode DecoderFunc) {
+	decodersM.Lock()
+	defer decodersM.Unlock()
+
+	if decode == nil {
+		delete(decoders, t)
+		return
+	}
+
+	decoders[t] = decode
+}
+
+// RegisterDecoderFunc registers a function decoding the values of the fields of type T; see RegisterDecoder.
+func RegisterDecoderFunc[T any](decode func(value string) (T, error)) {
+	RegisterDecoder(reflect.TypeOf((*T)(nil)).Elem(), func(value string) (interface{}, error) {
+		return decode(value)
+	})
+}
+
+// decoderFor returns the decoder registered for the type, if any, or its built-in decoder; see builtinDecoders.
+func decoderFor(t reflect.Type) DecoderFunc {
+	decodersM.RLock()
+	defer decodersM.RUnlock()
+
+	if decode, ok := decoders[t]; ok {
+		return decode
+	}
+
+	return builtinDecoders[t]
+}
+
+// decodeRegistered decodes the value into the field using the decoder registered for its type, or its built-in decoder,
+// if there is one, in which case ok is true.
+func decodeRegistered(value string, field reflect.Value) (ok bool, err error) {
+	t := field.Type()
+	decode := decoderFor(t)
+	if decode == nil {
+		return
+	}
+
+	ok = true
+
+	var decoded interface{}
+	if decoded, err = decode(value); err != nil {
+		return
+	}
+
+	v := reflect.ValueOf(decoded)
+	switch {
+	case !v.IsValid():
+		field.Set(reflect.Zero(t))
+	case v.Type().AssignableTo(t):
+		field.Set(v)
+	case v.Kind() == reflect.Ptr && v.Type().Elem().AssignableTo(t):
+		if v.IsNil() {
+			field.Set(reflect.Zero(t))
+		} else {
+			field.Set(v.Elem())
+		}
+	default:
+		err = fmt.Errorf("decoder for %s returned a %s", t, v.Type())
+	}
+
+	return
+}
+
+// decodesItself returns true if values can be decoded into the field as a whole, rather than into its fields or
+// items: it implements Setter, encoding.TextUnmarshaler or encoding.BinaryUnmarshaler, or a decoder is registered for
+// its type.
+func decodesItself(field reflect.Value) bool {
+	return setterFrom(field) != nil || textUnmarshaler(field) != nil || binaryUnmarshaler(field) != nil ||
+		decoderFor(field.Type()) != nil
+}
+
+// ErrInvalidTarget is returned by DecodeValue when the target is not a non-nil pointer.
+var ErrInvalidTarget = errors.New("target must be a non-nil pointer")
+
+// DecodeValue decodes the value into the variable the target points to, as Parse decodes the value of a parameter into
+// a field of the same type: using the decoder registered for the type, Setter, encoding.TextUnmarshaler or
+// encoding.BinaryUnmarshaler, or else splitting slices, arrays and maps on ';', and parsing numbers, booleans and
+// durations. It lets the decoding be used outside of Parse, such as for a value received by an admin API, and be
+// fuzzed for custom types.
+//
+// The errors decoding the value wrap ErrBadFieldValue, and the target is left untouched. It fails with
+// ErrInvalidTarget if the target is not a non-nil pointer, and ErrUnsupportedType if values cannot be decoded into its
+// type, such as structs that do not decode themselves.
+func DecodeValue(value string, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("%w: %T", ErrInvalidTarget, target)
+	}
+
+	t := v.Type().Elem()
+	decoded := reflect.New(t).Elem()
+	if !decodable(decoded) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+
+	if err := processFieldValue(false, value, decoded); err != nil {
+		return fmt.Errorf("%w of type %s: %w", ErrBadFieldValue, t, err)
+	}
+
+	v.Elem().Set(decoded)
+	return nil
+}
@@ -0,0 +1,150 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// money is a type standing in for a third-party type, such as a decimal, with no Setter of its own.
+type money struct {
+	Units int64
+	Cents int64
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoderFunc(func(value string) (url.URL, error) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return url.URL{}, err
+		}
+		if u.Scheme == "" {
+			return url.URL{}, errors.New("expected an absolute URL, as in https://example.com")
+		}
+		return *u, nil
+	})
+	RegisterDecoder(reflect.TypeOf(money{}), func(value string) (interface{}, error) {
+		var m money
+		units, cents, _ := strings.Cut(value, ".")
+		if err := processFieldValue(false, units, reflect.ValueOf(&m.Units).Elem()); err != nil {
+			return nil, err
+		}
+		if err := processFieldValue(false, cents, reflect.ValueOf(&m.Cents).Elem()); err != nil {
+			return nil, err
+		}
+		return &m, nil
+	})
+	defer func() {
+		RegisterDecoder(reflect.TypeOf(url.URL{}), nil)
+		RegisterDecoder(reflect.TypeOf(money{}), nil)
+	}()
+
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/endpoint": "https://example.com/api",
+			"/path/mirror":   "https://mirror.example.com",
+			"/path/prices":   "1.50;2.05",
+			"/path/limit":    "100.00",
+		},
+		path: "/path/",
+	}
+
+	cfg := &struct {
+		Endpoint url.URL  `sky:"endpoint"`
+		Mirror   *url.URL `sky:"mirror"`
+		Prices   []money  `sky:"prices"`
+		Limit    money    `sky:"limit"`
+		Fallback url.URL  `sky:"fallback,default:https://fallback.example.com"`
+	}{}
+
+	_, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "https://example.com/api", cfg.Endpoint.String())
+	assert.Equal(t, "mirror.example.com", cfg.Mirror.Host)
+	assert.Equal(t, []money{{1, 50}, {2, 5}}, cfg.Prices)
+	assert.Equal(t, money{100, 0}, cfg.Limit)
+	assert.Equal(t, "fallback.example.com", cfg.Fallback.Host)
+
+	// Errors of the decoder are returned
+	source.ps["/path/endpoint"] = "example.com"
+	_, err = Parse(context.Background(), cfg, false, source)
+	assert.ErrorIs(t, err, ErrBadFieldValue)
+	assert.ErrorContains(t, err, "expected an absolute URL")
+}
+
+func TestDecodeValue(t *testing.T) {
+	var n int8
+	assert.NoError(t, DecodeValue("0x10", &n))
+	assert.Equal(t, int8(16), n)
+
+	// The target is left untouched on error
+	assert.ErrorIs(t, DecodeValue("300", &n), ErrBadFieldValue)
+	assert.ErrorIs(t, DecodeValue("300", &n), ErrValueOutOfRange)
+	assert.Equal(t, int8(16), n)
+
+	var d []time.Duration
+	assert.NoError(t, DecodeValue("1s;1m", &d))
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, d)
+
+	var m map[string]int
+	assert.NoError(t, DecodeValue("a:1;b:2", &m))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, m)
+
+	var u *url.URL
+	assert.NoError(t, DecodeValue("https://example.com/path", &u))
+	assert.Equal(t, "example.com", u.Host)
+
+	var s money
+	assert.ErrorIs(t, DecodeValue("1.50", &s), ErrUnsupportedType)
+	assert.ErrorIs(t, DecodeValue("1", n), ErrInvalidTarget)
+	assert.ErrorIs(t, DecodeValue("1", (*int)(nil)), ErrInvalidTarget)
+	assert.ErrorIs(t, DecodeValue("1", nil), ErrInvalidTarget)
+}
+
+func FuzzDecodeValue(f *testing.F) {
+	for _, seed := range []string{"", "0", "-1", "0x1f", "1.5e3", "true", "1h2m3s", "1;2;3", "a:1;b:2", ";:", "NaN"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		// Values decoded are formatted back to the same value
+		roundTrip := func(target any) {
+			if DecodeValue(value, target) != nil {
+				return
+			}
+
+			formatted, err := formatFieldValue(reflect.ValueOf(target).Elem())
+			if err != nil {
+				t.Fatalf("failed to format %q decoded into %T: %v", value, target, err)
+			}
+
+			again := reflect.New(reflect.TypeOf(target).Elem())
+			if err = DecodeValue(formatted, again.Interface()); err != nil {
+				t.Fatalf("failed to decode %q formatted from %q into %T: %v", formatted, value, target, err)
+			}
+			if !reflect.DeepEqual(reflect.ValueOf(target).Elem().Interface(), again.Elem().Interface()) {
+				t.Fatalf("%q decoded into %T is formatted as %q, decoded differently", value, target, formatted)
+			}
+		}
+
+		roundTrip(new(int64))
+		roundTrip(new(uint16))
+		roundTrip(new(bool))
+		roundTrip(new(time.Duration))
+		roundTrip(new([]int))
+
+		// Other types must not panic
+		_ = DecodeValue(value, new(float32))
+		_ = DecodeValue(value, new(map[string][]int))
+		_ = DecodeValue(value, new([2]string))
+		_ = DecodeValue(value, new(*url.URL))
+	})
+}
@@ -0,0 +1,104 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDefaultFrom is returned when the `defaultfrom` tag of a field refers to no other field, or when fields take their
+// default values from each other in a cycle.
+var ErrDefaultFrom = errors.New("invalid defaultfrom")
+
+// defaultFrom is a field taking its default value from another field; see the `defaultfrom` tag.
+type defaultFrom struct {
+	field int // the index of the field
+	from  int // the index of the field its default value is taken from
+}
+
+// defaultFromOrder returns the fields tagged with `defaultfrom`, ordered so that each comes after the field it takes
+// its default value from, if that field is tagged too. It fails with ErrDefaultFrom if a field refers to an unknown ID,
+// or if the fields refer to each other in a cycle.
+func defaultFromOrder(fields []fieldInfo) (order []defaultFrom, err error) {
+	ids := make(map[string]int, len(fields))
+	for i, field := range fields {
+		if _, ok := ids[field.options.id]; !ok {
+			ids[field.options.id] = i
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(fields))
+
+	var visit func(i int, chain []string) error
+	visit = func(i int, chain []string) error {
+		field := fields[i]
+		switch {
+		case state[i] == visited:
+			return nil
+		case state[i] == visiting:
+			return fmt.Errorf("%w: cycle %s", ErrDefaultFrom, strings.Join(append(chain, field.options.id), " -> "))
+		case field.options.defaultFrom == "":
+			state[i] = visited
+			return nil
+		}
+
+		from, ok := ids[field.options.defaultFrom]
+		if !ok {
+			return fmt.Errorf("%w: field %s defaults to unknown field %q", ErrDefaultFrom, field.path,
+				field.options.defaultFrom)
+		}
+
+		state[i] = visiting
+		if err := visit(from, append(chain, field.options.id)); err != nil {
+			return err
+		}
+		state[i] = visited
+
+		order = append(order, defaultFrom{field: i, from: from})
+		return nil
+	}
+
+	for i := range fields {
+		if err = visit(i, nil); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// applyDefaultsFrom sets the fields tagged with `defaultfrom` that were not set beforehand, nor from a source, to the
+// value of the field they refer to, in order, once all the sources have been applied. Fields referring to a field
+// whose value is zero are left untouched.
+func (u *updater) applyDefaultsFrom(ctx context.Context, order []defaultFrom) (err error) {
+	for _, d := range order {
+		f, from := u.fields[d.field], u.fields[d.from].field
+		if f.origin != ProvenanceZero || from.structField.IsZero() {
+			continue
+		}
+
+		var value string
+		if value, err = formatFieldValue(from.structField); err == nil {
+			err = decodeFieldValue(true, value, f.field.structField, f.field.options)
+		}
+		if err != nil {
+			err = f.field.fieldError(StageDefault, nil, "", fmt.Errorf("%w of type %s from field %s: %w",
+				ErrBadDefaultFieldValue, f.field.structField.Type(), from.path, err))
+			return
+		}
+
+		f.field.allocate()
+		f.origin = ProvenanceDefault
+
+		u.opts.logger.DebugContext(ctx, "applied default value from field",
+			"field", f.field.options.id, "from", from.options.id, "value", f.field.logValue(value))
+	}
+
+	return
+}
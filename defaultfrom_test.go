@@ -0,0 +1,80 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDefaultFrom(t *testing.T) {
+	source := &mockSource{id: "ssm", path: "/", ps: mockParameterStore{
+		"/primary_host": "db1",
+		"/port":         "5432",
+		"/admin_host":   "admin",
+	}}
+
+	type config struct {
+		BackupHost  string `sky:"backup_host,defaultfrom:replica_host"`
+		ReplicaHost string `sky:"replica_host,defaultfrom:primary_host"`
+		PrimaryHost string `sky:"primary_host"`
+		AdminHost   string `sky:"admin_host,defaultfrom:primary_host"`
+		PortLabel   string `sky:"port_label,defaultfrom:port"`
+		Port        int    `sky:"port"`
+		Preset      string `sky:"preset,defaultfrom:primary_host"`
+		Empty       string `sky:"empty,optional"`
+		FromEmpty   string `sky:"from_empty,defaultfrom:empty"`
+	}
+
+	cfg := config{Preset: "kept"}
+	r, err := Parse(context.Background(), &cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The defaults are taken in order, after the sources, which take precedence, as do the values set beforehand
+	assert.Equal(t, config{
+		BackupHost:  "db1",
+		ReplicaHost: "db1",
+		PrimaryHost: "db1",
+		AdminHost:   "admin",
+		PortLabel:   "5432",
+		Port:        5432,
+		Preset:      "kept",
+	}, cfg)
+	assert.Equal(t, ProvenanceDefault, r.Status()[0].Provenance)
+	assert.Equal(t, ProvenanceSource, r.Status()[3].Provenance)
+	assert.Equal(t, ProvenanceZero, r.Status()[8].Provenance)
+
+	_, options, err := ParseTag("replica_host,defaultfrom:primary_host")
+	assert.NoError(t, err)
+	assert.Equal(t, "primary_host", options.DefaultFrom)
+}
+
+func TestDefaultFromErrors(t *testing.T) {
+	source := &mockSource{id: "ssm", path: "/", ps: mockParameterStore{}}
+
+	var cycle struct {
+		A string `sky:"a,defaultfrom:b"`
+		B string `sky:"b,defaultfrom:c"`
+		C string `sky:"c,defaultfrom:a"`
+	}
+	_, err := Parse(context.Background(), &cycle, false, source)
+	assert.ErrorIs(t, err, ErrDefaultFrom)
+	assert.ErrorContains(t, err, "cycle a -> b -> c -> a")
+
+	var unknown struct {
+		A string `sky:"a,defaultfrom:missing"`
+	}
+	_, err = Parse(context.Background(), &unknown, false, source)
+	assert.ErrorIs(t, err, ErrDefaultFrom)
+
+	var mismatched struct {
+		Name string `sky:"name,default:db1"`
+		Port int    `sky:"port,defaultfrom:name"`
+	}
+	_, err = Parse(context.Background(), &mismatched, false, source)
+	assert.ErrorIs(t, err, ErrBadDefaultFieldValue)
+
+	_, _, err = ParseTag("a,default:x,defaultfrom:b")
+	assert.ErrorContains(t, err, `tag "defaultfrom" conflicts with default`)
+}
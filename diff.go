@@ -0,0 +1,119 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Difference describes a field whose value in a source differs from its value in the configuration struct.
+type Difference struct {
+	// ID is the identifier of the field.
+	ID string
+	// Source is the ID of the source the value would be taken from.
+	Source string
+	// Parameter is the name of the parameter in the source.
+	Parameter string
+	// Value is the value of the parameter in the source; it is redacted if the field is tagged with `secret`.
+	Value string
+	// Current is the current value of the field; it is redacted if the field is tagged with `secret`.
+	Current string
+}
+
+// Diff fetches the values of the fields of the configuration struct from the sources, in the same way as Parse does,
+// and returns the fields whose values in the sources differ from the current values in the struct. The configuration
+// struct is not modified, and is locked for reading while its fields are read, if it implements RLocker or sync.Locker.
+// Fields not found in any of the sources are ignored.
+func Diff(ctx context.Context, cfg interface{}, withUntagged bool, sources ...Source) (diffs []Difference, err error) {
+	var opts []Option
+	if withUntagged {
+		opts = append(opts, WithUntagged())
+	}
+
+	return DiffWithOptions(ctx, cfg, sources, opts...)
+}
+
+// DiffWithOptions is like Diff, but its behaviour can be configured using the same options as ParseWithOptions.
+func DiffWithOptions(ctx context.Context, cfg interface{}, sources []Source, opts ...Option) (diffs []Difference, err error) {
+	o := makeOptions(opts)
+
+	if len(sources) == 0 {
+		err = ErrNoSource
+		return
+	}
+
+	// Extract the fields to read them, leaving the nil pointers of the configuration struct untouched
+	l := readLock(cfg)
+	l.Lock()
+	var fields []fieldInfo
+	fields, err = o.readFields(cfg)
+	l.Unlock()
+	if err != nil {
+		err = fmt.Errorf("failed to extract fields: %w", err)
+		return
+	}
+
+	if err = o.checkTransformers(fields); err != nil {
+		return
+	}
+
+	var resolved map[int]resolvedValue
+	resolved, err = resolveValues(ctx, o, sources, fields, func(idx int, _ Source) bool {
+		// Fields populated from subtrees of parameters are not compared
+		return !fields[idx].subtree
+	})
+	if err != nil {
+		return
+	}
+
+	// Decode the values into new values of the same types to compare them with the current values
+	values := make(map[int]reflect.Value, len(resolved))
+	for idx, field := range fields {
+		rv, ok := resolved[idx]
+		if !ok {
+			continue
+		}
+
+		v := reflect.New(field.structField.Type()).Elem()
+		var decoded string
+		if decoded, err = o.transform(ctx, field, rv.value); err == nil {
+			err = decodeFieldValue(false, decoded, v, field.options)
+		}
+		if err != nil {
+			err = field.valueError(StageParse, rv.source, rv.key, err)
+			return
+		}
+		values[idx] = v
+	}
+
+	// Compare them while holding the read lock of the configuration struct, if it is lockable
+	l.Lock()
+	defer l.Unlock()
+
+	for idx, field := range fields {
+		v, ok := values[idx]
+		if !ok {
+			continue
+		}
+
+		if reflect.DeepEqual(v.Interface(), field.structField.Interface()) {
+			continue
+		}
+
+		var current string
+		if current, err = formatFieldValue(field.structField); err != nil {
+			return
+		}
+
+		rv := resolved[idx]
+		diffs = append(diffs, Difference{
+			ID:        field.options.id,
+			Source:    rv.source.ID(),
+			Parameter: rv.key,
+			Value:     field.logValue(rv.value),
+			Current:   field.logValue(current),
+		})
+	}
+
+	return
+}
@@ -0,0 +1,100 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	global := &mockSource{
+		ps: mockParameterStore{
+			"/path/global/host":     "db.example.com",
+			"/path/global/port":     "5432",
+			"/path/global/password": "hunter2",
+			"/path/global/tags":     "a;b",
+		},
+		path: "/path/global/",
+		id:   "global",
+	}
+	regional := &mockSource{
+		ps: mockParameterStore{
+			"/path/regional/port": "6432",
+		},
+		path: "/path/regional/",
+		id:   "regional",
+	}
+
+	type config struct {
+		Host     string
+		Port     int
+		Password string `sky:",secret"`
+		Tags     []string
+		Timeout  int `sky:",optional"`
+	}
+
+	cfg := &config{}
+	_, err := Parse(context.Background(), cfg, true, global, regional)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	diffs, err := Diff(context.Background(), cfg, true, global, regional)
+	if assert.NoError(t, err) {
+		assert.Empty(t, diffs)
+	}
+
+	global.set("/path/global/host", "db2.example.com")
+	global.set("/path/global/port", "1234") // overridden by regional
+	global.set("/path/global/password", "correct-horse")
+	regional.set("/path/regional/tags", "a;b") // same value from another source
+
+	diffs, err = Diff(context.Background(), cfg, true, global, regional)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Difference{
+			{
+				ID:        "Host",
+				Source:    "global",
+				Parameter: "/path/global/host",
+				Value:     "db2.example.com",
+				Current:   "db.example.com",
+			},
+			{
+				ID:        "Password",
+				Source:    "global",
+				Parameter: "/path/global/password",
+				Value:     redacted,
+				Current:   redacted,
+			},
+		}, diffs)
+	}
+
+	// The struct is not modified
+	assert.Equal(t, "db.example.com", cfg.Host)
+
+	// Values that cannot be decoded are reported
+	regional.set("/path/regional/port", "not-a-number")
+	_, err = Diff(context.Background(), cfg, true, global, regional)
+	assert.ErrorIs(t, err, ErrBadFieldValue)
+
+	_, err = Diff(context.Background(), cfg, true)
+	assert.ErrorIs(t, err, ErrNoSource)
+}
+
+func TestDiffNilPointers(t *testing.T) {
+	source := &mockSource{ps: mockParameterStore{"/path/db/host": "db1"}, path: "/path/"}
+
+	type db struct {
+		Host string `sky:"host"`
+	}
+	cfg := &struct {
+		DB *db `sky:"db"`
+	}{}
+
+	// The nil pointers of the struct are left untouched, their fields compared as zero
+	diffs, err := Diff(context.Background(), cfg, false, source)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Difference{{ID: "host", Source: "mock", Parameter: "/path/db/host", Value: "db1"}}, diffs)
+	}
+	assert.Nil(t, cfg.DB)
+}
@@ -0,0 +1,112 @@
+package skyconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DocumentFormat is the format of a configuration document.
+type DocumentFormat int
+
+const (
+	// FormatJSON is the format of JSON documents.
+	FormatJSON DocumentFormat = iota
+	// FormatYAML is the format of YAML documents.
+	FormatYAML
+)
+
+// ErrUnknownDocumentFormat is returned when a document format is not known.
+var ErrUnknownDocumentFormat = errors.New("unknown document format")
+
+// documentValues returns the values of the keys found in the flattened document.
+func documentValues(document map[string]string, keys []string) map[string]string {
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := document[key]; ok {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+// documentKeys returns the names of the parameters of the flattened document under the path, relative to it.
+func documentKeys(document map[string]string, path string) (keys []string) {
+	prefix := path
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	for name := range document {
+		if key, ok := strings.CutPrefix(name, prefix); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+// flattenDocument parses the document and flattens it into parameters named after the path of each value.
+func flattenDocument(data []byte, format DocumentFormat) (values map[string]string, err error) {
+	var document any
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&document)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &document)
+	default:
+		err = fmt.Errorf("%w: %d", ErrUnknownDocumentFormat, format)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to parse document: %w", err)
+		return
+	}
+
+	values = make(map[string]string)
+	flattenValue("", document, values)
+
+	return
+}
+
+// flattenValue adds the value, named after its path, to the values; objects and lists of objects are flattened.
+func flattenValue(path string, value any, values map[string]string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "/" + key
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case map[string]any:
+		for key, item := range v {
+			flattenValue(join(key), item, values)
+		}
+	case []any:
+		// Lists of scalars are joined; the items of other lists are named after their index
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				for i, item := range v {
+					flattenValue(join(strconv.Itoa(i)), item, values)
+				}
+				return
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		values[path] = strings.Join(items, ";")
+	default:
+		values[path] = fmt.Sprint(v)
+	}
+}
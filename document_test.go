@@ -0,0 +1,32 @@
+package skyconf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_flattenDocument(t *testing.T) {
+	yamlDoc := `
+level: info
+db:
+  host: localhost
+  port: 5432
+  ratio: 0.5
+hosts: [a, b]
+`
+	values, err := flattenDocument([]byte(yamlDoc), FormatYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"level":    "info",
+		"db/host":  "localhost",
+		"db/port":  "5432",
+		"db/ratio": "0.5",
+		"hosts":    "a;b",
+	}, values)
+
+	_, err = flattenDocument([]byte(`{`), FormatJSON)
+	assert.Error(t, err)
+
+	_, err = flattenDocument([]byte(`{}`), DocumentFormat(42))
+	assert.ErrorIs(t, err, ErrUnknownDocumentFormat)
+}
@@ -0,0 +1,16 @@
+package skyconf
+
+// WithEmptyAsMissing makes the parameters whose value is an empty string count as missing from the sources, such as
+// placeholders created empty by infrastructure code, so that they do not override the default values of the fields,
+// nor the values of earlier sources or aliases. On refresh, an empty value is handled as the parameter being deleted;
+// see the `ondelete` tag. Fields tagged with `allowempty` still take empty values.
+func WithEmptyAsMissing() Option {
+	return func(o *options) {
+		o.emptyAsMissing = true
+	}
+}
+
+// isMissing returns true if the value of the parameter of the field counts as missing; see WithEmptyAsMissing.
+func (o *options) isMissing(field fieldInfo, value string) bool {
+	return value == "" && o.emptyAsMissing && !field.options.allowEmpty
+}
@@ -0,0 +1,58 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithEmptyAsMissing(t *testing.T) {
+	base := &mockSource{id: "base", path: "/", refreshable: true, ps: mockParameterStore{
+		"/region": "eu-west-1",
+	}}
+	placeholders := &mockSource{id: "placeholders", path: "/", refreshable: true, ps: mockParameterStore{
+		"/region":     "",
+		"/log_level":  "",
+		"/old_name":   "service",
+		"/name":       "",
+		"/suffix":     "",
+		"/timeout_ms": "250",
+	}}
+
+	type config struct {
+		Region    string `sky:"region"`
+		LogLevel  string `sky:"log_level,default:info"`
+		Name      string `sky:"name,alias:old_name"`
+		Suffix    string `sky:"suffix,allowempty,default:-dev"`
+		TimeoutMS int    `sky:"timeout_ms,refresh:1m,ondelete:default,default:100"`
+	}
+
+	// Without the option, the empty values override the defaults and the values of earlier sources
+	var cfg config
+	_, err := Parse(context.Background(), &cfg, false, base, placeholders)
+	assert.NoError(t, err)
+	assert.Equal(t, config{Name: "", TimeoutMS: 250}, cfg)
+
+	// With the option, they are missing, unless tagged with allowempty, as Suffix is
+	cfg = config{}
+	r, err := ParseWithOptions(context.Background(), &cfg, []Source{base, placeholders}, WithEmptyAsMissing())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, config{Region: "eu-west-1", LogLevel: "info", Name: "service", TimeoutMS: 250}, cfg)
+	_, options, err := ParseTag("suffix,allowempty")
+	assert.NoError(t, err)
+	assert.True(t, options.AllowEmpty)
+
+	// On refresh, an empty value is handled as the parameter being deleted
+	placeholders.ps["/timeout_ms"] = ""
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 100, cfg.TimeoutMS)
+
+	// Required fields whose value is empty are not found
+	var required struct {
+		LogLevel string `sky:"log_level"`
+	}
+	_, err = ParseWithOptions(context.Background(), &required, []Source{placeholders}, WithEmptyAsMissing())
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+}
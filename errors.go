@@ -0,0 +1,131 @@
+package skyconf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stage is the stage at which the value of a field failed to be set.
+type Stage string
+
+const (
+	// StageDefault is the stage at which the default values of the fields are set, before fetching any parameter.
+	StageDefault Stage = "default"
+	// StageParse is the stage at which the fields are set from the values of the parameters, by Parse.
+	StageParse Stage = "parse"
+	// StageRefresh is the stage at which the fields are updated by the Refresher, or by Receive.
+	StageRefresh Stage = "refresh"
+)
+
+// FieldError is the error setting the value of a field of the configuration struct, returned by Parse and passed to
+// the error callback of Refresh. It names the field, the parameter and the source the value was taken from, so that
+// the error can be handled programmatically and logged in a structured way; the cause is one of ErrBadFieldValue,
+// ErrBadDefaultFieldValue, ErrParameterNotFound or ErrMissingKeyOnRefresh, matched with errors.Is.
+type FieldError struct {
+	// Field is the name of the field, qualified with those of the enclosing structs, as in "DB.Port".
+	Field string
+	// ID is the id of the field; see the `id` tag.
+	ID string
+	// Parameter is the name of the parameter in the source; empty for default values.
+	Parameter string
+	// Source is the ID of the source; "(any)" if the parameter was not found in any of the sources, empty for
+	// default values.
+	Source string
+	// Stage is the stage at which the error occurred.
+	Stage Stage
+	// Secret is true if the field is tagged with `secret`.
+	Secret bool
+	// Candidates are the parameters looked up in each of the sources, in order, as "<source>:<name>", including the
+	// aliases of the parameter, when the parameter was not found in any of the sources; Parameter is then its name in
+	// the last source.
+	Candidates []string
+	// Err is the cause of the error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(string(e.Stage))
+	sb.WriteString(": field ")
+	sb.WriteString(e.Field)
+	if len(e.Candidates) > 0 {
+		sb.WriteString(", parameters anyOf:[ ")
+		sb.WriteString(strings.Join(e.Candidates, ", "))
+		sb.WriteString(" ]")
+	} else if e.Parameter != "" {
+		sb.WriteString(", parameter ")
+		sb.WriteString(e.Source + ":" + e.Parameter)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+
+	return sb.String()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// SourceError is the error fetching the parameters of the fields from a source, returned by Parse and passed to the
+// error callback of Refresh. It matches ErrGetParameters and the error returned by the source with errors.Is.
+type SourceError struct {
+	// Source is the ID of the source.
+	Source string
+	// Parameters are the names of the parameters requested from the source.
+	Parameters []string
+	// Fields are the names of the fields whose parameters were requested, qualified with those of the enclosing
+	// structs.
+	Fields []string
+	// Elapsed is the time taken by the failed fetch on refresh; see WithRefreshTimeout. It is zero for Parse.
+	Elapsed time.Duration
+	// Err is the error returned by the source.
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s from source '%s' : %s", ErrGetParameters, e.Source, e.Err)
+}
+
+func (e *SourceError) Unwrap() []error {
+	return []error{ErrGetParameters, e.Err}
+}
+
+// fieldError returns the error of the field at the stage, naming the parameter of the source it is taken from; source
+// is nil for default values.
+func (f fieldInfo) fieldError(stage Stage, source Source, key string, err error) *FieldError {
+	fe := &FieldError{
+		Field:     f.path,
+		ID:        f.options.id,
+		Parameter: key,
+		Stage:     stage,
+		Secret:    f.options.secret,
+		Err:       err,
+	}
+	if source != nil {
+		fe.Source = source.ID()
+	}
+
+	return fe
+}
+
+// candidates returns the parameters of the field looked up in each of the sources it can be taken from, in order,
+// including its aliases.
+func (f fieldInfo) candidates(sources []Source) (names []string) {
+	for _, source := range sources {
+		if !f.options.fromSource(source.ID()) {
+			continue
+		}
+
+		for _, name := range append([]string{f.parameterName(source)}, f.aliasNames(source)...) {
+			names = append(names, source.ID()+":"+name)
+		}
+	}
+
+	return
+}
+
+// valueError wraps the error setting the value of the parameter obtained from the source into the field.
+func (f fieldInfo) valueError(stage Stage, source Source, key string, err error) error {
+	return f.fieldError(stage, source, key, fmt.Errorf("%w of type %s: %w", ErrBadFieldValue, f.structField.Type(), err))
+}
@@ -0,0 +1,132 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFieldError(t *testing.T) {
+	type config struct {
+		DB struct {
+			Port     int    `sky:"port,refresh:1m"`
+			Password string `sky:"password,secret,refresh:1m"`
+		} `sky:"db"`
+	}
+
+	tests := []struct {
+		name string
+		cfg  any
+		ps   mockParameterStore
+		want FieldError
+		is   error
+	}{
+		{
+			name: "bad value",
+			cfg:  &config{},
+			ps:   mockParameterStore{"/db/port": "port", "/db/password": "pass"},
+			want: FieldError{Field: "DB.Port", ID: "port", Parameter: "/db/port", Source: "mock", Stage: StageParse},
+			is:   ErrBadFieldValue,
+		},
+		{
+			name: "missing secret",
+			cfg:  &config{},
+			ps:   mockParameterStore{"/db/port": "5432"},
+			want: FieldError{Field: "DB.Password", ID: "password", Parameter: "/db/password", Source: "mock",
+				Stage: StageParse, Secret: true},
+			is: ErrParameterNotFound,
+		},
+		{
+			name: "bad default value",
+			cfg: &struct {
+				Timeout int `sky:"timeout,default:soon"`
+			}{},
+			ps:   mockParameterStore{},
+			want: FieldError{Field: "Timeout", ID: "timeout", Stage: StageDefault},
+			is:   ErrBadDefaultFieldValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(context.Background(), tt.cfg, false, &mockSource{ps: tt.ps, path: "/", refreshable: true})
+			assert.ErrorIs(t, err, tt.is)
+
+			var fe *FieldError
+			if !assert.ErrorAs(t, err, &fe) {
+				return
+			}
+			fe.Err = nil
+			assert.Equal(t, tt.want, *fe)
+		})
+	}
+
+	t.Run("refresh", func(t *testing.T) {
+		source := &mockSource{
+			ps:          mockParameterStore{"/db/port": "5432", "/db/password": "pass"},
+			path:        "/",
+			refreshable: true,
+		}
+		r, err := Parse(context.Background(), &config{}, false, source)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		source.set("/db/port", "port")
+		err = r.RefreshOnce(context.Background())
+		assert.ErrorIs(t, err, ErrBadFieldValue)
+
+		var fe *FieldError
+		if assert.True(t, errors.As(err, &fe)) {
+			assert.Equal(t, StageRefresh, fe.Stage)
+			assert.Equal(t, "DB.Port", fe.Field)
+			assert.Equal(t, "refresh: field DB.Port, parameter mock:/db/port: "+
+				"failed to set value for field of type int: "+
+				"strconv.ParseInt: parsing \"port\": invalid syntax", fe.Error())
+		}
+	})
+	t.Run("refresh fetch", func(t *testing.T) {
+		source := &mockSource{
+			ps:          mockParameterStore{"/db/port": "5432", "/db/password": "pass"},
+			path:        "/",
+			refreshable: true,
+		}
+		r, err := Parse(context.Background(), &config{}, false, source)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		source.ps = nil
+		err = r.RefreshOnce(context.Background())
+		assert.ErrorIs(t, err, ErrGetParameters)
+		assert.ErrorIs(t, err, errInvalidSource)
+
+		var se *SourceError
+		if assert.ErrorAs(t, err, &se) {
+			assert.Equal(t, "mock", se.Source)
+			assert.ElementsMatch(t, []string{"/db/port", "/db/password"}, se.Parameters)
+			assert.ElementsMatch(t, []string{"DB.Port", "DB.Password"}, se.Fields)
+		}
+	})
+	t.Run("missing from every source", func(t *testing.T) {
+		var cfg struct {
+			Port int `sky:"port,alias:old_port"`
+		}
+		sources := []Source{
+			&mockSource{ps: mockParameterStore{}, path: "/global/", id: "global"},
+			&mockSource{ps: mockParameterStore{}, path: "/local/", id: "local"},
+		}
+		_, err := Parse(context.Background(), &cfg, false, sources...)
+		assert.ErrorIs(t, err, ErrParameterNotFound)
+
+		var fe *FieldError
+		if assert.ErrorAs(t, err, &fe) {
+			assert.Equal(t, "(any)", fe.Source)
+			assert.Equal(t, "/local/port", fe.Parameter)
+			assert.Equal(t, []string{"global:/global/port", "global:/global/old_port", "local:/local/port",
+				"local:/local/old_port"}, fe.Candidates)
+			assert.Equal(t, "parse: field Port, parameters anyOf:[ global:/global/port, global:/global/old_port, "+
+				"local:/local/port, local:/local/old_port ]: parameter not found in source", fe.Error())
+		}
+	})
+}
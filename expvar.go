@@ -0,0 +1,104 @@
+package skyconf
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// WithExpvar makes Parse publish the statistics of the Refresher it returns, and a snapshot of the configuration
+// struct, as returned by Snapshot with the same options, as the variable with the given name of the expvar package, so
+// that they are served at /debug/vars along with the other variables. They are read each time the variable is.
+// Parsing again with the same name publishes those of the new Refresher in its place. The variable is not published if
+// the name is already taken by another variable.
+func WithExpvar(name string) Option {
+	return func(o *options) {
+		o.expvarName = name
+	}
+}
+
+// expvarStats is the value of the variable published by WithExpvar.
+type expvarStats struct {
+	Fields     int               `json:"fields"`
+	Refreshed  int               `json:"refreshed"` // the fields refreshed periodically
+	Paused     int               `json:"paused"`
+	Stale      int               `json:"stale"`
+	Suppressed int               `json:"suppressed"`
+	Pending    int               `json:"pending"`
+	Provenance map[string]int    `json:"provenance"` // the number of fields by provenance
+	Sources    map[string]int    `json:"sources"`    // the number of fields set from each source, by ID
+	Config     map[string]string `json:"config,omitempty"`
+	Error      string            `json:"error,omitempty"` // the error taking the snapshot of the configuration, if any
+}
+
+var (
+	expvarsM sync.Mutex
+	expvars  = make(map[string]*atomic.Pointer[func() any]) // the functions of the variables published, by name
+)
+
+// publishExpvar publishes the statistics of the Refresher and the snapshot of the configuration struct as the
+// variable named by WithExpvar, if set.
+func (o *options) publishExpvar(ctx context.Context, cfg interface{}, r Refresher) {
+	if o.expvarName == "" {
+		return
+	}
+
+	fn := func() any {
+		return o.expvarStats(cfg, r)
+	}
+
+	expvarsM.Lock()
+	defer expvarsM.Unlock()
+
+	published, ok := expvars[o.expvarName]
+	if !ok {
+		if expvar.Get(o.expvarName) != nil {
+			o.logger.WarnContext(ctx, "not publishing expvar variable already published", "name", o.expvarName)
+			return
+		}
+
+		published = &atomic.Pointer[func() any]{}
+		expvar.Publish(o.expvarName, expvar.Func(func() any {
+			return (*published.Load())()
+		}))
+		expvars[o.expvarName] = published
+	}
+	published.Store(&fn)
+}
+
+// expvarStats returns the statistics of the Refresher and the snapshot of the configuration struct.
+func (o *options) expvarStats(cfg interface{}, r Refresher) expvarStats {
+	stats := expvarStats{
+		Pending:    len(r.Pending()),
+		Provenance: make(map[string]int),
+		Sources:    make(map[string]int),
+	}
+
+	for _, s := range r.Status() {
+		stats.Fields++
+		stats.Provenance[string(s.Provenance)]++
+		if s.Source != "" {
+			stats.Sources[s.Source]++
+		}
+		if s.Refresh != 0 {
+			stats.Refreshed++
+		}
+		if s.Paused {
+			stats.Paused++
+		}
+		if s.Stale {
+			stats.Stale++
+		}
+		if !s.Suppressed.IsZero() {
+			stats.Suppressed++
+		}
+	}
+
+	var err error
+	if stats.Config, err = o.snapshot(cfg); err != nil {
+		stats.Error = err.Error()
+	}
+
+	return stats
+}
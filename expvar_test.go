@@ -0,0 +1,57 @@
+package skyconf
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithExpvar(t *testing.T) {
+	type config struct {
+		Host     string `sky:"host,refresh:1m"`
+		Password string `sky:"password,secret"`
+		Port     int    `sky:"port,default:5432"`
+	}
+
+	source := &mockSource{path: "/app/", id: "mock", refreshable: true, ps: mockParameterStore{
+		"/app/host":     "db1",
+		"/app/password": "hunter2",
+	}}
+
+	var cfg config
+	_, err := ParseWithOptions(context.Background(), &cfg, []Source{source}, WithExpvar("skyconf_test"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var stats expvarStats
+	if !assert.NoError(t, json.Unmarshal([]byte(expvar.Get("skyconf_test").String()), &stats)) {
+		return
+	}
+	assert.Equal(t, expvarStats{
+		Fields:     3,
+		Refreshed:  1,
+		Provenance: map[string]int{"source": 2, "default": 1},
+		Sources:    map[string]int{"mock": 2},
+		Config:     map[string]string{"host": "db1", "password": redacted, "port": "5432"},
+	}, stats)
+
+	// Parsing again publishes the new configuration in place of the previous one
+	var other config
+	source.ps["/app/host"] = "db2"
+	_, err = ParseWithOptions(context.Background(), &other, []Source{source}, WithExpvar("skyconf_test"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	stats = expvarStats{}
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("skyconf_test").String()), &stats))
+	assert.Equal(t, "db2", stats.Config["host"])
+
+	// Variables published by others are left alone
+	expvar.NewString("skyconf_taken").Set("taken")
+	_, err = ParseWithOptions(context.Background(), &other, []Source{source}, WithExpvar("skyconf_taken"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"taken"`, expvar.Get("skyconf_taken").String())
+}
@@ -2,9 +2,13 @@ package skyconf
 
 import (
 	"encoding"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,9 +20,76 @@ type Setter interface {
 }
 
 type fieldInfo struct {
-	nameParts   []string
+	nameParts   []string // the key parts of the parameter, shared by copies of the field; never modified
 	structField reflect.Value
 	options     fieldOptions
+	subtree     bool                     // populated from a subtree of parameters; see expandSubtrees
+	pointers    []*lazyPointer           // the pointers to the structs enclosing the field, set once it is set
+	set         func(value string) error // the setter bound to the field, if any; see Binder
+	path        string                   // the name of the field, qualified with those of the enclosing structs
+}
+
+// unset returns true if any of the pointers to the structs enclosing the field is still nil.
+func (f fieldInfo) unset() bool {
+	for _, p := range f.pointers {
+		if p.field.IsNil() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lazyPointer is a nil pointer to a struct, which is only set once one of the fields of the struct is set from a
+// source; see WithLazyPointers.
+type lazyPointer struct {
+	field reflect.Value // the nil pointer
+	value reflect.Value // the value to set it to
+}
+
+// allocate sets the pointers to the structs enclosing the field, if any are still nil.
+func (f fieldInfo) allocate() {
+	for _, p := range f.pointers {
+		if p.field.IsNil() {
+			p.field.Set(p.value)
+		}
+	}
+}
+
+// name returns the name of the parameter of the field in the source; its absolute key, if it has one.
+func (f fieldInfo) name(source Source) string {
+	if f.options.absolute != "" {
+		return f.options.absolute
+	}
+
+	return source.ParameterName(f.nameParts)
+}
+
+// aliasNames returns the names of the parameter of the field in the source under its alias keys, in order, including
+// the version or label selector of the parameter, if any.
+func (f fieldInfo) aliasNames(source Source) (names []string) {
+	for _, alias := range f.options.aliases {
+		a := fieldInfo{nameParts: append(slices.Clone(f.nameParts[:len(f.nameParts)-1]), alias), options: f.options}
+		a.options.absolute = ""
+		if strings.HasPrefix(alias, "/") {
+			a.options.absolute = alias
+		}
+
+		names = append(names, a.parameterName(source))
+	}
+
+	return
+}
+
+// parameterName returns the name of the parameter of the field in the source, including the version or label selector
+// of the parameter, if any, in the name:selector form.
+func (f fieldInfo) parameterName(source Source) string {
+	name := f.name(source)
+	if f.options.selector != "" {
+		name += ":" + f.options.selector
+	}
+
+	return name
 }
 
 type fieldOptions struct {
@@ -28,6 +99,20 @@ type fieldOptions struct {
 	source       string
 	refresh      time.Duration
 	id           string
+	secret       bool
+	transform    []string
+	encoding     string
+	selector     string
+	doc          string   // description of the field
+	absolute     string   // name of the parameter, if the key is absolute
+	aliases      []string // alternate keys of the parameter, tried in order if it is not found
+	deprecated   string   // deprecation message, if the parameter is deprecated
+	onDelete     string   // what to do when the parameter is deleted from the source; see updater.remove
+	manual       bool     // changes found on refresh are staged until approved; see updater.stage
+	allowEmpty   bool     // empty values are taken even if they count as missing; see WithEmptyAsMissing
+	defaultFrom  string   // id of the field the default value is taken from; see updater.applyDefaultsFrom
+	when         string   // condition on another field for the field to be fetched; see fieldConditions
+	profiles     []string // profiles the field is part of, or excluded from if negated; see inProfiles
 }
 
 func (o *fieldOptions) String() string {
@@ -35,16 +120,177 @@ func (o *fieldOptions) String() string {
 		o.defaultValue, o.optional, o.flatten, o.source, o.refresh, o.id)
 }
 
+// description returns the description of the field on a single line, suitable for messages.
+func (o *fieldOptions) description() string {
+	return strings.Join(strings.Fields(o.doc), " ")
+}
+
 // inherit copies the options from the parent.
 func (o *fieldOptions) inherit(parent fieldOptions) {
 	o.source = parent.source
 }
 
+// sourceIDs returns the IDs of the sources the field is taken from, in order of precedence, as given by the `source`
+// tag; nil if the field is taken from any source.
+func (o *fieldOptions) sourceIDs() []string {
+	if o.source == "" {
+		return nil
+	}
+
+	return strings.Split(o.source, "|")
+}
+
+// fromSource returns true if the field can be taken from the source with the ID.
+func (o *fieldOptions) fromSource(id string) bool {
+	return o.source == "" || slices.Contains(o.sourceIDs(), id)
+}
+
+// prefers returns true if the field is taken from the source with the ID a rather than b, as a is listed before b by
+// the `source` tag. Otherwise, the precedence of the sources passed to Parse applies, the last one taking precedence.
+func (o *fieldOptions) prefers(a, b string) bool {
+	ids := o.sourceIDs()
+	i, j := slices.Index(ids, a), slices.Index(ids, b)
+	return i >= 0 && j >= 0 && i < j
+}
+
 var ErrInvalidStruct = errors.New("config must be a pointer to a struct")
 var ErrBadTags = errors.New("error parsing tags for field")
 
-// extractFields uses reflection to examine the struct and extract the fields.
+// ErrArrayLength is returned when the number of items in a value does not match the length of an array field.
+var ErrArrayLength = errors.New("value does not match the length of the array")
+
+// ErrRecursiveStruct is returned when a struct contains, directly or indirectly, a field of its own type.
+var ErrRecursiveStruct = errors.New("recursive struct type")
+
+// ErrMaxDepth is returned when structs are nested deeper than the maximum depth; see WithMaxDepth.
+var ErrMaxDepth = errors.New("maximum nesting depth exceeded")
+
+// ErrValueOutOfRange is returned when a number does not fit in the type of the field, such as 300 for an int8.
+var ErrValueOutOfRange = errors.New("value out of range")
+
+// ErrUnsupportedType is returned when the type of a field is not one values can be decoded into, such as interfaces,
+// channels and functions.
+var ErrUnsupportedType = errors.New("unsupported field type")
+
+// WithSkipUnsupported ignores the fields of types that values cannot be decoded into, such as interfaces, channels
+// and functions, rather than failing with ErrUnsupportedType.
+func WithSkipUnsupported() Option {
+	return func(o *options) {
+		o.skipUnsupported = true
+	}
+}
+
+// SkippedField describes a field of the configuration struct that was skipped when extracting the fields.
+type SkippedField struct {
+	// Name is the name of the field, qualified with the names of the structs enclosing it, as in DB.Options.
+	Name string
+	// Type is the type of the field.
+	Type string
+	// Reason is the reason the field was skipped.
+	Reason string
+}
+
+// SkippedFieldsFunc is called with the fields skipped when extracting the fields of a configuration struct.
+type SkippedFieldsFunc func(skipped []SkippedField)
+
+// WithSkippedFields reports the fields skipped when extracting the fields of the configuration struct, other than
+// unexported fields that are neither tagged with `sky` nor embedded: unexported embedded structs, such as those of
+// third-party structs, whose fields cannot be set, unexported fields tagged with `sky`, and fields of unsupported types
+// skipped using WithSkipUnsupported.
+func WithSkippedFields(report SkippedFieldsFunc) Option {
+	return func(o *options) {
+		o.reportSkipped = report
+	}
+}
+
+// WithLazyPointers leaves nil pointers to structs in the configuration struct nil unless at least one of the fields of
+// the struct is set from a source, rather than always initialising them; this allows testing a pointer for nil to find
+// out if a feature is configured. Default values of the fields of such a struct only apply once the pointer is set, and
+// its fields are only required if the pointer is set.
+func WithLazyPointers() Option {
+	return func(o *options) {
+		o.lazyPointers = true
+	}
+}
+
+// defaultMaxDepth is the maximum depth structs can be nested, unless set using WithMaxDepth.
+const defaultMaxDepth = 32
+
+// WithMaxDepth sets the maximum depth that structs can be nested in the configuration struct. The default is 32.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// extraction holds the state of the extraction of the fields of a configuration struct.
+type extraction struct {
+	withUntagged    bool
+	maxDepth        int
+	skipUnsupported bool
+	lazyPointers    bool
+	types           []reflect.Type  // the types of the structs being extracted, outermost first
+	pointers        []*lazyPointer  // the lazy pointers to the structs being extracted, outermost first
+	names           []string        // the names of the fields of the structs being extracted, outermost first
+	profiles        map[string]bool // the active profiles; nil if the fields are not filtered by profile
+	skipped         []SkippedField
+}
+
+// path returns the name of the field, qualified with the names of the fields of the structs being extracted.
+func (e *extraction) path(name string) string {
+	return strings.Join(append(slices.Clip(e.names), name), ".")
+}
+
+// skip records that the field was skipped for the reason.
+func (e *extraction) skip(field reflect.StructField, reason string) {
+	e.skipped = append(e.skipped, SkippedField{
+		Name:   e.path(field.Name),
+		Type:   field.Type.String(),
+		Reason: reason,
+	})
+}
+
+// extractFields extracts the fields of the configuration struct, as configured by the options.
+func (o *options) extractFields(cfg interface{}) (fields []fieldInfo, err error) {
+	e := o.extraction()
+	if fields, err = e.extract(slices.Clip(o.prefix), cfg, fieldOptions{}); err != nil {
+		return
+	}
+
+	if o.reportSkipped != nil && len(e.skipped) > 0 {
+		o.reportSkipped(e.skipped)
+	}
+
+	o.bind(cfg, fields)
+	return
+}
+
+// extraction returns the state of an extraction of the fields of a configuration struct, as configured by the options.
+func (o *options) extraction() *extraction {
+	return &extraction{
+		withUntagged:    o.withUntagged,
+		maxDepth:        o.maxDepth,
+		skipUnsupported: o.skipUnsupported,
+		lazyPointers:    o.lazyPointers,
+		profiles:        o.activeProfiles(),
+	}
+}
+
+// extractFields uses reflection to examine the struct and extract the fields, as configured by the default options,
+// without filtering them by profile.
 func extractFields(withUntagged bool, prefix []string, target interface{}, parentOptions fieldOptions) (fields []fieldInfo, err error) {
+	o := makeOptions(nil)
+	o.withUntagged = withUntagged
+
+	e := o.extraction()
+	e.profiles = nil
+	return e.extract(prefix, target, parentOptions)
+}
+
+// extract extracts the fields of the target struct, recursing into nested structs.
+func (e *extraction) extract(prefix []string, target interface{}, parentOptions fieldOptions) (fields []fieldInfo, err error) {
+	withUntagged := e.withUntagged
+
 	if prefix == nil {
 		prefix = []string{}
 	}
@@ -61,20 +307,32 @@ func extractFields(withUntagged bool, prefix []string, target interface{}, paren
 
 	targetType := s.Type()
 
+	// Keep track of the structs being extracted, to detect recursive types
+	e.types = append(e.types, targetType)
+	defer func() {
+		e.types = e.types[:len(e.types)-1]
+	}()
+
 	// Iterate over the fields of the struct.
 	for i := 0; i < s.NumField(); i++ {
 		f := s.Field(i)
-
-		// Skip unexported fields.
-		if !f.CanSet() {
-			continue
-		}
-
 		structField := targetType.Field(i)
 
 		// Get the 'sky' tag.
 		tags, tagged := structField.Tag.Lookup("sky")
 
+		// Skip unexported fields, including embedded structs of unexported types, which cannot be set nor interfaced.
+		// Those embedded or tagged are reported, as they may be expected to be set.
+		if !f.CanSet() || !f.CanInterface() {
+			switch {
+			case structField.Anonymous:
+				e.skip(structField, "unexported embedded struct")
+			case tagged && tags != "-":
+				e.skip(structField, "unexported field")
+			}
+			continue
+		}
+
 		// If there is no tag (not even an empty tag), ignore the field if withUntagged == false
 		if !tagged && !withUntagged {
 			continue
@@ -92,35 +350,65 @@ func extractFields(withUntagged bool, prefix []string, target interface{}, paren
 		var keyPart string
 		keyPart, options, err = parseTag(tags, parentOptions)
 		if err != nil {
-			err = fmt.Errorf("%w %s: %s", ErrBadTags, fieldName, err)
+			err = fmt.Errorf("%w %s: %w", ErrBadTags, fieldName, err)
 			return
 		}
 
+		// Ignore the field if it is not part of the configuration for the active profiles
+		if !inProfiles(e.profiles, options.profiles) {
+			continue
+		}
+
 		// If the key part is empty, use the field name. They will be formatted and joined later by a parameter source.
-		if keyPart == "" {
+		keyed := keyPart != ""
+		if !keyed {
 			keyPart = fieldName
 		}
 
+		// The description of the field can also be given by the companion 'skydoc' tag, which takes precedence.
+		if doc, ok := structField.Tag.Lookup("skydoc"); ok {
+			options.doc = doc
+		}
+
 		// If a field-id was not set, use the key part.
 		if options.id == "" {
 			options.id = keyPart
 		}
 
-		// Make the field key by appending the field key part to the prefix.
-		// This might be ignored if the field is flattened.
-		fieldKey := append(prefix, keyPart)
+		// Make the field key by appending the field key part to a copy of the prefix, which the other fields of the
+		// struct share. This might be ignored if the field is flattened.
+		fieldKey := append(slices.Clip(prefix), keyPart)
+
+		// An absolute key names the parameter as is, whatever the prefix and the source.
+		if strings.HasPrefix(keyPart, "/") {
+			options.absolute = keyPart
+			fieldKey = strings.Split(strings.TrimPrefix(keyPart, "/"), "/")
+		}
 
 		// If the field is a pointer, and it's nil, create a new instance.
 		// Iterate over the pointer until we get to the actual struct.
+		pointers := e.pointers
 		for f.Kind() == reflect.Ptr {
 			if f.IsNil() {
+				// Initialising a pointer to a struct being extracted would recurse endlessly
+				if slices.Contains(e.types, f.Type().Elem()) {
+					err = fmt.Errorf("%w: field %s of type %s", ErrRecursiveStruct, fieldName, f.Type())
+					return
+				}
+
 				// If the field is not a struct, we can't zero it out.
 				if f.Type().Elem().Kind() != reflect.Struct {
 					break
 				}
 
-				// Initialize the pointer with a new instance.
-				f.Set(reflect.New(f.Type().Elem()))
+				// Initialize the pointer with a new instance, or defer it until one of its fields is set.
+				if e.lazyPointers {
+					p := &lazyPointer{field: f, value: reflect.New(f.Type().Elem())}
+					pointers = append(slices.Clip(pointers), p)
+					f = p.value
+				} else {
+					f.Set(reflect.New(f.Type().Elem()))
+				}
 			}
 
 			// Drill down to the next level.
@@ -131,20 +419,35 @@ func extractFields(withUntagged bool, prefix []string, target interface{}, paren
 
 		// If the field is a struct, and it's not a Setter, TextUnmarshaler, or BinaryUnmarshaler, i.e. it can't
 		// deserialize itself, recursively extract fields, appending the field key as we go.
-		case f.Kind() == reflect.Struct &&
-			setterFrom(f) == nil && textUnmarshaler(f) == nil && binaryUnmarshaler(f) == nil:
+		case f.Kind() == reflect.Struct && !decodesItself(f):
+
+			if options.absolute != "" {
+				err = fmt.Errorf("%w %s: absolute key of a struct", ErrBadTags, fieldName)
+				return
+			}
 
-			// If the field is anonymous, and it's set to flatten, we don't want to append the field key part.
+			// If the field is set to flatten, or is anonymous and not given a key, we don't want to append the field
+			// key part. The options of the field, such as its source, still apply to the fields of the struct.
 			innerPrefix := fieldKey
-			if structField.Anonymous || options.flatten {
+			if options.flatten || structField.Anonymous && !keyed {
 				innerPrefix = prefix
 			}
 
+			if len(e.types) >= e.maxDepth {
+				err = fmt.Errorf("%w: field %s is nested deeper than %d structs", ErrMaxDepth, fieldName, e.maxDepth)
+				return
+			}
+
 			embeddedPtr := f.Addr().Interface()
 
 			// Recursively extract fields from the embedded struct.
 			var innerFields []fieldInfo
-			innerFields, err = extractFields(withUntagged, innerPrefix, embeddedPtr, options)
+			outer := e.pointers
+			e.pointers = pointers
+			e.names = append(e.names, fieldName)
+			innerFields, err = e.extract(innerPrefix, embeddedPtr, options)
+			e.names = e.names[:len(e.names)-1]
+			e.pointers = outer
 			if err != nil {
 				return
 			}
@@ -152,12 +455,41 @@ func extractFields(withUntagged bool, prefix []string, target interface{}, paren
 			// Append the inner fields to the list of fields.
 			fields = append(fields, innerFields...)
 
+		// If the field is a map of structs, it is populated from a subtree of parameters once the keys are known.
+		case isSubtree(f.Type()):
+			if options.absolute != "" {
+				err = fmt.Errorf("%w %s: absolute key of a subtree", ErrBadTags, fieldName)
+				return
+			}
+
+			fields = append(fields, fieldInfo{
+				nameParts:   fieldKey,
+				structField: f,
+				options:     options,
+				subtree:     true,
+				pointers:    pointers,
+				path:        e.path(fieldName),
+			})
+
+		// If values cannot be decoded into the field, fail early rather than when a value is found for it.
+		case !decodable(f):
+			if e.skipUnsupported {
+				e.skip(structField, "unsupported type")
+				continue
+			}
+
+			err = fmt.Errorf("%w: field %s of type %s; tag it with `sky:\"-\"` to ignore it", ErrUnsupportedType,
+				fieldName, f.Type())
+			return
+
 		default:
 			// Append the field to the list of fields.
 			fields = append(fields, fieldInfo{
 				nameParts:   fieldKey,
 				structField: f,
 				options:     options,
+				pointers:    pointers,
+				path:        e.path(fieldName),
 			})
 		}
 	}
@@ -165,8 +497,14 @@ func extractFields(withUntagged bool, prefix []string, target interface{}, paren
 	return fields, nil
 }
 
-// parseTag parses the tag and returns the key and options.
+// parseTag parses the tag and returns the key and options, ignoring unknown options.
 func parseTag(tag string, parentOptions fieldOptions) (key string, f fieldOptions, err error) {
+	return parseTagOptions(tag, parentOptions, false)
+}
+
+// parseTagOptions parses the tag and returns the key and options. If strict is true, unknown options, and options
+// missing their value or given one they do not take, are errors rather than ignored.
+func parseTagOptions(tag string, parentOptions fieldOptions, strict bool) (key string, f fieldOptions, err error) {
 	// Inherit the parent options.
 	f.inherit(parentOptions)
 
@@ -194,8 +532,21 @@ func parseTag(tag string, parentOptions fieldOptions) (key string, f fieldOption
 			switch prop {
 			case "optional":
 				f.optional = true
-			case "flatten":
+			case "flatten", "squash":
 				f.flatten = true
+			case "secret":
+				f.secret = true
+			case "manual":
+				f.manual = true
+			case "allowempty":
+				f.allowEmpty = true
+			case "deprecated":
+				f.deprecated = "parameter is deprecated"
+			default:
+				if strict {
+					err = unknownTagOption(prop, true)
+					return
+				}
 			}
 		case 2:
 			val := strings.TrimSpace(vals[1])
@@ -205,17 +556,103 @@ func parseTag(tag string, parentOptions fieldOptions) (key string, f fieldOption
 			}
 			switch prop {
 			case "default":
+				if f.defaultFrom != "" {
+					err = fmt.Errorf("tag %q conflicts with defaultfrom", prop)
+					return
+				}
 				f.defaultValue = val
+			case "profiles": // profiles the field is part of, separated by '|'
+				var ok bool
+				if f.profiles, ok = parseProfiles(val); !ok {
+					err = fmt.Errorf("invalid profiles %q", val)
+					return
+				}
+			case "when": // condition on the value of another field, as in "TLSEnabled=true"
+				if _, _, _, ok := parseCondition(val); !ok {
+					err = fmt.Errorf("invalid condition %q", val)
+					return
+				}
+				f.when = val
+			case "defaultfrom": // id of the field to take the default value from
+				if f.defaultValue != "" {
+					err = fmt.Errorf("tag %q conflicts with default", prop)
+					return
+				}
+				f.defaultFrom = val
 			case "source":
+				if slices.Contains(strings.Split(val, "|"), "") {
+					err = fmt.Errorf("invalid source %q", val)
+					return
+				}
 				f.source = val
 			case "refresh": // refresh is a duration
 				f.refresh, err = time.ParseDuration(val)
-				if err != nil || f.refresh <= 0 {
+				if err != nil {
 					err = fmt.Errorf("invalid duration %q: %w", val, err)
 					return
 				}
+				if f.refresh <= 0 {
+					err = fmt.Errorf("%w: %q is not positive", ErrRefreshInterval, val)
+					return
+				}
 			case "id":
 				f.id = val
+			case "desc":
+				f.doc = val
+			case "deprecated":
+				f.deprecated = val
+			case "ondelete": // what to do when the parameter is deleted from the source on refresh
+				if !slices.Contains([]string{"keep", "zero", "default", "error"}, val) {
+					err = fmt.Errorf("invalid ondelete %q", val)
+					return
+				}
+				f.onDelete = val
+			case "alias": // alias is a list of alternate keys separated by '|'
+				f.aliases = strings.Split(val, "|")
+				if slices.Contains(f.aliases, "") {
+					err = fmt.Errorf("invalid alias %q", val)
+					return
+				}
+				// An absolute key has no parent for a relative alias to be keyed under
+				for _, alias := range f.aliases {
+					if strings.HasPrefix(key, "/") && !strings.HasPrefix(alias, "/") {
+						err = fmt.Errorf("relative alias %q of an absolute key", alias)
+						return
+					}
+				}
+			case "transform": // transform is a list of transformer names separated by '|'
+				f.transform = strings.Split(val, "|")
+			case "encoding": // encoding of the value of a byte slice or array
+				if val != "hex" && val != "base64" {
+					err = fmt.Errorf("invalid encoding %q", val)
+					return
+				}
+				f.encoding = val
+			case "version": // version of the parameter to fetch
+				if n, e := strconv.ParseUint(val, 10, 64); e != nil || n == 0 {
+					err = fmt.Errorf("invalid version %q", val)
+					return
+				}
+				if f.selector != "" {
+					err = fmt.Errorf("tag %q conflicts with a version or label", prop)
+					return
+				}
+				f.selector = val
+			case "label": // label of the version of the parameter to fetch
+				if val[0] >= '0' && val[0] <= '9' || strings.ContainsAny(val, ":/") {
+					err = fmt.Errorf("invalid label %q", val)
+					return
+				}
+				if f.selector != "" {
+					err = fmt.Errorf("tag %q conflicts with a version or label", prop)
+					return
+				}
+				f.selector = val
+			default:
+				if strict {
+					err = unknownTagOption(prop, false)
+					return
+				}
 			}
 		}
 	}
@@ -223,10 +660,93 @@ func parseTag(tag string, parentOptions fieldOptions) (key string, f fieldOption
 	return
 }
 
+// unknownTagOption returns the error for an option unknown to parseTagOptions, given without a value if flag is true.
+func unknownTagOption(prop string, flag bool) error {
+	switch {
+	case flag && slices.Contains(tagValueOptions, prop):
+		return fmt.Errorf("tag %q missing a value", prop)
+	case !flag && slices.Contains(tagFlagOptions, prop):
+		return fmt.Errorf("tag %q does not take a value", prop)
+	}
+
+	return fmt.Errorf("%w %q", ErrUnknownTagOption, prop)
+}
+
+// rangeError wraps the error parsing the number into ErrValueOutOfRange, if it is out of the range of the type.
+func rangeError(value string, t reflect.Type, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%w: %s does not fit in %s; %w", ErrValueOutOfRange, value, t, err)
+	}
+
+	return err
+}
+
+// decodeFieldValue sets the value of a field based on its type and its options. If an encoding is specified, the value
+// is decoded into the bytes of the byte slice or array.
+func decodeFieldValue(isDefaultValue bool, value string, field reflect.Value, options fieldOptions) (err error) {
+	if options.encoding == "" {
+		return processFieldValue(isDefaultValue, value, field)
+	}
+
+	t := field.Type()
+
+	// If the field is a pointer, dereference it.
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if field.IsNil() {
+			field.Set(reflect.New(t))
+		}
+
+		field = field.Elem()
+	}
+
+	// If the field is a zero value, and the value is the default value, skip it.
+	if isDefaultValue && !field.IsZero() {
+		return nil
+	}
+
+	if (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) || t.Elem().Kind() != reflect.Uint8 {
+		return fmt.Errorf("encoding %s is not supported for type %s", options.encoding, t)
+	}
+
+	var b []byte
+	switch options.encoding {
+	case "hex":
+		b, err = hex.DecodeString(value)
+	case "base64":
+		b, err = base64.StdEncoding.DecodeString(value)
+	}
+	if err != nil {
+		return
+	}
+
+	if t.Kind() == reflect.Slice {
+		field.Set(reflect.ValueOf(b).Convert(t))
+		return
+	}
+
+	if len(b) != t.Len() {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrArrayLength, t.Len(), len(b))
+	}
+
+	reflect.Copy(field, reflect.ValueOf(b))
+	return
+}
+
 // processFieldValue sets the value of a field based on its type.
 func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (err error) {
 	t := field.Type()
 
+	// If a decoder is registered for the pointer type, use it.
+	if t.Kind() == reflect.Ptr && decoderFor(t) != nil {
+		if isDefaultValue && !field.IsNil() {
+			return nil
+		}
+
+		_, err = decodeRegistered(value, field)
+		return
+	}
+
 	// If the field is a pointer, dereference it.
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -242,6 +762,12 @@ func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (
 		return nil
 	}
 
+	// If a decoder is registered for the type, use it.
+	var registered bool
+	if registered, err = decodeRegistered(value, field); registered {
+		return
+	}
+
 	// If it implements the Setter interface, use it.
 	if setter := setterFrom(field); setter != nil {
 		return setter.Set(value)
@@ -272,6 +798,7 @@ func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (
 		} else {
 			// Otherwise, parse the integer.
 			val, err = strconv.ParseInt(value, 0, t.Bits())
+			err = rangeError(value, t, err)
 		}
 
 		if err == nil { // if no error
@@ -282,6 +809,11 @@ func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (
 		// Parse the unsigned integer.
 		var val uint64
 		val, err = strconv.ParseUint(value, 0, t.Bits())
+		if _, e := strconv.ParseInt(value, 0, 64); err != nil && e == nil {
+			// A negative integer is out of the range of an unsigned type, rather than invalid
+			err = fmt.Errorf("%w: %s", strconv.ErrRange, err)
+		}
+		err = rangeError(value, t, err)
 		if err == nil { // if no error
 			field.SetUint(val)
 		}
@@ -298,15 +830,18 @@ func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (
 		// Parse the float.
 		var val float64
 		val, err = strconv.ParseFloat(value, t.Bits())
+		err = rangeError(value, t, err)
 		if err == nil { // if no error
 			field.SetFloat(val)
 		}
 
 	case reflect.Slice:
 		// Split the value into parts and load them into the slice.
-		vals := strings.Split(value, ";")
-		sl := reflect.MakeSlice(t, len(vals), len(vals))
-		for i, val := range vals {
+		n := strings.Count(value, ";") + 1
+		sl := reflect.MakeSlice(t, n, n)
+		for i := range n {
+			var val string
+			val, value, _ = strings.Cut(value, ";")
 			err = processFieldValue(false, val, sl.Index(i))
 			if err != nil {
 				return
@@ -315,32 +850,59 @@ func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (
 
 		field.Set(sl)
 
+	case reflect.Array:
+		// Split the value into parts and load them into the array, which must be of the same length.
+		n := strings.Count(value, ";") + 1
+		if n != t.Len() {
+			err = fmt.Errorf("%w: expected %d items, got %d", ErrArrayLength, t.Len(), n)
+			return
+		}
+
+		arr := reflect.New(t).Elem()
+		for i := range n {
+			var val string
+			val, value, _ = strings.Cut(value, ";")
+			err = processFieldValue(false, val, arr.Index(i))
+			if err != nil {
+				return
+			}
+		}
+
+		field.Set(arr)
+
 	case reflect.Map:
 		// Split the value into pairs and load them into the map.
-		mp := reflect.MakeMap(t)
-		if len(strings.TrimSpace(value)) != 0 {
-			pairs := strings.Split(value, ";")
-			for _, pair := range pairs {
-				kvpair := strings.Split(pair, ":")
-				if len(kvpair) != 2 {
-					err = fmt.Errorf("invalid map item: %q", pair)
-					return
-				}
+		if len(strings.TrimSpace(value)) == 0 {
+			field.Set(reflect.MakeMap(t))
+			break
+		}
 
-				k := reflect.New(t.Key()).Elem()
-				err = processFieldValue(false, kvpair[0], k)
-				if err != nil {
-					return
-				}
+		// The key and value are reused for every pair, as SetMapIndex copies them
+		mp := reflect.MakeMapWithSize(t, strings.Count(value, ";")+1)
+		k := reflect.New(t.Key()).Elem()
+		v := reflect.New(t.Elem()).Elem()
+		for more := true; more; {
+			var pair string
+			pair, value, more = strings.Cut(value, ";")
+			key, val, ok := strings.Cut(pair, ":")
+			if !ok || strings.Contains(val, ":") {
+				err = fmt.Errorf("invalid map item: %q", pair)
+				return
+			}
 
-				v := reflect.New(t.Elem()).Elem()
-				err = processFieldValue(false, kvpair[1], v)
-				if err != nil {
-					return
-				}
+			k.SetZero()
+			err = processFieldValue(false, key, k)
+			if err != nil {
+				return
+			}
 
-				mp.SetMapIndex(k, v)
+			v.SetZero()
+			err = processFieldValue(false, val, v)
+			if err != nil {
+				return
 			}
+
+			mp.SetMapIndex(k, v)
 		}
 
 		field.Set(mp)
@@ -352,32 +914,138 @@ func processFieldValue(isDefaultValue bool, value string, field reflect.Value) (
 	return
 }
 
-func interfaceFrom(field reflect.Value, fn func(interface{}, *bool)) {
+// decodable returns true if processFieldValue can decode values into the field.
+func decodable(field reflect.Value) bool {
+	if decodesItself(field) {
+		return true
+	}
+
+	zero := func(t reflect.Type) reflect.Value {
+		return reflect.New(t).Elem()
+	}
+
+	t := field.Type()
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return decodable(zero(t.Elem()))
+	case reflect.Map:
+		return decodable(zero(t.Key())) && decodable(zero(t.Elem()))
+	case reflect.Struct, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Uintptr,
+		reflect.Complex64, reflect.Complex128:
+		return false
+	}
+
+	return true
+}
+
+// formatFieldValue formats the value of a field in a form that processFieldValue can read back. Types implementing
+// encoding.TextMarshaler or fmt.Stringer are formatted using them; slices and maps are formatted using the same
+// separators processFieldValue uses to split them.
+func formatFieldValue(field reflect.Value) (str string, err error) {
+	// If the field is a pointer, dereference it.
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", nil
+		}
+
+		field = field.Elem()
+	}
+
+	// If it implements the TextMarshaler use it.
+	if tm := textMarshaler(field); tm != nil {
+		var b []byte
+		b, err = tm.MarshalText()
+		return string(b), err
+	}
+
+	// If it implements the Stringer use it.
+	if s := stringer(field); s != nil {
+		return s.String(), nil
+	}
+
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		vals := make([]string, field.Len())
+		for i := range vals {
+			if vals[i], err = formatFieldValue(field.Index(i)); err != nil {
+				return
+			}
+		}
+
+		return strings.Join(vals, ";"), nil
+
+	case reflect.Map:
+		pairs := make([]string, 0, field.Len())
+		iter := field.MapRange()
+		for iter.Next() {
+			var k, v string
+			if k, err = formatFieldValue(iter.Key()); err != nil {
+				return
+			}
+			if v, err = formatFieldValue(iter.Value()); err != nil {
+				return
+			}
+			pairs = append(pairs, k+":"+v)
+		}
+
+		// Sort the pairs to make the output deterministic
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ";"), nil
+
+	default:
+		if !field.CanInterface() {
+			return "", fmt.Errorf("unexpected type %s when formatting values", field.Type())
+		}
+
+		return fmt.Sprintf("%v", field.Interface()), nil
+	}
+}
+
+// interfaceFrom returns the field, or a pointer to it, as an I, if its type implements I. The types are checked first,
+// as converting the field to an interface allocates.
+func interfaceFrom[I any](field reflect.Value) (i I) {
 	if !field.CanInterface() {
 		return
 	}
 
-	var ok bool
-	fn(field.Interface(), &ok)
-	if !ok && field.CanAddr() {
-		fn(field.Addr().Interface(), &ok)
+	iface := reflect.TypeFor[I]()
+	t := field.Type()
+	if t.Kind() == reflect.Interface || t.Implements(iface) {
+		var ok bool
+		if i, ok = field.Interface().(I); ok {
+			return
+		}
 	}
+
+	if field.CanAddr() && reflect.PointerTo(t).Implements(iface) {
+		i, _ = field.Addr().Interface().(I)
+	}
+
+	return
 }
 
 // setterFrom gets Setter from the field.
 func setterFrom(field reflect.Value) (s Setter) {
-	interfaceFrom(field, func(v interface{}, ok *bool) { s, *ok = v.(Setter) })
-	return s
+	return interfaceFrom[Setter](field)
 }
 
 // textUnmarshaler gets encoding.TextUnmarshaler from the field.
 func textUnmarshaler(field reflect.Value) (t encoding.TextUnmarshaler) {
-	interfaceFrom(field, func(v interface{}, ok *bool) { t, *ok = v.(encoding.TextUnmarshaler) })
-	return t
+	return interfaceFrom[encoding.TextUnmarshaler](field)
+}
+
+// textMarshaler gets encoding.TextMarshaler from the field.
+func textMarshaler(field reflect.Value) (t encoding.TextMarshaler) {
+	return interfaceFrom[encoding.TextMarshaler](field)
+}
+
+// stringer gets fmt.Stringer from the field.
+func stringer(field reflect.Value) (s fmt.Stringer) {
+	return interfaceFrom[fmt.Stringer](field)
 }
 
 // binaryUnmarshaler gets encoding.BinaryUnmarshaler from the field.
 func binaryUnmarshaler(field reflect.Value) (b encoding.BinaryUnmarshaler) {
-	interfaceFrom(field, func(v interface{}, ok *bool) { b, *ok = v.(encoding.BinaryUnmarshaler) })
-	return b
+	return interfaceFrom[encoding.BinaryUnmarshaler](field)
 }
@@ -1,8 +1,12 @@
 package skyconf
 
 import (
+	"context"
+	"encoding/hex"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -44,6 +48,27 @@ func Test_parseTag(t *testing.T) {
 			wantF:   fieldOptions{flatten: true},
 			wantErr: assert.NoError,
 		},
+		{
+			name:    "deprecated tag",
+			tag:     "key,deprecated",
+			wantKey: "key",
+			wantF:   fieldOptions{deprecated: "parameter is deprecated"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "deprecated tag with message",
+			tag:     "key,deprecated:use other_key",
+			wantKey: "key",
+			wantF:   fieldOptions{deprecated: "use other_key"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "squash tag",
+			tag:     "key,squash,source:source",
+			wantKey: "key",
+			wantF:   fieldOptions{flatten: true, source: "source"},
+			wantErr: assert.NoError,
+		},
 		{
 			name:    "default tag",
 			tag:     ",default:default",
@@ -58,6 +83,68 @@ func Test_parseTag(t *testing.T) {
 			wantF:   fieldOptions{source: "source"},
 			wantErr: assert.NoError,
 		},
+		{
+			name:    "secret tag",
+			tag:     ",secret",
+			wantKey: "",
+			wantF:   fieldOptions{secret: true},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "transform tag",
+			tag:     ",transform:base64|gzip",
+			wantKey: "",
+			wantF:   fieldOptions{transform: []string{"base64", "gzip"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "encoding tag",
+			tag:     ",encoding:hex",
+			wantKey: "",
+			wantF:   fieldOptions{encoding: "hex"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid encoding",
+			tag:     ",encoding:rot13",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "desc tag",
+			tag:     ",desc:Port of the server",
+			wantKey: "",
+			wantF:   fieldOptions{doc: "Port of the server"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "version tag",
+			tag:     ",version:3",
+			wantKey: "",
+			wantF:   fieldOptions{selector: "3"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid version",
+			tag:     ",version:latest",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "label tag",
+			tag:     ",label:stable",
+			wantKey: "",
+			wantF:   fieldOptions{selector: "stable"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid label",
+			tag:     ",label:1st",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "version and label tags",
+			tag:     ",version:3,label:stable",
+			wantErr: assert.Error,
+		},
 		{
 			name:    "optional,flatten,default,source tag",
 			tag:     ",optional,flatten,default:default,source:source",
@@ -165,6 +252,7 @@ func (m *mockBinaryUnmarshalerWithErr) UnmarshalBinary(_ []byte) error {
 func Test_processFieldValue(t *testing.T) {
 	nonZeroString := new(string)
 	*nonZeroString = "non-zero-value"
+	val1, val2 := "val1", "val2"
 
 	tests := []struct {
 		name           string
@@ -230,6 +318,21 @@ func Test_processFieldValue(t *testing.T) {
 			expected:       []string{},
 			expectErr:      true,
 		},
+		{
+			name:           "array field",
+			isDefaultValue: false,
+			value:          "a;b;c;d",
+			field:          reflect.ValueOf(new([4]string)).Elem(),
+			expected:       [4]string{"a", "b", "c", "d"},
+			expectErr:      false,
+		},
+		{
+			name:           "array field with mismatched length",
+			isDefaultValue: false,
+			value:          "a;b;c",
+			field:          reflect.ValueOf(new([4]string)).Elem(),
+			expectErr:      true,
+		},
 		{
 			name:           "map field",
 			isDefaultValue: false,
@@ -238,6 +341,14 @@ func Test_processFieldValue(t *testing.T) {
 			expected:       map[string]string{"key1": "val1", "key2": "val2"},
 			expectErr:      false,
 		},
+		{
+			name:           "map field of pointers",
+			isDefaultValue: false,
+			value:          "key1:val1;key2:val2",
+			field:          reflect.ValueOf(new(map[string]*string)).Elem(),
+			expected:       map[string]*string{"key1": &val1, "key2": &val2},
+			expectErr:      false,
+		},
 		{
 			name:           "map field with invalid entry",
 			isDefaultValue: false,
@@ -390,6 +501,74 @@ func Test_processFieldValue(t *testing.T) {
 	}
 }
 
+func Test_decodeFieldValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		encoding  string
+		field     reflect.Value
+		expected  interface{}
+		expectErr error
+	}{
+		{
+			name:     "hex byte array",
+			value:    "00112233445566778899aabbccddeeff",
+			encoding: "hex",
+			field:    reflect.ValueOf(new([16]byte)).Elem(),
+			expected: [16]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+		},
+		{
+			name:     "base64 byte slice",
+			value:    "aGVsbG8=",
+			encoding: "base64",
+			field:    reflect.ValueOf(new([]byte)).Elem(),
+			expected: []byte("hello"),
+		},
+		{
+			name:     "base64 pointer to byte array",
+			value:    "aGk=",
+			encoding: "base64",
+			field:    reflect.ValueOf(new(*[2]byte)).Elem(),
+			expected: &[2]byte{'h', 'i'},
+		},
+		{
+			name:      "byte array with mismatched length",
+			value:     "0011",
+			encoding:  "hex",
+			field:     reflect.ValueOf(new([4]byte)).Elem(),
+			expectErr: ErrArrayLength,
+		},
+		{
+			name:      "invalid hex",
+			value:     "xyz",
+			encoding:  "hex",
+			field:     reflect.ValueOf(new([]byte)).Elem(),
+			expectErr: hex.InvalidByteError('x'),
+		},
+		{
+			name:     "no encoding",
+			value:    "1;2",
+			field:    reflect.ValueOf(new([2]byte)).Elem(),
+			expected: [2]byte{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeFieldValue(false, tt.value, tt.field, fieldOptions{encoding: tt.encoding})
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, tt.field.Interface())
+			}
+		})
+	}
+
+	// Encoding is only supported for bytes
+	err := decodeFieldValue(false, "00", reflect.ValueOf(new(string)).Elem(), fieldOptions{encoding: "hex"})
+	assert.Error(t, err)
+}
+
 func Test_extractFields(t *testing.T) {
 	prefix := []string{"prefix"}
 	var target interface{}
@@ -434,6 +613,54 @@ func Test_extractFields(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, target.(*AConfig).A)
 
+	// Recursive struct types are rejected rather than initialised endlessly
+	type Node struct {
+		Value string `sky:"value"`
+		Next  *Node  `sky:"next"`
+	}
+
+	_, err = extractFields(true, nil, &Node{}, fieldOptions{})
+	assert.ErrorIs(t, err, ErrRecursiveStruct)
+	assert.ErrorContains(t, err, "field Next of type *skyconf.Node")
+
+	// Cycles of pointers are caught by the maximum depth
+	cyclic := &Node{}
+	cyclic.Next = cyclic
+	_, err = extractFields(true, nil, cyclic, fieldOptions{})
+	assert.ErrorIs(t, err, ErrMaxDepth)
+
+	e := &extraction{withUntagged: true, maxDepth: 2}
+	_, err = e.extract(nil, &struct {
+		A struct {
+			B struct {
+				C string
+			}
+		}
+	}{}, fieldOptions{})
+	assert.ErrorIs(t, err, ErrMaxDepth)
+
+	// Fields of unsupported types are rejected, unless skipped
+	type Unsupported struct {
+		Field1   string            `sky:"field1"`
+		Callback func()            `sky:"callback"`
+		Events   chan string       `sky:"-"`
+		Handlers map[string]func() `sky:"handlers"`
+		Any      interface{}       `sky:"any"`
+		Setter   Setter            `sky:"setter"`
+	}
+
+	_, err = extractFields(true, nil, &Unsupported{}, fieldOptions{})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	assert.ErrorContains(t, err, "field Callback of type func()")
+
+	e = &extraction{withUntagged: true, maxDepth: defaultMaxDepth, skipUnsupported: true}
+	gotFields, err := e.extract(nil, &Unsupported{Setter: new(mockSetter)}, fieldOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, gotFields, 2) {
+		assert.Equal(t, "field1", gotFields[0].options.id)
+		assert.Equal(t, "setter", gotFields[1].options.id)
+	}
+
 	// A realistic example
 	err = nil
 
@@ -467,7 +694,7 @@ func Test_extractFields(t *testing.T) {
 	target = &ConfigStruct{}
 
 	// withUntagged = true
-	gotFields, err := extractFields(true, prefix, target, fieldOptions{})
+	gotFields, err = extractFields(true, prefix, target, fieldOptions{})
 	assert.NoError(t, err)
 
 	expectedFields := []fieldInfo{
@@ -595,3 +822,287 @@ func Test_extractFields(t *testing.T) {
 		assert.Equal(t, expectedField.options, gotFields[i].options)
 	}
 }
+
+type mockTextMarshaler struct {
+	a, b string
+}
+
+func (m mockTextMarshaler) MarshalText() ([]byte, error) {
+	return []byte(m.a + "/" + m.b), nil
+}
+
+func (m *mockTextMarshaler) UnmarshalText(text []byte) error {
+	m.a, m.b, _ = strings.Cut(string(text), "/")
+	return nil
+}
+
+type mockStringer int
+
+func (m mockStringer) String() string {
+	return fmt.Sprintf("#%d", int(m))
+}
+
+func Test_extractFieldsFlatten(t *testing.T) {
+	type Database struct {
+		Host string `sky:"host"`
+		Port int    `sky:"port,source:local"`
+	}
+
+	tests := []struct {
+		name      string
+		target    interface{}
+		wantNames [][]string
+		wantSrc   []string
+	}{
+		{
+			name: "nested struct",
+			target: &struct {
+				DB Database `sky:"db,source:global"`
+			}{},
+			wantNames: [][]string{{"db", "host"}, {"db", "port"}},
+			wantSrc:   []string{"global", "local"},
+		},
+		{
+			name: "flatten with key and source",
+			target: &struct {
+				DB Database `sky:"db,flatten,source:global"`
+			}{},
+			wantNames: [][]string{{"host"}, {"port"}},
+			wantSrc:   []string{"global", "local"},
+		},
+		{
+			name: "squash",
+			target: &struct {
+				DB Database `sky:",squash"`
+			}{},
+			wantNames: [][]string{{"host"}, {"port"}},
+			wantSrc:   []string{"", "local"},
+		},
+		{
+			name: "anonymous",
+			target: &struct {
+				Database `sky:",source:global"`
+			}{},
+			wantNames: [][]string{{"host"}, {"port"}},
+			wantSrc:   []string{"global", "local"},
+		},
+		{
+			name: "anonymous with key",
+			target: &struct {
+				Database `sky:"db"`
+			}{},
+			wantNames: [][]string{{"db", "host"}, {"db", "port"}},
+			wantSrc:   []string{"", "local"},
+		},
+		{
+			name: "anonymous with key and flatten",
+			target: &struct {
+				Database `sky:"db,flatten"`
+			}{},
+			wantNames: [][]string{{"host"}, {"port"}},
+			wantSrc:   []string{"", "local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := extractFields(false, nil, tt.target, fieldOptions{})
+			if !assert.NoError(t, err) || !assert.Len(t, fields, len(tt.wantNames)) {
+				return
+			}
+
+			for i, field := range fields {
+				assert.Equal(t, tt.wantNames[i], field.nameParts)
+				assert.Equal(t, tt.wantSrc[i], field.options.source)
+			}
+		})
+	}
+}
+
+func Test_formatFieldValue(t *testing.T) {
+	str := "value"
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		want      string
+		roundTrip bool
+	}{
+		{name: "string", value: "value", want: "value", roundTrip: true},
+		{name: "int", value: -12, want: "-12", roundTrip: true},
+		{name: "bool", value: true, want: "true", roundTrip: true},
+		{name: "float", value: 1.5, want: "1.5", roundTrip: true},
+		{name: "duration", value: 90 * time.Second, want: "1m30s", roundTrip: true},
+		{name: "slice", value: []int{1, 2, 3}, want: "1;2;3", roundTrip: true},
+		{name: "map", value: map[string]int{"b": 2, "a": 1}, want: "a:1;b:2", roundTrip: true},
+		{name: "text marshaler", value: mockTextMarshaler{"x", "y"}, want: "x/y", roundTrip: true},
+		{name: "stringer", value: mockStringer(7), want: "#7"},
+		{name: "pointer", value: &str, want: "value", roundTrip: true},
+		{name: "nil pointer", value: (*string)(nil), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatFieldValue(reflect.ValueOf(tt.value))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+
+			if tt.roundTrip {
+				v := reflect.New(reflect.TypeOf(tt.value)).Elem()
+				if assert.NoError(t, processFieldValue(false, got, v)) {
+					assert.Equal(t, tt.value, v.Interface())
+				}
+			}
+		})
+	}
+}
+
+type thirdPartyOptions struct {
+	Verbose bool
+}
+
+type thirdParty struct {
+	thirdPartyOptions
+	Name    string `sky:"name"`
+	handler func()
+	secret  string `sky:"secret"`
+}
+
+func TestSkippedFields(t *testing.T) {
+	source := &mockSource{
+		ps:   mockParameterStore{"/path/client/name": "client", "/path/level": "info"},
+		path: "/path/",
+	}
+
+	cfg := &struct {
+		Client   thirdParty `sky:"client"`
+		Level    string     `sky:"level"`
+		Callback func()     `sky:"callback"`
+		internal string     `sky:"-"`
+	}{}
+
+	var skipped []SkippedField
+	_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithSkipUnsupported(),
+		WithSkippedFields(func(s []SkippedField) {
+			skipped = s
+		}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "client", cfg.Client.Name)
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, []SkippedField{
+		{Name: "Client.thirdPartyOptions", Type: "skyconf.thirdPartyOptions", Reason: "unexported embedded struct"},
+		{Name: "Client.secret", Type: "string", Reason: "unexported field"},
+		{Name: "Callback", Type: "func()", Reason: "unsupported type"},
+	}, skipped)
+}
+
+func Test_extractFieldsNameParts(t *testing.T) {
+	type Leaf struct {
+		Host string `sky:"Host"`
+		Port int    `sky:"PortNumber"`
+	}
+	type Level3 struct {
+		Leaf Leaf `sky:"Leaf"`
+	}
+	type Level2 struct {
+		Level3 Level3 `sky:"Level3"`
+	}
+	var cfg struct {
+		Level2 Level2 `sky:"Level2"`
+		Other  Leaf   `sky:"Other"`
+	}
+
+	// Fields sharing a prefix must not share the backing array of their name parts
+	fields, err := extractFields(false, nil, &cfg, fieldOptions{})
+	assert.NoError(t, err)
+	want := [][]string{
+		{"Level2", "Level3", "Leaf", "Host"},
+		{"Level2", "Level3", "Leaf", "PortNumber"},
+		{"Other", "Host"},
+		{"Other", "PortNumber"},
+	}
+	var got [][]string
+	for _, field := range fields {
+		got = append(got, field.nameParts)
+	}
+	assert.Equal(t, want, got)
+
+	// Naming the parameters in several sources must not alter the name parts of the fields
+	sources := []Source{
+		SSMSource(nil, "/app"),
+		SSMSourceWithOptions(nil, "/public", "public", WithVerbatimKeys()),
+		&mockSource{path: "/mock/"},
+	}
+	wantNames := map[string][]string{
+		"ssm":    {"/app/level2/level3/leaf/host", "/app/level2/level3/leaf/port_number", "/app/other/host", "/app/other/port_number"},
+		"public": {"/public/Level2/Level3/Leaf/Host", "/public/Level2/Level3/Leaf/PortNumber", "/public/Other/Host", "/public/Other/PortNumber"},
+		"mock":   {"/mock/level2/level3/leaf/host", "/mock/level2/level3/leaf/port_number", "/mock/other/host", "/mock/other/port_number"},
+	}
+	for i := 0; i < 2; i++ {
+		for _, source := range sources {
+			var names []string
+			for _, field := range fields {
+				names = append(names, field.name(source))
+			}
+			assert.Equal(t, wantNames[source.ID()], names)
+		}
+	}
+
+	got = nil
+	for _, field := range fields {
+		got = append(got, field.nameParts)
+	}
+	assert.Equal(t, want, got)
+
+	// Describing the configuration again gives the same names
+	first, err := String(&cfg, false, false, sources...)
+	assert.NoError(t, err)
+	second, err := String(&cfg, false, false, sources...)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Contains(t, first, "/public/Level2/Level3/Leaf/PortNumber")
+}
+
+func BenchmarkExtractFields(b *testing.B) {
+	cfg, _ := largeConfig(500)
+	o := makeOptions(nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := o.extractFields(cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcessFieldValue(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		value string
+		field interface{}
+	}{
+		{"int", "42", new(int)},
+		{"duration", "1m30s", new(time.Duration)},
+		{"slice", "1;2;3;4;5;6;7;8", new([]int)},
+		{"pointers", "a;b;c;d", new([]*string)},
+		{"array", "1;2;3;4", new([4]uint8)},
+		{"map", "a:1;b:2;c:3;d:4", new(map[string]int)},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			field := reflect.ValueOf(bm.field).Elem()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := processFieldValue(false, bm.value, field); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
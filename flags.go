@@ -0,0 +1,132 @@
+package skyconf
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Flags gives lock-free access to the current values of the fields of a configuration struct, such as hot-reloadable
+// feature flags read from hot paths. The values are kept current by the Refresher the Flags are created from, as they
+// are refreshed, without callers locking the configuration struct or implementing their own atomics.
+type Flags struct {
+	values map[string]*atomic.Pointer[flagValue]
+}
+
+// ErrUnknownRefresher is returned when a Refresher was not returned by Parse.
+var ErrUnknownRefresher = errors.New("refresher not returned by Parse")
+
+// flagValue is the value of a field, along with its boolean, string and numeric representations, as applicable.
+type flagValue struct {
+	value any
+	b     bool
+	s     string
+	i     int64
+	f     float64
+}
+
+// NewFlags returns Flags for the fields of the configuration struct refreshed by the Refresher, as returned by Parse.
+// Where fields share an ID, the first one is used.
+func NewFlags(r Refresher) (*Flags, error) {
+	u, ok := r.(*updater)
+	if !ok {
+		return nil, ErrUnknownRefresher
+	}
+
+	flags := &Flags{values: make(map[string]*atomic.Pointer[flagValue], len(u.fields))}
+
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	for _, f := range u.fields {
+		id := f.field.options.id
+		if _, ok := flags.values[id]; ok {
+			continue
+		}
+
+		p := &atomic.Pointer[flagValue]{}
+		f.rlocker.Lock()
+		p.Store(newFlagValue(f.field.structField))
+		f.rlocker.Unlock()
+		flags.values[id] = p
+	}
+
+	u.flags = append(u.flags, flags)
+	return flags, nil
+}
+
+// newFlagValue returns the value of the field, dereferencing pointers.
+func newFlagValue(v reflect.Value) *flagValue {
+	fv := &flagValue{value: v.Interface()}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fv
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		fv.b = v.Bool()
+	case reflect.String:
+		fv.s = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.i, fv.f = v.Int(), float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.i, fv.f = int64(v.Uint()), float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fv.i, fv.f = int64(v.Float()), v.Float()
+	default:
+	}
+
+	return fv
+}
+
+// store records the value of the field, if tracked; it is called with the configuration struct locked.
+func (fl *Flags) store(field fieldInfo) {
+	if p, ok := fl.values[field.options.id]; ok {
+		p.Store(newFlagValue(field.structField))
+	}
+}
+
+// get returns the current value of the field with the given ID, or a zero value if there is no such field.
+func (fl *Flags) get(id string) *flagValue {
+	if p, ok := fl.values[id]; ok {
+		return p.Load()
+	}
+
+	return &flagValue{}
+}
+
+// Value returns the current value of the field with the given ID, or nil if there is no such field.
+func (fl *Flags) Value(id string) any {
+	return fl.get(id).value
+}
+
+// Bool returns the current value of the boolean field with the given ID, or false if the field is not a boolean.
+func (fl *Flags) Bool(id string) bool {
+	return fl.get(id).b
+}
+
+// String returns the current value of the string field with the given ID, or "" if the field is not a string.
+func (fl *Flags) String(id string) string {
+	return fl.get(id).s
+}
+
+// Int returns the current value of the numeric field with the given ID as an integer, or 0 if the field is not a
+// number.
+func (fl *Flags) Int(id string) int64 {
+	return fl.get(id).i
+}
+
+// Float returns the current value of the numeric field with the given ID as a float, or 0 if the field is not a
+// number.
+func (fl *Flags) Float(id string) float64 {
+	return fl.get(id).f
+}
+
+// Duration returns the current value of the time.Duration field with the given ID, or 0 if the field is not a number.
+func (fl *Flags) Duration(id string) time.Duration {
+	return time.Duration(fl.get(id).i)
+}
@@ -0,0 +1,74 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFlags(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/new_checkout": "false",
+			"/path/banner":       "hello",
+			"/path/rate":         "0.5",
+			"/path/limit":        "10",
+			"/path/timeout":      "1s",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		NewCheckout bool          `sky:"newCheckout,refresh:1m"`
+		Banner      string        `sky:"banner,refresh:1m"`
+		Rate        float64       `sky:"rate,refresh:1m"`
+		Limit       uint          `sky:"limit,refresh:1m"`
+		Timeout     time.Duration `sky:"timeout,refresh:1m"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	flags, err := NewFlags(r)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, flags.Bool("newCheckout"))
+	assert.Equal(t, "hello", flags.String("banner"))
+	assert.Equal(t, 0.5, flags.Float("rate"))
+	assert.Equal(t, int64(10), flags.Int("limit"))
+	assert.Equal(t, time.Second, flags.Duration("timeout"))
+	assert.Equal(t, uint(10), flags.Value("limit"))
+
+	// Unknown fields and mismatched types read as zero values
+	assert.False(t, flags.Bool("unknown"))
+	assert.Nil(t, flags.Value("unknown"))
+	assert.Equal(t, "", flags.String("newCheckout"))
+
+	// Flags are read without locking while the fields are refreshed
+	source.set("/path/new_checkout", "true")
+	source.set("/path/banner", "bye")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_ = flags.Bool("newCheckout")
+		}
+	}()
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	wg.Wait()
+
+	assert.True(t, flags.Bool("newCheckout"))
+	assert.Equal(t, "bye", flags.String("banner"))
+
+	_, err = NewFlags(noRefresh)
+	assert.ErrorIs(t, err, ErrUnknownRefresher)
+}
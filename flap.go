@@ -0,0 +1,95 @@
+package skyconf
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// maxFlapSuppression caps the time the changes of a flapping field are suppressed for.
+const maxFlapSuppression = time.Hour
+
+// WithFlapGuard protects the service from fields changing too often on refresh, such as when an upstream automation
+// keeps rewriting their parameters. Once a field has changed limit times within the window, it is flapping: its next
+// changes are suppressed, and the field keeps its value, for the duration of the window. Each time the field flaps
+// again after the suppression, it is suppressed for twice as long as the time before, up to an hour; it is suppressed
+// for the duration of the window again once it has stayed stable for as long as it was last suppressed.
+//
+// The latest value of the parameter is set by the first refresh after the suppression. Flapping fields are logged,
+// reported by Status, and recorded if the metrics implement FlapMetrics.
+func WithFlapGuard(limit int, window time.Duration) Option {
+	return func(o *options) {
+		o.flapLimit = max(limit, 0)
+		o.flapWindow = window
+	}
+}
+
+// FlapMetrics is implemented by Metrics that also record the fields whose changes are suppressed because they change
+// too often; see WithFlapGuard.
+type FlapMetrics interface {
+	// FieldSuppressed records that the changes of the field with the given ID are suppressed for the duration.
+	FieldSuppressed(id string, d time.Duration)
+}
+
+// flapState tracks the changes of a field, to tell when it is flapping.
+type flapState struct {
+	changes    []time.Time   // the times the field changed within the window
+	until      time.Time     // the end of the suppression of the changes
+	suppressed time.Duration // the duration of the last suppression; 0 if the field has been stable since
+}
+
+// now returns the current time from the clock of the refresher, if set.
+func (u *updater) now() time.Time {
+	if u.clock != nil {
+		return u.clock.Now()
+	}
+
+	return time.Now()
+}
+
+// suppress records the change of the value of the field found on refresh, and returns true if the change must be
+// suppressed, the field flapping.
+func (u *updater) suppress(ctx context.Context, f *refreshedField, source Source, key, value string) bool {
+	if u.opts.flapLimit == 0 {
+		return false
+	}
+
+	now := u.now()
+
+	u.m.Lock()
+	g := &f.flap
+	switch {
+	case u.opts.hashValue(value) == f.valueHash:
+		u.m.Unlock()
+		return false
+	case now.Before(g.until):
+		u.m.Unlock()
+		return true
+	}
+
+	// Forget the changes out of the window, and the last suppression if the field has been stable since
+	g.changes = slices.DeleteFunc(g.changes, func(t time.Time) bool { return now.Sub(t) >= u.opts.flapWindow })
+	if g.suppressed > 0 && len(g.changes) == 0 && now.Sub(g.until) >= g.suppressed {
+		g.suppressed = 0
+	}
+
+	if len(g.changes) < u.opts.flapLimit {
+		g.changes = append(g.changes, now)
+		u.m.Unlock()
+		return false
+	}
+
+	g.suppressed = min(max(2*g.suppressed, u.opts.flapWindow), maxFlapSuppression)
+	g.until = now.Add(g.suppressed)
+	g.changes = nil
+	d := g.suppressed
+	u.m.Unlock()
+
+	u.opts.logger.WarnContext(ctx, "field flapping, suppressing changes", "field", f.field.options.id,
+		"source", source.ID(), "parameter", key, "duration", d)
+	if m, ok := u.opts.metrics.(FlapMetrics); ok {
+		m.FieldSuppressed(f.field.options.id, d)
+	}
+
+	return true
+}
@@ -0,0 +1,78 @@
+package skyconf
+
+import (
+	"code.cloudfoundry.org/clock/fakeclock"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithFlapGuard(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/app/rate": "0", "/app/name": "a"},
+		path:        "/app/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Rate int    `sky:"rate,refresh:10s"`
+		Name string `sky:"name,refresh:10s"`
+	}{}
+
+	metrics := &mockMetrics{}
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithFlapGuard(2, time.Minute),
+		WithMetrics(metrics))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start := time.Now()
+	clock := fakeclock.NewFakeClock(start)
+	r.(*updater).clock = clock
+
+	rate := 0
+	change := func(after time.Duration) {
+		rate++
+		source.set("/app/rate", strconv.Itoa(rate))
+		clock.Increment(after)
+		assert.NoError(t, r.RefreshOnce(context.Background()))
+	}
+
+	// The field is suppressed once it has changed twice within a minute
+	change(0)
+	change(10 * time.Second)
+	assert.Equal(t, 2, cfg.Rate)
+	change(10 * time.Second)
+	change(10 * time.Second)
+	assert.Equal(t, 2, cfg.Rate)
+	assert.Equal(t, start.Add(80*time.Second), r.Status()[0].Suppressed)
+	assert.True(t, r.Status()[1].Suppressed.IsZero())
+
+	// Other fields are refreshed
+	source.set("/app/name", "b")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "b", cfg.Name)
+
+	// The latest value is set after the suppression, which doubles when the field flaps again
+	clock.Increment(51 * time.Second)
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 4, cfg.Rate)
+	assert.True(t, r.Status()[0].Suppressed.IsZero())
+	change(9 * time.Second)
+	change(10 * time.Second)
+	assert.Equal(t, 5, cfg.Rate)
+	assert.Equal(t, start.Add(220*time.Second), r.Status()[0].Suppressed)
+
+	// The suppression is reset once the field has been stable for as long as it was suppressed
+	clock.Increment(121 * time.Second)
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 6, cfg.Rate)
+	change(179 * time.Second)
+	change(time.Second)
+	change(time.Second)
+	assert.Equal(t, 8, cfg.Rate)
+
+	assert.Equal(t, map[string][]time.Duration{"rate": {time.Minute, 2 * time.Minute, time.Minute}}, metrics.flapping)
+}
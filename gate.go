@@ -0,0 +1,104 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Gate is consulted before each periodic refresh cycle to decide whether this instance polls the sources. In a
+// horizontally scaled deployment, it can be backed by a distributed lock so that only the leader polls the sources,
+// propagating the values to the other instances; see WithGate.
+type Gate interface {
+	// Allow returns true if this instance may poll the sources in the current refresh cycle.
+	Allow(ctx context.Context) bool
+}
+
+// GateFunc is a function that implements Gate.
+type GateFunc func(ctx context.Context) bool
+
+// Allow calls f(ctx).
+func (f GateFunc) Allow(ctx context.Context) bool {
+	return f(ctx)
+}
+
+// PropagateFunc is called with the values fetched from a source in a refresh cycle, keyed by parameter name, so that
+// they can be passed on to the instances that do not poll the sources, to be applied using Refresher.Receive. The values
+// are not redacted; they must be propagated securely.
+type PropagateFunc func(ctx context.Context, sourceID string, values map[string]string)
+
+// openGate is the default Gate; it always allows polling the sources.
+type openGate struct{}
+
+func (openGate) Allow(context.Context) bool {
+	return true
+}
+
+// WithGate sets the gate consulted before each periodic refresh cycle, and the function called with the values fetched
+// in each cycle, if any. Calls to RefreshOnce and RefreshNow are not gated.
+func WithGate(g Gate, propagate PropagateFunc) Option {
+	return func(o *options) {
+		o.gate = g
+		o.propagate = propagate
+	}
+}
+
+// Receive applies the values propagated from another instance, keyed by parameter name, as if they were fetched from
+// the source with the given ID by a refresh. Values of parameters that are not used by any of the fields are ignored.
+func (u *updater) Receive(ctx context.Context, sourceID string, values map[string]string) (err error) {
+	found := false
+	for _, source := range u.sources {
+		if source.ID() != sourceID {
+			continue
+		}
+
+		// The configuration structs of a RefresherGroup may each have a source with the ID
+		found = true
+		if e := u.receive(ctx, source, values); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("'%s' : %w", sourceID, ErrSourceNotFound)
+	}
+
+	return
+}
+
+// receive applies the values, keyed by parameter name, to the fields whose value was set from the source.
+func (u *updater) receive(ctx context.Context, source Source, values map[string]string) (err error) {
+	var candidates []candidate
+	for _, f := range u.fields {
+		u.m.Lock()
+		key, current, paused := f.key, f.source, f.paused
+		u.m.Unlock()
+
+		// Only fields whose value was set from the source are refreshed by it
+		if current != source || paused {
+			continue
+		}
+
+		value, ok := values[key]
+		if !ok || u.opts.isMissing(f.field, value) {
+			continue
+		}
+
+		candidates = append(candidates, candidate{f: f, source: source, key: key, value: value})
+	}
+
+	// Validate the values that have changed, and set those accepted
+	rejected := u.validate(ctx, candidates)
+	for _, c := range candidates {
+		e := rejected[c.f]
+		if e == nil {
+			if _, e = u.apply(ctx, c.f, source, c.key, c.value, Metadata{}); e != nil {
+				e = c.f.field.valueError(StageRefresh, source, c.key, e)
+			}
+		}
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return
+}
@@ -0,0 +1,90 @@
+package skyconf
+
+import (
+	"code.cloudfoundry.org/clock/fakeclock"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGate(t *testing.T) {
+	leaderSource := &mockSource{
+		ps:          mockParameterStore{"/path/param1": "value1"},
+		path:        "/path/",
+		refreshable: true,
+	}
+	followerSource := &mockSource{
+		ps:          mockParameterStore{"/path/param1": "value1"},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	type config struct {
+		Param1 string `sky:",refresh:1s"`
+	}
+
+	var leader atomic.Bool
+	var m sync.Mutex
+	var propagated []map[string]string
+	propagate := func(_ context.Context, sourceID string, values map[string]string) {
+		m.Lock()
+		defer m.Unlock()
+		assert.Equal(t, "mock", sourceID)
+		propagated = append(propagated, values)
+	}
+
+	leaderCfg := &config{}
+	r, err := ParseWithOptions(context.Background(), leaderCfg, []Source{leaderSource},
+		WithGate(GateFunc(func(context.Context) bool { return leader.Load() }), propagate))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	followerCfg := &config{}
+	follower, err := Parse(context.Background(), followerCfg, false, followerSource)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	clock := fakeclock.NewFakeClock(time.Now())
+	r.(*updater).clock = clock
+
+	updates := r.Refresh(context.Background(), nil)
+	defer func() {
+		assert.NoError(t, r.Close())
+	}()
+
+	// The gate is closed; the source is not polled
+	leaderSource.set("/path/param1", "value2")
+	clock.WaitForWatcherAndIncrement(time.Second + time.Millisecond)
+	select {
+	case <-updates:
+		assert.Fail(t, "refreshed while the gate was closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, "value1", leaderCfg.Param1)
+
+	// The gate is open; the source is polled and the values propagated
+	leader.Store(true)
+	clock.Increment(time.Second + time.Millisecond)
+	select {
+	case id := <-updates:
+		assert.Equal(t, "Param1", id)
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for update")
+		return
+	}
+	assert.Equal(t, "value2", leaderCfg.Param1)
+
+	m.Lock()
+	if assert.Len(t, propagated, 1) {
+		assert.NoError(t, follower.Receive(context.Background(), "mock", propagated[0]))
+	}
+	m.Unlock()
+	assert.Equal(t, "value2", followerCfg.Param1)
+
+	assert.ErrorIs(t, follower.Receive(context.Background(), "unknown", nil), ErrSourceNotFound)
+}
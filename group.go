@@ -0,0 +1,65 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// ErrRefresherStarted is returned by RefresherGroup.Parse once the refresher of the group has been started.
+var ErrRefresherStarted = errors.New("refresher already started")
+
+// RefresherGroup parses several configuration structs, each from its own sources, such as the same parameter store
+// under different paths, into a single Refresher. The fields of all the structs are refreshed by the same tickers,
+// clock and metrics, with the parameters of the fields sharing a source and a refresh interval fetched together, and
+// their updates sent to a single channel. Each struct is locked on its own when it is lockable.
+//
+// The IDs of the fields identify them in updates and in Pause, Resume and Watch; they should be unique across the
+// structs of the group.
+type RefresherGroup struct {
+	opts []Option
+	u    *updater
+}
+
+// NewRefresherGroup returns an empty RefresherGroup whose structs are parsed with the options.
+func NewRefresherGroup(opts ...Option) *RefresherGroup {
+	return &RefresherGroup{
+		opts: opts,
+		u:    newUpdater(makeOptions(opts), nil, nil),
+	}
+}
+
+// Parse populates the configuration struct from the sources, as ParseWithOptions does with the options of the group,
+// and adds its fields to the refresher of the group. It returns ErrRefresherStarted once the refresher has been
+// started; all the structs must be parsed beforehand.
+func (g *RefresherGroup) Parse(ctx context.Context, cfg interface{}, sources ...Source) (err error) {
+	if g.u.timings != nil {
+		return ErrRefresherStarted
+	}
+
+	var r Refresher
+	if r, err = ParseWithOptions(ctx, cfg, sources, g.opts...); err != nil {
+		return
+	}
+
+	g.u.merge(r.(*updater))
+	return
+}
+
+// Refresher returns the refresher of all the structs parsed by the group.
+func (g *RefresherGroup) Refresher() Refresher {
+	return g.u
+}
+
+// merge adds the fields and the sources of the other updater, which must not have been started.
+func (u *updater) merge(other *updater) {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	u.fields = append(u.fields, other.fields...)
+	for _, source := range other.sources {
+		if !slices.Contains(u.sources, source) {
+			u.sources = append(u.sources, source)
+		}
+	}
+}
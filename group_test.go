@@ -0,0 +1,85 @@
+package skyconf
+
+import (
+	"code.cloudfoundry.org/clock/fakeclock"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRefresherGroup(t *testing.T) {
+	ps := mockParameterStore{
+		"/a/param1":    "a1",
+		"/b/host":      "b1",
+		"/b/no_update": "b2",
+		"/b/param1":    "not for a",
+	}
+	sourceA := &mockSource{ps: ps, path: "/a/", refreshable: true}
+	sourceB := &mockSource{ps: ps, path: "/b/", refreshable: true}
+
+	cfgA := &rwConfig{}
+	cfgB := &struct {
+		Host     string `sky:"host,refresh:1s"`
+		NoUpdate string `sky:"no_update"`
+	}{}
+
+	g := NewRefresherGroup()
+	if !assert.NoError(t, g.Parse(context.Background(), cfgA, sourceA)) {
+		return
+	}
+	if !assert.NoError(t, g.Parse(context.Background(), cfgB, sourceB)) {
+		return
+	}
+	assert.Equal(t, "a1", cfgA.Param1)
+	assert.Equal(t, "b1", cfgB.Host)
+	assert.Equal(t, "b2", cfgB.NoUpdate)
+
+	r := g.Refresher()
+	clock := fakeclock.NewFakeClock(time.Now())
+	r.(*updater).clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := r.Refresh(ctx, nil)
+
+	// The fields of both structs are refreshed by the same ticker, and their updates sent to the same channel
+	ps.set("/a/param1", "a2")
+	ps.set("/b/host", "b3")
+	ps.set("/b/no_update", "b4")
+	assert.Len(t, r.(*updater).timings, 1)
+	clock.WaitForWatcherAndIncrement(time.Second + time.Millisecond)
+
+	var ids []string
+	for len(ids) < 2 {
+		select {
+		case id := <-updates:
+			ids = append(ids, id)
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for updates")
+			return
+		}
+	}
+	assert.ElementsMatch(t, []string{"Param1", "host"}, ids)
+
+	cfgA.RLock()
+	assert.Equal(t, "a2", cfgA.Param1)
+	cfgA.RUnlock()
+	assert.Positive(t, cfgA.writes.Load())
+
+	// Each struct is refreshed only from its own sources
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.Equal(t, "b4", cfgB.NoUpdate)
+	cfgA.RLock()
+	assert.Equal(t, "a2", cfgA.Param1)
+	cfgA.RUnlock()
+
+	// Values received for a source ID apply to the fields of all the structs with a source of that ID
+	assert.NoError(t, r.Receive(context.Background(), "mock", map[string]string{"/a/param1": "a5", "/b/host": "b5"}))
+	cfgA.RLock()
+	assert.Equal(t, "a5", cfgA.Param1)
+	cfgA.RUnlock()
+	assert.Equal(t, "b5", cfgB.Host)
+
+	assert.ErrorIs(t, g.Parse(context.Background(), &struct{}{}, sourceA), ErrRefresherStarted)
+}
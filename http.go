@@ -0,0 +1,213 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPOption configures an HTTP source.
+type HTTPOption func(s *httpSource)
+
+// WithHTTPClient sets the client used by an HTTP source; http.DefaultClient is used by default.
+func WithHTTPClient(c *http.Client) HTTPOption {
+	return func(s *httpSource) {
+		s.client = c
+	}
+}
+
+// WithHeader sets a header sent with each request made by an HTTP source, such as Authorization.
+func WithHeader(key, value string) HTTPOption {
+	return func(s *httpSource) {
+		s.header.Set(key, value)
+	}
+}
+
+// WithRequestEditor sets a function called to edit each request made by an HTTP source before it is sent, such as to
+// sign it or to set a token that expires.
+func WithRequestEditor(fn func(req *http.Request) error) HTTPOption {
+	return func(s *httpSource) {
+		s.editors = append(s.editors, fn)
+	}
+}
+
+// WithHTTPSourceOptions sets the options limiting the requests made by an HTTP source.
+func WithHTTPSourceOptions(opts ...SourceOption) HTTPOption {
+	return func(s *httpSource) {
+		s.limiter = newLimiter(makeSourceOptions(opts))
+	}
+}
+
+type httpSource struct {
+	url     string
+	id      string
+	client  *http.Client
+	header  http.Header
+	editors []func(req *http.Request) error
+	limiter *limiter
+
+	m       sync.Mutex
+	etag    string            // ETag of the document last downloaded
+	expires time.Time         // time until which the document last downloaded is fresh, as told by its Cache-Control
+	values  map[string]string // the document last downloaded, flattened
+}
+
+// HTTPSource creates a new source with a custom ID reading parameters from a JSON document served at the URL, such as
+// by a configuration service. The document is flattened in the same way as for S3SourceWithOptions.
+//
+// The document is not requested again while it is fresh, as told by the max-age directive of its Cache-Control
+// header; afterward, it is only downloaded again if it has changed, as told by its ETag.
+func HTTPSource(url, id string, opts ...HTTPOption) Source {
+	s := &httpSource{
+		url:     url,
+		id:      id,
+		client:  http.DefaultClient,
+		header:  make(http.Header),
+		limiter: newLimiter(sourceOptions{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *httpSource) Source(ctx context.Context, keys []string) (values map[string]string, err error) {
+	// Ensure there are keys to fetch
+	if len(keys) == 0 {
+		return
+	}
+
+	var document map[string]string
+	if document, err = s.document(ctx); err != nil {
+		return
+	}
+
+	values = documentValues(document, keys)
+	return
+}
+
+// ListKeys lists the parameters of the document under the path formed by the parts.
+func (s *httpSource) ListKeys(ctx context.Context, parts []string) (keys []string, err error) {
+	var document map[string]string
+	if document, err = s.document(ctx); err != nil {
+		return
+	}
+
+	keys = documentKeys(document, s.ParameterName(parts))
+	return
+}
+
+func (s *httpSource) ParameterName(parts []string) string {
+	return makeParameterName("", parts)
+}
+
+func (s *httpSource) ID() string {
+	return s.id
+}
+
+func (s *httpSource) Refreshable() bool {
+	return true
+}
+
+// document returns the flattened document, requesting it only if it is no longer fresh, and downloading it only if
+// it has changed since it was last downloaded.
+func (s *httpSource) document(ctx context.Context) (document map[string]string, err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.values != nil && time.Now().Before(s.expires) {
+		return s.values, nil
+	}
+
+	var res *http.Response
+	var data []byte
+	err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+		var req *http.Request
+		if req, err = http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil); err != nil {
+			return
+		}
+
+		req.Header = s.header.Clone()
+		req.Header.Set("Accept", "application/json")
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+
+		for _, edit := range s.editors {
+			if err = edit(req); err != nil {
+				return
+			}
+		}
+
+		if res, err = s.client.Do(req); err != nil {
+			return
+		}
+		defer res.Body.Close()
+
+		// Read the document before the timeout of the request is cancelled, as the body may not be buffered yet
+		if res.StatusCode == http.StatusOK {
+			if data, err = io.ReadAll(res.Body); err != nil {
+				err = fmt.Errorf("failed to read document: %w", err)
+			}
+		}
+		return
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get document: %w", err)
+		return
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		// The document has not changed; use the document last downloaded
+		if s.values != nil {
+			s.expires = cacheExpiry(res.Header)
+			return s.values, nil
+		}
+		fallthrough
+	default:
+		err = fmt.Errorf("failed to get document: unexpected status %s", res.Status)
+		return
+	}
+
+	if document, err = flattenDocument(data, FormatJSON); err != nil {
+		return
+	}
+
+	s.etag = res.Header.Get("ETag")
+	s.expires = cacheExpiry(res.Header)
+	s.values = document
+
+	return
+}
+
+// cacheExpiry returns the time until which a response is fresh, as told by the max-age directive of its Cache-Control
+// header; the response is stale right away if there is no such directive, or if no-cache is set.
+func cacheExpiry(header http.Header) time.Time {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return time.Time{}
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	if maxAge == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(maxAge)
+}
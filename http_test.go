@@ -0,0 +1,121 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHTTPSource(t *testing.T) {
+	var m sync.Mutex
+	document := `{"level": "info", "db": {"host": "localhost"}}`
+	version, downloads, requests := 1, 0, 0
+	cacheControl := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+
+		requests++
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Signed") != "yes" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		etag := `"` + strconv.Itoa(version) + `"`
+		w.Header().Set("ETag", etag)
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		downloads++
+		_, _ = w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	cfg := &struct {
+		Level string `sky:"level,refresh:1m"`
+		DB    struct {
+			Host string `sky:"host"`
+		} `sky:"db"`
+	}{}
+
+	source := HTTPSource(server.URL, "config-api",
+		WithHTTPClient(server.Client()),
+		WithHeader("Authorization", "Bearer token"),
+		WithRequestEditor(func(req *http.Request) error {
+			req.Header.Set("X-Signed", "yes")
+			return nil
+		}))
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+
+	// The document is revalidated using its ETag
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 1, downloads)
+
+	m.Lock()
+	document = `{"level": "debug"}`
+	version++
+	cacheControl = "max-age=3600"
+	m.Unlock()
+
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, 2, downloads)
+
+	// The document is not requested again while it is fresh
+	before := requests
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, before, requests)
+
+	// Errors are reported
+	_, err = HTTPSource(server.URL, "unauthorized", WithHTTPClient(server.Client())).
+		Source(context.Background(), []string{"level"})
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestHTTPSourceRequestTimeout(t *testing.T) {
+	// The body is streamed after the headers are sent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"level": "info"}`))
+	}))
+	defer server.Close()
+
+	source := HTTPSource(server.URL, "config-api",
+		WithHTTPClient(server.Client()),
+		WithHTTPSourceOptions(WithRequestTimeout(time.Minute)))
+
+	values, err := source.Source(context.Background(), []string{"level"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"level": "info"}, values)
+	}
+}
+
+func Test_cacheExpiry(t *testing.T) {
+	header := func(cacheControl string) http.Header {
+		return http.Header{"Cache-Control": []string{cacheControl}}
+	}
+
+	assert.True(t, cacheExpiry(http.Header{}).IsZero())
+	assert.True(t, cacheExpiry(header("no-cache, max-age=60")).IsZero())
+	assert.True(t, cacheExpiry(header("max-age=abc")).IsZero())
+	assert.WithinDuration(t, time.Now().Add(time.Minute), cacheExpiry(header("public, max-age=60")), time.Second)
+}
@@ -0,0 +1,192 @@
+package skyconf
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IaCFormat is the format of the infrastructure as code generated by GenerateIaC.
+type IaCFormat int
+
+const (
+	// IaCTerraform generates an aws_ssm_parameter Terraform resource per field.
+	IaCTerraform IaCFormat = iota
+	// IaCCloudFormation generates the Resources section of a CloudFormation template in YAML, with an
+	// AWS::SSM::Parameter resource per field.
+	IaCCloudFormation
+)
+
+// ErrUnknownIaCFormat is returned when an infrastructure as code format is not known.
+var ErrUnknownIaCFormat = errors.New("unknown infrastructure as code format")
+
+// iacPlaceholder is the value of the parameters with no default value, and of the secrets.
+const iacPlaceholder = "CHANGE_ME"
+
+// iacParameter is a parameter to create for a field.
+type iacParameter struct {
+	field fieldInfo
+	name  string
+}
+
+// GenerateIaC returns the infrastructure as code creating the parameters of the tagged fields of the configuration
+// struct, named as in the source the field is fetched from; the source named by the `source` tag, or the first source.
+// The value of a parameter is the default value of its field, or a placeholder to replace if there is none, and its
+// description that given by the `desc` or `skydoc` tags. Fields tagged with `secret` are created as SecureString
+// parameters with a placeholder value that Terraform is told to ignore changes to, so the actual secret can be set
+// out-of-band. CloudFormation cannot create SecureString parameters, so they are only noted with a comment, as are
+// the fields populated from subtrees of parameters.
+func GenerateIaC(cfg interface{}, format IaCFormat, sources ...Source) (str string, err error) {
+	if len(sources) == 0 {
+		err = ErrNoSource
+		return
+	}
+
+	var fields []fieldInfo
+	fields, err = extractFields(false, nil, cfg, fieldOptions{})
+	if err != nil {
+		return
+	}
+
+	params := make([]iacParameter, 0, len(fields))
+	for _, field := range fields {
+		// The parameter is declared in the source the field prefers, if it lists any
+		source := sources[0]
+		if ids := field.options.sourceIDs(); len(ids) > 0 {
+			i := slices.IndexFunc(sources, func(s Source) bool { return s.ID() == ids[0] })
+			if i < 0 {
+				err = fmt.Errorf("'%s' : %w", ids[0], ErrSourceNotFound)
+				return
+			}
+			source = sources[i]
+		}
+
+		params = append(params, iacParameter{
+			field: field,
+			name:  field.name(source),
+		})
+	}
+
+	switch format {
+	case IaCTerraform:
+		str = terraformParameters(params)
+	case IaCCloudFormation:
+		str = cloudFormationParameters(params)
+	default:
+		err = fmt.Errorf("%w: %d", ErrUnknownIaCFormat, format)
+	}
+
+	return
+}
+
+// iacValue returns the value of the parameter of the field; its default value, unless the field is a secret or has no
+// default value.
+func (f fieldInfo) iacValue() string {
+	if f.options.secret || f.options.defaultValue == "" {
+		return iacPlaceholder
+	}
+
+	return f.options.defaultValue
+}
+
+// iacResourceName returns the name of the resource of the field, made of the words of the name of its parameter,
+// joined by the separator after applying the case function to each.
+func (f fieldInfo) iacResourceName(sep string, word func(string) string) string {
+	var words []string
+	for _, part := range f.templateKey() {
+		words = append(words, strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	for i := range words {
+		words[i] = word(words[i])
+	}
+
+	name := strings.Join(words, sep)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "P" + sep + name
+	}
+
+	return name
+}
+
+// terraformString quotes the string for Terraform, escaping the template sequences.
+func terraformString(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+
+	return strconv.Quote(s)
+}
+
+func terraformParameters(params []iacParameter) string {
+	var sb strings.Builder
+	for i, p := range params {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		if p.field.subtree {
+			sb.WriteString("# " + p.field.options.id + ": subtree of parameters under " + p.name + "\n")
+			continue
+		}
+
+		typ := "String"
+		if p.field.options.secret {
+			typ = "SecureString"
+		}
+
+		sb.WriteString(`resource "aws_ssm_parameter" "` + p.field.iacResourceName("_", strings.ToLower) + "\" {\n")
+		sb.WriteString("  name  = " + terraformString(p.name) + "\n")
+		sb.WriteString("  type  = " + strconv.Quote(typ) + "\n")
+		sb.WriteString("  value = " + terraformString(p.field.iacValue()) + "\n")
+		if desc := p.field.options.description(); desc != "" {
+			sb.WriteString("\n  description = " + terraformString(desc) + "\n")
+		}
+		if p.field.options.secret {
+			sb.WriteString("\n  lifecycle {\n    ignore_changes = [value]\n  }\n")
+		}
+		sb.WriteString("}\n")
+	}
+
+	return sb.String()
+}
+
+func cloudFormationParameters(params []iacParameter) string {
+	var sb strings.Builder
+	sb.WriteString("Resources:\n")
+	for _, p := range params {
+		switch {
+		case p.field.subtree:
+			sb.WriteString("  # " + p.field.options.id + ": subtree of parameters under " + p.name + "\n")
+			continue
+		case p.field.options.secret:
+			sb.WriteString("  # " + p.field.options.id + ": SecureString parameter " + p.name +
+				" cannot be created by CloudFormation\n")
+			continue
+		}
+
+		sb.WriteString("  " + p.field.iacResourceName("", title) + ":\n")
+		sb.WriteString("    Type: AWS::SSM::Parameter\n")
+		sb.WriteString("    Properties:\n")
+		sb.WriteString("      Name: " + strconv.Quote(p.name) + "\n")
+		sb.WriteString("      Type: String\n")
+		sb.WriteString("      Value: " + strconv.Quote(p.field.iacValue()) + "\n")
+		if desc := p.field.options.description(); desc != "" {
+			sb.WriteString("      Description: " + strconv.Quote(desc) + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// title returns the word with its first letter in upper case, and the others in lower case.
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
@@ -0,0 +1,137 @@
+package skyconf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGenerateIaC(t *testing.T) {
+	type backend struct {
+		URL string `sky:"url"`
+	}
+
+	cfg := &struct {
+		Level string `sky:"level,default:info,desc:Log level."`
+		DB    struct {
+			Host     string `sky:"host,default:${HOST}"`
+			Password string `sky:"password,secret,desc:Password of the database user."`
+		} `sky:"db"`
+		Region   string             `sky:"region,source:global"`
+		Backends map[string]backend `sky:"backends"`
+		Untagged string
+	}{}
+
+	app := &mockSource{path: "/app/", id: "app"}
+	global := &mockSource{path: "/global/", id: "global"}
+
+	tests := []struct {
+		name    string
+		format  IaCFormat
+		sources []Source
+		wantStr string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "terraform",
+			format:  IaCTerraform,
+			sources: []Source{app, global},
+			wantStr: `resource "aws_ssm_parameter" "level" {
+  name  = "/app/level"
+  type  = "String"
+  value = "info"
+
+  description = "Log level."
+}
+
+resource "aws_ssm_parameter" "db_host" {
+  name  = "/app/db/host"
+  type  = "String"
+  value = "$${HOST}"
+}
+
+resource "aws_ssm_parameter" "db_password" {
+  name  = "/app/db/password"
+  type  = "SecureString"
+  value = "CHANGE_ME"
+
+  description = "Password of the database user."
+
+  lifecycle {
+    ignore_changes = [value]
+  }
+}
+
+resource "aws_ssm_parameter" "region" {
+  name  = "/global/region"
+  type  = "String"
+  value = "CHANGE_ME"
+}
+
+# backends: subtree of parameters under /app/backends
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "cloudformation",
+			format:  IaCCloudFormation,
+			sources: []Source{app, global},
+			wantStr: `Resources:
+  Level:
+    Type: AWS::SSM::Parameter
+    Properties:
+      Name: "/app/level"
+      Type: String
+      Value: "info"
+      Description: "Log level."
+  DbHost:
+    Type: AWS::SSM::Parameter
+    Properties:
+      Name: "/app/db/host"
+      Type: String
+      Value: "${HOST}"
+  # password: SecureString parameter /app/db/password cannot be created by CloudFormation
+  Region:
+    Type: AWS::SSM::Parameter
+    Properties:
+      Name: "/global/region"
+      Type: String
+      Value: "CHANGE_ME"
+  # backends: subtree of parameters under /app/backends
+`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown source",
+			format:  IaCTerraform,
+			sources: []Source{app},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrSourceNotFound, i...)
+			},
+		},
+		{
+			name:    "unknown format",
+			format:  IaCFormat(42),
+			sources: []Source{app, global},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrUnknownIaCFormat, i...)
+			},
+		},
+		{
+			name:   "no sources",
+			format: IaCTerraform,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNoSource, i...)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			str, err := GenerateIaC(cfg, tt.format, tt.sources...)
+			if !tt.wantErr(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStr, str)
+		})
+	}
+}
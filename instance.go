@@ -0,0 +1,90 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// InstanceIDEnv is the environment variable naming the instance for WithInstanceOverrides, taking precedence over the
+// instance ID found in the EC2 instance metadata and the hostname.
+const InstanceIDEnv = "SKYCONF_INSTANCE_ID"
+
+// imdsTimeout bounds the lookup of the instance ID in the EC2 instance metadata, which is not reachable outside EC2.
+const imdsTimeout = time.Second
+
+// WithInstanceOverrides appends the source returned by override for the ID of the instance to the sources, so that its
+// parameters take precedence over those of all the other sources for the fields that do not specify a source. This
+// allows the configuration of a single instance to be overridden, such as to debug it, without code changes.
+//
+// The ID of the instance is taken from the SKYCONF_INSTANCE_ID environment variable if set, then from the EC2 instance
+// metadata, unless disabled by AWS_EC2_METADATA_DISABLED, and then from the hostname.
+func WithInstanceOverrides(override func(instance string) Source) Option {
+	return func(o *options) {
+		o.instanceOverrides = override
+	}
+}
+
+// SSMInstanceOverrides returns a function creating the SSM source with the ID "instance" of the parameters under
+// <base>/overrides/<instance>/, for WithInstanceOverrides.
+func SSMInstanceOverrides(ssm *ssmpkg.Client, base string, opts ...SourceOption) func(instance string) Source {
+	return func(instance string) Source {
+		return SSMSourceWithOptions(ssm, strings.TrimSuffix(base, "/")+"/overrides/"+instance, "instance", opts...)
+	}
+}
+
+// appendInstanceOverrides appends the source of the overrides of the instance to the sources, if set.
+func (o *options) appendInstanceOverrides(ctx context.Context, sources []Source) ([]Source, error) {
+	if o.instanceOverrides == nil {
+		return sources, nil
+	}
+
+	instance, err := discoverInstanceID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the instance ID: %w", err)
+	}
+
+	override := o.instanceOverrides(instance)
+	o.logger.DebugContext(ctx, "applying instance overrides", "instance", instance, "source", override.ID())
+
+	return append(slices.Clip(sources), override), nil
+}
+
+// discoverInstanceID returns the ID of the instance from the environment, the EC2 instance metadata or the hostname.
+func discoverInstanceID(ctx context.Context) (string, error) {
+	if id := os.Getenv(InstanceIDEnv); id != "" {
+		return id, nil
+	}
+
+	if !strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		if id, err := imdsInstanceID(ctx); err == nil && id != "" {
+			return id, nil
+		}
+	}
+
+	return os.Hostname()
+}
+
+// imdsInstanceID returns the ID of the EC2 instance from its instance metadata.
+func imdsInstanceID(ctx context.Context) (id string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	client := imds.New(imds.Options{Retryer: aws.NopRetryer{}})
+	output, err := client.GetMetadata(ctx, &imds.GetMetadataInput{Path: "instance-id"})
+	if err != nil {
+		return
+	}
+	defer output.Content.Close()
+
+	b, err := io.ReadAll(output.Content)
+	id = strings.TrimSpace(string(b))
+	return
+}
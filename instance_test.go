@@ -0,0 +1,61 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestInstanceOverrides(t *testing.T) {
+	ps := mockParameterStore{
+		"/app/log_level":                  "info",
+		"/app/timeout":                    "5",
+		"/app/overrides/i-0abc/log_level": "debug",
+	}
+
+	type config struct {
+		LogLevel string `sky:"logLevel"`
+		Timeout  int    `sky:"timeout"`
+	}
+	overrides := func(instance string) Source {
+		return &mockSource{ps: ps, path: "/app/overrides/" + instance + "/", id: "instance"}
+	}
+
+	t.Run("from the environment", func(t *testing.T) {
+		t.Setenv(InstanceIDEnv, "i-0abc")
+
+		var cfg config
+		_, err := ParseWithOptions(context.Background(), &cfg, []Source{&mockSource{ps: ps, path: "/app/"}},
+			WithInstanceOverrides(overrides))
+		assert.NoError(t, err)
+		assert.Equal(t, "debug", cfg.LogLevel)
+		assert.Equal(t, 5, cfg.Timeout)
+	})
+
+	t.Run("from the hostname", func(t *testing.T) {
+		t.Setenv(InstanceIDEnv, "")
+		t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+		hostname, err := os.Hostname()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var instance string
+		var cfg config
+		_, err = ParseWithOptions(context.Background(), &cfg, []Source{&mockSource{ps: ps, path: "/app/"}},
+			WithInstanceOverrides(func(i string) Source {
+				instance = i
+				return overrides(i)
+			}))
+		assert.NoError(t, err)
+		assert.Equal(t, hostname, instance)
+		assert.Equal(t, "info", cfg.LogLevel)
+	})
+
+	t.Run("ssm", func(t *testing.T) {
+		source := SSMInstanceOverrides(nil, "/app/")("i-0abc")
+		assert.Equal(t, "instance", source.ID())
+		assert.Equal(t, "/app/overrides/i-0abc/log_level", source.ParameterName([]string{"logLevel"}))
+	})
+}
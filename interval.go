@@ -0,0 +1,126 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrRefreshInterval is returned by Parse when the refresh interval of a field is not positive, or is shorter than the
+// minimum set with WithMinRefreshInterval.
+var ErrRefreshInterval = errors.New("invalid refresh interval")
+
+// WithMinRefreshInterval makes Parse fail with ErrRefreshInterval if a field is refreshed more often than every d,
+// protecting the quotas of the sources from overly eager `refresh` tags.
+func WithMinRefreshInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.minRefresh = d
+	}
+}
+
+// WithCoalescedRefresh refreshes fields whose refresh intervals differ by at most tolerance together, at the shortest
+// of their intervals, so that they share a ticker and their parameters are fetched in a single request per source.
+func WithCoalescedRefresh(tolerance time.Duration) Option {
+	return func(o *options) {
+		o.coalesce = tolerance
+	}
+}
+
+// WithBatchedRefresh refreshes together the fields of the refresh intervals whose tickers fire within window of one
+// another, such as fields refreshed every minute and every five minutes, every five minutes, so that the parameters of
+// each source are fetched in a single request rather than one per interval. The refresh of the first interval to fire
+// is delayed by up to window, waiting for the others.
+func WithBatchedRefresh(window time.Duration) Option {
+	return func(o *options) {
+		o.batchWindow = window
+	}
+}
+
+// checkRefreshIntervals returns an error for the first refreshed field whose refresh interval is negative or is shorter
+// than the minimum.
+func (o *options) checkRefreshIntervals(fields []fieldInfo) error {
+	for _, field := range fields {
+		d := field.options.refresh
+		switch {
+		case d == 0:
+			continue
+		case d < 0:
+			return fmt.Errorf("%w: field %s refreshes every %s", ErrRefreshInterval, field.path, d)
+		case d < o.minRefresh:
+			return fmt.Errorf("%w: field %s refreshes every %s, more often than every %s", ErrRefreshInterval,
+				field.path, d, o.minRefresh)
+		}
+	}
+
+	return nil
+}
+
+// coalesceIntervals maps each of the intervals to the interval it is refreshed at: the shortest of the intervals
+// within the tolerance of the coalescing option after it, in ascending order.
+func (o *options) coalesceIntervals(intervals []time.Duration) map[time.Duration]time.Duration {
+	slices.Sort(intervals)
+
+	coalesced := make(map[time.Duration]time.Duration, len(intervals))
+	var base time.Duration
+	for i, d := range intervals {
+		if i == 0 || d-base > o.coalesce {
+			base = d
+		}
+		coalesced[d] = base
+	}
+
+	return coalesced
+}
+
+// batchTicks groups by source the fields of the interval that ticked, along with those of the other intervals ticking
+// within the batching window set with WithBatchedRefresh, if any.
+func (u *updater) batchTicks(ctx context.Context, ticks <-chan (<-chan time.Time),
+	timings map[<-chan time.Time]map[Source]*refreshedFields,
+	ticked map[Source]*refreshedFields) (batch map[Source][]*refreshedFields) {
+
+	batch = make(map[Source][]*refreshedFields, len(ticked))
+	add := func(rf map[Source]*refreshedFields) {
+		for source, fields := range rf {
+			if !slices.Contains(batch[source], fields) {
+				batch[source] = append(batch[source], fields)
+			}
+		}
+	}
+	add(ticked)
+
+	if u.opts.batchWindow <= 0 {
+		return
+	}
+
+	window := time.NewTimer(u.opts.batchWindow)
+	defer window.Stop()
+	for {
+		select {
+		case tc := <-ticks:
+			add(timings[tc])
+		case <-window.C:
+			return
+		case <-ctx.Done():
+			return
+		case <-u.stop:
+			return
+		}
+	}
+}
+
+// mergeRefreshedFields returns the groups of fields as one, so that their parameters are fetched together.
+func mergeRefreshedFields(groups []*refreshedFields) *refreshedFields {
+	if len(groups) == 1 {
+		return groups[0]
+	}
+
+	merged := &refreshedFields{}
+	for _, rf := range groups {
+		merged.fields = append(merged.fields, rf.fields...)
+		merged.keys = append(merged.keys, rf.keys...)
+	}
+
+	return merged
+}
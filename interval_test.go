@@ -0,0 +1,156 @@
+package skyconf
+
+import (
+	"code.cloudfoundry.org/clock/fakeclock"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRefreshIntervals(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/a": "a",
+			"/path/b": "b",
+			"/path/c": "c",
+			"/path/d": "d",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	type config struct {
+		A string `sky:"a,refresh:10s"`
+		B string `sky:"b,refresh:10500ms"`
+		C string `sky:"c,refresh:11200ms"`
+		D string `sky:"d,refresh:30s"`
+	}
+
+	t.Run("not positive", func(t *testing.T) {
+		_, err := Parse(context.Background(), &struct {
+			A string `sky:"a,refresh:-1s"`
+		}{}, false, source)
+		assert.ErrorIs(t, err, ErrBadTags)
+		assert.ErrorIs(t, err, ErrRefreshInterval)
+
+		_, err = Parse(context.Background(), &struct {
+			A string `sky:"a,refresh:0s"`
+		}{}, false, source)
+		assert.ErrorIs(t, err, ErrRefreshInterval)
+	})
+
+	t.Run("minimum", func(t *testing.T) {
+		_, err := ParseWithOptions(context.Background(), &config{}, []Source{source},
+			WithMinRefreshInterval(15*time.Second))
+		assert.ErrorIs(t, err, ErrRefreshInterval)
+		assert.ErrorContains(t, err, "field A refreshes every 10s")
+
+		_, err = ParseWithOptions(context.Background(), &config{}, []Source{source},
+			WithMinRefreshInterval(5*time.Second))
+		assert.NoError(t, err)
+	})
+
+	t.Run("coalesced", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			opts      []Option
+			wantTimes []time.Duration
+		}{
+			{
+				name:      "not coalesced",
+				wantTimes: []time.Duration{10 * time.Second, 10500 * time.Millisecond, 11200 * time.Millisecond, 30 * time.Second},
+			},
+			{
+				name:      "within a second",
+				opts:      []Option{WithCoalescedRefresh(time.Second)},
+				wantTimes: []time.Duration{10 * time.Second, 11200 * time.Millisecond, 30 * time.Second},
+			},
+			{
+				name:      "within two seconds",
+				opts:      []Option{WithCoalescedRefresh(2 * time.Second)},
+				wantTimes: []time.Duration{10 * time.Second, 30 * time.Second},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r, err := ParseWithOptions(context.Background(), &config{}, []Source{source}, tt.opts...)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				u := r.(*updater)
+				u.processTimings()
+				var times []time.Duration
+				for d := range u.timings {
+					times = append(times, d)
+				}
+				slices.Sort(times)
+				assert.Equal(t, tt.wantTimes, times)
+			})
+		}
+	})
+	t.Run("batched", func(t *testing.T) {
+		for _, tt := range []struct {
+			name      string
+			opts      []Option
+			wantCalls int
+		}{
+			{name: "not batched", wantCalls: 2},
+			{name: "within the window", opts: []Option{WithBatchedRefresh(50 * time.Millisecond)}, wantCalls: 1},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				fetches := &recordingSource{mockSource: source, fetches: make(chan []string, 10)}
+				cfg := &struct {
+					A string `sky:"a,refresh:1s"`
+					B string `sky:"b,refresh:2s"`
+				}{}
+				r, err := ParseWithOptions(context.Background(), cfg, []Source{fetches},
+					append(tt.opts, WithJitter(0))...)
+				if !assert.NoError(t, err) {
+					return
+				}
+				<-fetches.fetches
+
+				clock := fakeclock.NewFakeClock(time.Now())
+				r.(*updater).clock = newJitterTickerClock(clock, 0, false)
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				r.Refresh(ctx, nil)
+
+				// Both intervals fire in the same cycle
+				clock.WaitForNWatchersAndIncrement(2*time.Second, 2)
+				var keys []string
+				for range tt.wantCalls {
+					select {
+					case fetched := <-fetches.fetches:
+						keys = append(keys, fetched...)
+					case <-time.After(time.Second):
+						assert.Fail(t, "timed out waiting for the refresh")
+						return
+					}
+				}
+				slices.Sort(keys)
+				assert.Equal(t, []string{"/path/a", "/path/b"}, keys)
+
+				select {
+				case fetched := <-fetches.fetches:
+					assert.Fail(t, "unexpected fetch", fetched)
+				case <-time.After(100 * time.Millisecond):
+				}
+			})
+		}
+	})
+}
+
+// recordingSource records the keys of each fetch.
+type recordingSource struct {
+	*mockSource
+	fetches chan []string
+}
+
+func (r *recordingSource) Source(ctx context.Context, params []string) (map[string]string, error) {
+	r.fetches <- params
+	return r.mockSource.Source(ctx, params)
+}
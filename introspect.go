@@ -0,0 +1,131 @@
+package skyconf
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Field describes a field of a configuration struct, as Parse sees it: its path in the struct, the key of its
+// parameter, and the options set by its tags. It lets tooling, such as validators, documentation generators and test
+// fixtures, rely on the semantics of the tags rather than parse them again.
+type Field struct {
+	// Path is the name of the field, qualified with those of the enclosing structs, as in "DB.Port".
+	Path string
+	// ID is the identifier of the field, set by the `id` tag; the last part of its key by default.
+	ID string
+	// Key is the parts of the key of the parameter of the field, including the prefix set with WithPrefix, which
+	// sources join into the name of the parameter; see Parameters.
+	Key []string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Subtree is true if the field is a map of structs, populated from the subtree of parameters under its key.
+	Subtree bool
+	// Options are the options set by the tags of the field; its ID is that of the field.
+	Options FieldOptions
+
+	field fieldInfo
+}
+
+// FieldOptions are the options set by the `sky` tag of a field; see Parse.
+type FieldOptions struct {
+	// ID is the identifier of the field, set by the `id` tag.
+	ID string
+	// Default is the default value of the field, set by the `default` tag.
+	Default string
+	// DefaultFrom is the ID of the field the default value is taken from, set by the `defaultfrom` tag.
+	DefaultFrom string
+	// Optional is true if the field is tagged with `optional`.
+	Optional bool
+	// Flatten is true if the field is tagged with `flatten` or `squash`.
+	Flatten bool
+	// Secret is true if the field is tagged with `secret`.
+	Secret bool
+	// Manual is true if the field is tagged with `manual`.
+	Manual bool
+	// AllowEmpty is true if the field is tagged with `allowempty`.
+	AllowEmpty bool
+	// Source is the ID of the source the field is taken from, set by the `source` tag, or the IDs of the sources in order
+	// of precedence, separated by '|'; empty for any source.
+	Source string
+	// Refresh is the refresh interval of the field, set by the `refresh` tag; 0 if it is not refreshed periodically.
+	Refresh time.Duration
+	// Description is the description of the field, set by the `desc` or `skydoc` tags.
+	Description string
+	// Absolute is the name of the parameter of the field, if its key is absolute.
+	Absolute string
+	// Aliases are the alternate keys of the parameter, set by the `alias` tag.
+	Aliases []string
+	// Deprecated is the deprecation message of the parameter, if tagged with `deprecated`.
+	Deprecated string
+	// OnDelete is what to do when the parameter is deleted from the source, set by the `ondelete` tag.
+	OnDelete string
+	// Transform are the names of the transformers applied to the value, set by the `transform` tag.
+	Transform []string
+	// Encoding is the encoding of the value of a byte slice or array, set by the `encoding` tag.
+	Encoding string
+	// Selector is the version or the label of the parameter to fetch, set by the `version` or `label` tags.
+	Selector string
+	// When is the condition on the value of another field for the field to be fetched, set by the `when` tag.
+	When string
+	// Profiles are the profiles the field is part of, or excluded from if prefixed by '!', set by the `profiles` tag.
+	Profiles []string
+}
+
+// Fields returns the fields of the configuration struct that Parse would populate, in the same order, as configured by
+// the options: WithUntagged, WithPrefix, WithMaxDepth, WithSkipUnsupported, WithSkippedFields and WithProfiles apply.
+// The configuration struct is not modified, other than pointers to structs being initialised unless WithLazyPointers is
+// set.
+func Fields(cfg interface{}, opts ...Option) (fields []Field, err error) {
+	var infos []fieldInfo
+	if infos, err = makeOptions(opts).extractFields(cfg); err != nil {
+		return
+	}
+
+	fields = make([]Field, len(infos))
+	for i, f := range infos {
+		fields[i] = Field{
+			Path:    f.path,
+			ID:      f.options.id,
+			Key:     slices.Clone(f.nameParts),
+			Type:    f.structField.Type(),
+			Subtree: f.subtree,
+			Options: f.options.export(),
+			field:   f,
+		}
+	}
+
+	return
+}
+
+// Parameters returns the names of the parameter of the field in the source, as Parse looks them up: the name of its
+// key, followed by those of its aliases, including the version or label selector of the parameter, if any.
+func (f Field) Parameters(source Source) []string {
+	return append([]string{f.field.parameterName(source)}, f.field.aliasNames(source)...)
+}
+
+// export returns the options as exported by Fields and ParseTag.
+func (o fieldOptions) export() FieldOptions {
+	return FieldOptions{
+		ID:          o.id,
+		Default:     o.defaultValue,
+		DefaultFrom: o.defaultFrom,
+		Optional:    o.optional,
+		Flatten:     o.flatten,
+		Secret:      o.secret,
+		Manual:      o.manual,
+		AllowEmpty:  o.allowEmpty,
+		Source:      o.source,
+		Refresh:     o.refresh,
+		Description: o.doc,
+		Absolute:    o.absolute,
+		Aliases:     slices.Clone(o.aliases),
+		Deprecated:  o.deprecated,
+		OnDelete:    o.onDelete,
+		Transform:   slices.Clone(o.transform),
+		Encoding:    o.encoding,
+		Selector:    o.selector,
+		When:        o.when,
+		Profiles:    o.profiles,
+	}
+}
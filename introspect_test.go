@@ -0,0 +1,71 @@
+package skyconf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFields(t *testing.T) {
+	type db struct {
+		Host string `sky:"host,alias:hostname|/legacy/db_host,desc:Database host"`
+		Port int    `sky:"port,default:5432,refresh:1m"`
+	}
+
+	var cfg struct {
+		DB       *db           `sky:"db"`
+		Password string        `sky:"password,secret,id:db_password,source:secrets,label:current"`
+		Region   string        `sky:"/shared/region,optional"`
+		Tenants  map[string]db `sky:"tenants"`
+		Untagged string
+		Labels   map[string]string `sky:"labels,transform:base64|gzip,ondelete:keep"`
+	}
+
+	fields, err := Fields(&cfg, WithPrefix("app"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, cfg.DB)
+
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"DB.Host", "DB.Port", "Password", "Region", "Tenants", "Labels"}, paths)
+
+	assert.Equal(t, Field{
+		Path: "DB.Host",
+		ID:   "host",
+		Key:  []string{"app", "db", "host"},
+		Type: reflect.TypeOf(""),
+		Options: FieldOptions{
+			ID:          "host",
+			Aliases:     []string{"hostname", "/legacy/db_host"},
+			Description: "Database host",
+		},
+		field: fields[0].field,
+	}, fields[0])
+	assert.Equal(t, FieldOptions{ID: "port", Default: "5432", Refresh: time.Minute}, fields[1].Options)
+	assert.Equal(t, FieldOptions{ID: "db_password", Secret: true, Source: "secrets", Selector: "current"}, fields[2].Options)
+	assert.Equal(t, "db_password", fields[2].ID)
+	assert.Equal(t, FieldOptions{ID: "/shared/region", Optional: true, Absolute: "/shared/region"}, fields[3].Options)
+	assert.True(t, fields[4].Subtree)
+	assert.Equal(t, []string{"base64", "gzip"}, fields[5].Options.Transform)
+	assert.Equal(t, "keep", fields[5].Options.OnDelete)
+
+	source := &mockSource{path: "/path/"}
+	assert.Equal(t, []string{"/path/app/db/host", "/path/app/db/hostname", "/legacy/db_host"},
+		fields[0].Parameters(source))
+	assert.Equal(t, []string{"/path/app/password:current"}, fields[2].Parameters(source))
+	assert.Equal(t, []string{"/shared/region"}, fields[3].Parameters(source))
+
+	// The untagged fields are included with WithUntagged
+	fields, err = Fields(&cfg, WithUntagged())
+	if assert.NoError(t, err) {
+		assert.Len(t, fields, 7)
+	}
+
+	_, err = Fields(cfg)
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+}
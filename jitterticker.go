@@ -2,19 +2,50 @@ package skyconf
 
 import (
 	cfclock "code.cloudfoundry.org/clock"
+	"math/rand/v2"
+	"sync"
 	"time"
 )
 
-// newJitterTickerClock returns a new jitterTickerClock, which is a clock that adds a random jitter to the ticker to
-// prevent thundering herd. The jitter is at most 1/10th of the duration.
-func newJitterTickerClock() cfclock.Clock {
+// defaultJitter is the fraction of the refresh interval by which the ticks are delayed at most, unless set with
+// WithJitter.
+const defaultJitter = 0.1
+
+// WithJitter delays each refresh tick by a random duration of at most the given fraction of the refresh interval, drawn
+// anew for every tick, so that fleets of instances started together do not poll the sources in lockstep. The fraction
+// is 0.1 by default; 0 disables the jitter, and fractions above 1 are treated as 1.
+func WithJitter(fraction float64) Option {
+	return func(o *options) {
+		o.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithAlignedRefresh aligns the refresh ticks to the wall clock: a field refreshed every minute is refreshed on the
+// minute, every hour on the hour, and so on, with intervals measured from midnight UTC. The jitter, if any, is added to
+// each tick; see WithJitter. This picks up changes deployed on a schedule in the same refresh cycle for all fields.
+func WithAlignedRefresh() Option {
+	return func(o *options) {
+		o.aligned = true
+	}
+}
+
+// newJitterTickerClock returns a new jitterTickerClock, which is a clock that adds a random jitter to each tick of its
+// tickers to prevent thundering herd. The jitter is at most the given fraction of the duration. If aligned, the ticks
+// fall on the multiples of the duration, before the jitter is added.
+func newJitterTickerClock(clock cfclock.Clock, fraction float64, aligned bool) cfclock.Clock {
 	return &jitterTickerClock{
-		clock: cfclock.NewClock(),
+		clock:    clock,
+		fraction: fraction,
+		aligned:  aligned,
+		rand:     rand.Int64N,
 	}
 }
 
 type jitterTickerClock struct {
-	clock cfclock.Clock
+	clock    cfclock.Clock
+	fraction float64
+	aligned  bool
+	rand     func(n int64) int64 // returns a random number in [0, n)
 }
 
 func (j *jitterTickerClock) Now() time.Time {
@@ -38,7 +69,68 @@ func (j *jitterTickerClock) NewTimer(d time.Duration) cfclock.Timer {
 }
 
 func (j *jitterTickerClock) NewTicker(d time.Duration) cfclock.Ticker {
-	// Add a random jitter to the ticker to prevent thundering herd, with a max jitter of 1/10th of the duration
-	jitter := time.Duration(j.clock.Now().UnixNano() % int64(d/10))
-	return j.clock.NewTicker(d + jitter)
+	if j.fraction == 0 && !j.aligned {
+		return j.clock.NewTicker(d)
+	}
+
+	t := &jitterTicker{
+		c:    make(chan time.Time, 1),
+		stop: make(chan struct{}),
+	}
+
+	timer := j.clock.NewTimer(j.wait(d, j.clock.Now()))
+	go func() {
+		defer timer.Stop()
+		for {
+			select {
+			case now := <-timer.C():
+				// Drop the tick if the previous one has not been received yet, as time.Ticker does
+				select {
+				case t.c <- now:
+				default:
+				}
+				timer.Reset(j.wait(d, j.clock.Now()))
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+
+	return t
+}
+
+// wait returns the duration from now until the next tick of a ticker ticking every d.
+func (j *jitterTickerClock) wait(d time.Duration, now time.Time) time.Duration {
+	if j.aligned {
+		return now.Truncate(d).Add(d).Sub(now) + j.jitter(d)
+	}
+
+	return d + j.jitter(d)
+}
+
+// jitter returns a random duration of at most the fraction of d.
+func (j *jitterTickerClock) jitter(d time.Duration) time.Duration {
+	n := int64(float64(d) * j.fraction)
+	if n <= 0 {
+		return 0
+	}
+
+	return time.Duration(j.rand(n + 1))
+}
+
+// jitterTicker is a ticker whose ticks are each delayed by a random jitter; see jitterTickerClock.
+type jitterTicker struct {
+	c    chan time.Time
+	stop chan struct{}
+	once sync.Once
+}
+
+func (t *jitterTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *jitterTicker) Stop() {
+	t.once.Do(func() {
+		close(t.stop)
+	})
 }
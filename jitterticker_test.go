@@ -0,0 +1,80 @@
+package skyconf
+
+import (
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestJitterTicker(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+
+	// Each tick is delayed by a new jitter of at most half the interval
+	jitters := []time.Duration{2 * time.Second, 4 * time.Second, 0}
+	j := newJitterTickerClock(clock, 0.5, false).(*jitterTickerClock)
+	j.rand = func(n int64) int64 {
+		assert.Equal(t, int64(5*time.Second+1), n)
+		d := jitters[0]
+		jitters = jitters[1:]
+		return int64(d)
+	}
+
+	ticker := j.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for _, d := range []time.Duration{12 * time.Second, 14 * time.Second} {
+		assert.Eventually(t, func() bool { return clock.WatcherCount() == 1 }, time.Second, time.Millisecond)
+		clock.Increment(d - time.Millisecond)
+		select {
+		case <-ticker.C():
+			assert.Fail(t, "ticked before the jitter elapsed")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		clock.Increment(time.Millisecond)
+		select {
+		case <-ticker.C():
+		case <-time.After(time.Second):
+			assert.Fail(t, "did not tick after the jitter elapsed")
+		}
+	}
+
+	// Without jitter, the ticks are not delayed
+	ticker = newJitterTickerClock(clock, 0, false).NewTicker(time.Second)
+	defer ticker.Stop()
+	_, jittered := ticker.(*jitterTicker)
+	assert.False(t, jittered)
+
+	assert.Equal(t, defaultJitter, makeOptions(nil).jitter)
+	assert.Equal(t, 1.0, makeOptions([]Option{WithJitter(2)}).jitter)
+	assert.Equal(t, 0.0, makeOptions([]Option{WithJitter(-1)}).jitter)
+}
+
+func TestAlignedTicker(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Date(2024, 5, 1, 12, 0, 25, 0, time.UTC))
+
+	j := newJitterTickerClock(clock, 0.1, true).(*jitterTickerClock)
+	j.rand = func(n int64) int64 {
+		return int64(3 * time.Second)
+	}
+
+	ticker := j.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	// The ticks fall on the minute, plus the jitter
+	for _, want := range []time.Time{
+		time.Date(2024, 5, 1, 12, 1, 3, 0, time.UTC),
+		time.Date(2024, 5, 1, 12, 2, 3, 0, time.UTC),
+	} {
+		assert.Eventually(t, func() bool { return clock.WatcherCount() == 1 }, time.Second, time.Millisecond)
+		clock.Increment(want.Sub(clock.Now()))
+
+		select {
+		case tick := <-ticker.C():
+			assert.Equal(t, want, tick.UTC())
+		case <-time.After(time.Second):
+			assert.Fail(t, "did not tick")
+		}
+	}
+}
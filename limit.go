@@ -0,0 +1,167 @@
+package skyconf
+
+import (
+	"context"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"sync"
+	"time"
+)
+
+// SourceOption configures the behaviour of a source.
+type SourceOption func(o *sourceOptions)
+
+type sourceOptions struct {
+	requestTimeout        time.Duration
+	maxQPS                float64
+	maxConcurrency        int
+	detectChanges         bool
+	keyspaceNotifications bool
+	throttleRetries       int
+	verbatimKeys          bool
+	streaming             bool
+	partialResults        bool
+	negativeRecheck       time.Duration
+	batchSize             int
+	getParameterFallback  bool
+	clientOptions         []func(*ssmpkg.Options)
+}
+
+// WithRequestTimeout sets the maximum duration of each request made by a source. The timeout applies in addition to
+// any deadline set on the context passed to the source.
+func WithRequestTimeout(d time.Duration) SourceOption {
+	return func(o *sourceOptions) {
+		o.requestTimeout = d
+	}
+}
+
+// WithMaxQPS limits the number of requests per second made by a source.
+func WithMaxQPS(qps float64) SourceOption {
+	return func(o *sourceOptions) {
+		o.maxQPS = qps
+	}
+}
+
+// WithMaxConcurrency limits the number of requests a source can have in flight at any one time.
+func WithMaxConcurrency(n int) SourceOption {
+	return func(o *sourceOptions) {
+		o.maxConcurrency = n
+	}
+}
+
+func makeSourceOptions(opts []SourceOption) sourceOptions {
+	o := sourceOptions{throttleRetries: defaultThrottleRetries}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// limiter applies the request timeout, rate and concurrency limits of a source to the requests it makes, and slows
+// them down when the source throttles them.
+type limiter struct {
+	timeout    time.Duration
+	base       time.Duration // minimum interval between the start of two requests, set with WithMaxQPS
+	sem        chan struct{}
+	throttling func(err error) bool // returns true for the errors of throttled requests; nil if not detected
+	retries    int                  // the number of times a throttled request is retried
+
+	m        sync.Mutex
+	interval time.Duration // minimum interval between the start of two requests, longer than base when throttled
+	next     time.Time     // earliest time the next request may start
+}
+
+func newLimiter(o sourceOptions) *limiter {
+	l := &limiter{
+		timeout: o.requestTimeout,
+		retries: o.throttleRetries,
+	}
+
+	if o.maxQPS > 0 {
+		l.base = time.Duration(float64(time.Second) / o.maxQPS)
+		l.interval = l.base
+	}
+
+	if o.maxConcurrency > 0 {
+		l.sem = make(chan struct{}, o.maxConcurrency)
+	}
+
+	return l
+}
+
+// do runs fn once the rate and concurrency limits allow it, with a context bound by the request timeout. If fn is
+// throttled, the requests are slowed down and fn is retried, up to the number of throttle retries.
+func (l *limiter) do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	// Wait for a free slot if the concurrency is limited
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			defer func() { <-l.sem }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for retry := 0; ; retry++ {
+		// Wait for our turn if the rate is limited
+		if err = l.wait(ctx); err != nil {
+			return
+		}
+
+		err = l.call(ctx, fn)
+		if l.throttling == nil || !l.throttling(err) {
+			if err == nil {
+				l.succeeded()
+			}
+			return
+		}
+
+		reportThrottled(ctx, l.throttled(), err)
+		if retry >= l.retries {
+			return
+		}
+	}
+}
+
+// call runs fn with a context bound by the request timeout.
+func (l *limiter) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	return fn(ctx)
+}
+
+// wait blocks until the next request is allowed to start, or the context is done.
+func (l *limiter) wait(ctx context.Context) error {
+	// Reserve a slot for this request
+	l.m.Lock()
+	if l.interval == 0 {
+		l.m.Unlock()
+		return nil
+	}
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.m.Unlock()
+
+	d := at.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
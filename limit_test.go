@@ -0,0 +1,83 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("no limits", func(t *testing.T) {
+		l := newLimiter(makeSourceOptions(nil))
+		called := false
+		err := l.do(context.Background(), func(ctx context.Context) error {
+			called = true
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("request timeout", func(t *testing.T) {
+		l := newLimiter(makeSourceOptions([]SourceOption{WithRequestTimeout(10 * time.Millisecond)}))
+		err := l.do(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("max qps", func(t *testing.T) {
+		l := newLimiter(makeSourceOptions([]SourceOption{WithMaxQPS(100)}))
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, l.do(context.Background(), func(ctx context.Context) error { return nil }))
+		}
+
+		// The first request starts immediately, the following four are spaced 10ms apart
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("max qps honours context", func(t *testing.T) {
+		l := newLimiter(makeSourceOptions([]SourceOption{WithMaxQPS(1)}))
+		assert.NoError(t, l.do(context.Background(), func(ctx context.Context) error { return nil }))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := l.do(ctx, func(ctx context.Context) error { return nil })
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("max concurrency", func(t *testing.T) {
+		l := newLimiter(makeSourceOptions([]SourceOption{WithMaxConcurrency(2)}))
+
+		var inFlight, maxInFlight int32
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = l.do(context.Background(), func(ctx context.Context) error {
+					n := atomic.AddInt32(&inFlight, 1)
+					for {
+						m := atomic.LoadInt32(&maxInFlight)
+						if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					atomic.AddInt32(&inFlight, -1)
+					return nil
+				})
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, maxInFlight, int32(2))
+	})
+}
@@ -0,0 +1,38 @@
+package skyconf
+
+import (
+	"context"
+	"log/slog"
+)
+
+// redacted replaces the values of secret fields in logs.
+const redacted = "[REDACTED]"
+
+// WithLogger sets the logger used to log, at debug level, which parameters are fetched, which default values are
+// applied, and which fields are set or refreshed. The values of fields tagged with `secret` are redacted. Nothing is
+// logged unless a logger is provided.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// discardHandler is a slog.Handler that discards all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler { return d }
+
+func (d discardHandler) WithGroup(string) slog.Handler { return d }
+
+// logValue returns the value of the field as it should appear in logs.
+func (f fieldInfo) logValue(value string) string {
+	if f.options.secret {
+		return redacted
+	}
+
+	return value
+}
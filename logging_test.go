@@ -0,0 +1,46 @@
+package skyconf
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/username": "admin",
+			"/path/password": "hunter2",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Username string `sky:"username"`
+		Password string `sky:"password,secret,refresh:1m"`
+		Port     int    `sky:"port,default:5432"`
+	}{}
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithLogger(logger))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	source.set("/path/password", "correct-horse")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="applied default value" field=port value=5432`)
+	assert.Contains(t, out, `msg="fetched parameters" source=mock`)
+	assert.Contains(t, out, `msg="set field value" field=username source=mock parameter=/path/username value=admin`)
+	assert.Contains(t, out, `msg="set field value" field=password source=mock parameter=/path/password value=[REDACTED]`)
+	assert.Contains(t, out, `msg="refreshed field value" field=password source=mock parameter=/path/password value=[REDACTED]`)
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "correct-horse")
+}
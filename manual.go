@@ -0,0 +1,140 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// stagedValue is a change to the value of a field tagged with `manual`, found on refresh and awaiting approval.
+type stagedValue struct {
+	source   Source
+	key      string
+	value    string
+	metadata Metadata
+}
+
+// stage stages the change to the value of the field tagged with `manual`, replacing any change staged before, and
+// returns true; it returns false, discarding the staged change, if the value has not changed since the field was set,
+// such as when a change is reverted in the source before being approved.
+func (u *updater) stage(ctx context.Context, f *refreshedField, source Source, key, value string,
+	metadata Metadata) bool {
+
+	u.m.Lock()
+	if u.opts.hashValue(value) == f.valueHash {
+		f.staged = nil
+		u.m.Unlock()
+		return false
+	}
+
+	replaced := f.staged != nil
+	if replaced && f.staged.source == source && f.staged.key == key && f.staged.value == value {
+		f.staged.metadata = metadata
+		u.m.Unlock()
+		return true
+	}
+	f.staged = &stagedValue{source: source, key: key, value: value, metadata: metadata}
+	u.m.Unlock()
+
+	u.opts.logger.InfoContext(ctx, "staged field value awaiting approval", "field", f.field.options.id,
+		"source", source.ID(), "parameter", key, "value", f.field.logValue(value), "replaced", replaced)
+
+	return true
+}
+
+// Pending returns the changes staged for the fields tagged with `manual`, in the order of the fields.
+func (u *updater) Pending() (changes []PendingChange) {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	for _, f := range u.fields {
+		if f.staged == nil {
+			continue
+		}
+
+		f.rlocker.Lock()
+		current, err := formatFieldValue(f.field.structField)
+		f.rlocker.Unlock()
+		if err != nil {
+			current = err.Error()
+		}
+
+		changes = append(changes, PendingChange{
+			ID:        f.field.options.id,
+			Field:     f.field.path,
+			Source:    f.staged.source.ID(),
+			Parameter: f.staged.key,
+			Value:     f.field.logValue(f.staged.value),
+			Current:   f.field.logValue(current),
+			Paused:    f.paused,
+		})
+	}
+
+	return
+}
+
+// Apply applies the changes staged for the fields with the given IDs, or for all the fields if no IDs are given.
+// Changes that fail to be applied remain staged, while the others are applied; the errors of all those that fail are
+// returned joined.
+func (u *updater) Apply(ids ...string) (err error) {
+	ctx := context.Background()
+
+	// Ensure all the IDs are known before applying anything
+	u.m.Lock()
+	for _, id := range ids {
+		if !slices.ContainsFunc(u.fields, func(f *refreshedField) bool { return f.field.options.id == id }) {
+			u.m.Unlock()
+			return fmt.Errorf("%w: %s", ErrFieldNotFound, id)
+		}
+	}
+
+	staged := make([]*stagedValue, len(u.fields))
+	for i, f := range u.fields {
+		if len(ids) == 0 || slices.Contains(ids, f.field.options.id) {
+			staged[i] = f.staged
+		}
+	}
+	u.m.Unlock()
+
+	// Validate the changes, which may have been staged before the values of the other fields they are validated with
+	// changed
+	var candidates []candidate
+	for i, f := range u.fields {
+		if s := staged[i]; s != nil {
+			candidates = append(candidates, candidate{f: f, source: s.source, key: s.key, value: s.value})
+		}
+	}
+	rejected := u.validate(ctx, candidates)
+
+	// Apply the changes in the order of the fields, keeping those rejected by the validators or failing to be set
+	// staged, and going on with the others
+	var errs []error
+	for i, f := range u.fields {
+		s := staged[i]
+		if s == nil {
+			continue
+		}
+		if e := rejected[f]; e != nil {
+			errs = append(errs, e)
+			continue
+		}
+
+		u.opts.logger.InfoContext(ctx, "applying approved field value", "field", f.field.options.id,
+			"source", s.source.ID(), "parameter", s.key)
+		if _, e := u.set(ctx, f, s.source, s.key, s.value, s.metadata, true); e != nil {
+			errs = append(errs, f.field.valueError(StageRefresh, s.source, s.key, e))
+			continue
+		}
+
+		// Unstage the change, unless replaced by a refresh in the meantime
+		u.m.Lock()
+		if f.staged == s {
+			f.staged = nil
+		}
+		u.m.Unlock()
+	}
+
+	err = errors.Join(errs...)
+	return
+}
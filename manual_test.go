@@ -0,0 +1,73 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestManualApply(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{"/app/host": "db1", "/app/key": "key1", "/app/timeout": "30", "/app/port": "5432",
+			"/app/region": "eu"},
+		path:        "/app/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Host    string `sky:"host,refresh:1m,manual"`
+		Key     string `sky:"key,refresh:1m,manual,secret"`
+		Timeout int    `sky:"timeout,refresh:1m"`
+		Port    int    `sky:"port,refresh:1m,manual"`
+		Region  string `sky:"region,refresh:1m,manual"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "db1", cfg.Host)
+	assert.Empty(t, r.Pending())
+
+	// The changes to the fields tagged with `manual` are staged
+	source.set("/app/host", "db2")
+	source.set("/app/key", "key2")
+	source.set("/app/timeout", "60")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "db1", cfg.Host)
+	assert.Equal(t, "key1", cfg.Key)
+	assert.Equal(t, 60, cfg.Timeout)
+	assert.Equal(t, []PendingChange{
+		{ID: "host", Field: "Host", Source: "mock", Parameter: "/app/host", Value: "db2", Current: "db1"},
+		{ID: "key", Field: "Key", Source: "mock", Parameter: "/app/key", Value: redacted, Current: redacted},
+	}, r.Pending())
+
+	// A later change replaces the staged one
+	source.set("/app/host", "db3")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "db3", r.Pending()[0].Value)
+
+	assert.ErrorIs(t, r.Apply("unknown"), ErrFieldNotFound)
+	assert.NoError(t, r.Apply("host"))
+	assert.Equal(t, "db3", cfg.Host)
+	assert.Equal(t, "key1", cfg.Key)
+	assert.Len(t, r.Pending(), 1)
+
+	// A change reverted in the source before its approval is discarded
+	source.set("/app/key", "key1")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Empty(t, r.Pending())
+	assert.NoError(t, r.Apply())
+	assert.Equal(t, "key1", cfg.Key)
+
+	// Failing changes remain staged, while the others are applied
+	source.set("/app/port", "bad")
+	source.set("/app/region", "us")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.ErrorIs(t, r.Apply(), ErrBadFieldValue)
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, "us", cfg.Region)
+	if assert.Len(t, r.Pending(), 1) {
+		assert.Equal(t, "bad", r.Pending()[0].Value)
+	}
+}
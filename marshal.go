@@ -0,0 +1,108 @@
+package skyconf
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strings"
+)
+
+// MarshalConfig returns a document of the given format holding the current values of the fields of the configuration
+// struct, keyed by parameter name, such as for an admin endpoint. The parameters are named as by S3Source, their parts
+// nested in objects, so that the document can be loaded by S3Source. Values are marshalled by their own marshaler for
+// the format, or as text if they implement encoding.TextMarshaler or fmt.Stringer; if redactSecrets is true, the values
+// of fields tagged with `secret` are redacted. The fields are those returned by Fields with the same options, except the
+// fields of structs behind nil pointers, which are left out. The configuration struct is locked for reading while its
+// fields are read, if it implements RLocker or sync.Locker.
+func MarshalConfig(cfg interface{}, format DocumentFormat, redactSecrets bool, opts ...Option) (data []byte,
+	err error) {
+
+	if format != FormatJSON && format != FormatYAML {
+		err = fmt.Errorf("%w: %d", ErrUnknownDocumentFormat, format)
+		return
+	}
+
+	o := makeOptions(opts)
+	document := make(map[string]any)
+
+	l := readLock(cfg)
+	l.Lock()
+	defer l.Unlock()
+
+	var fields []fieldInfo
+	if fields, err = o.readFields(cfg); err != nil {
+		return
+	}
+
+	for _, field := range fields {
+		if field.unset() {
+			continue
+		}
+
+		var value any
+		if redactSecrets && field.options.secret {
+			value = redacted
+		} else if value, err = marshalValue(field.structField, format); err != nil {
+			err = fmt.Errorf("failed to format field %s: %w", field.path, err)
+			return
+		}
+
+		name := makeParameterName("", field.nameParts)
+		if err = setDocumentValue(document, strings.Split(name, "/"), value); err != nil {
+			return
+		}
+	}
+
+	if format == FormatJSON {
+		return json.MarshalIndent(document, "", "  ")
+	}
+
+	return yaml.Marshal(document)
+}
+
+// marshalValue returns the value of the field to marshal in the format; the field itself if it implements the
+// marshaler of the format or encoding.TextMarshaler, or is not a fmt.Stringer, and its string otherwise.
+func marshalValue(field reflect.Value, format DocumentFormat) (any, error) {
+	var marshals bool
+	switch format {
+	case FormatJSON:
+		marshals = interfaceFrom[json.Marshaler](field) != nil
+	case FormatYAML:
+		marshals = interfaceFrom[yaml.Marshaler](field) != nil
+	}
+
+	if marshals || textMarshaler(field) != nil || stringer(field) == nil {
+		return field.Interface(), nil
+	}
+
+	return formatFieldValue(field)
+}
+
+// setDocumentValue sets the value in the document, nesting it in an object for each of the parts of its name.
+func setDocumentValue(document map[string]any, parts []string, value any) error {
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := document[part]
+		if !ok {
+			next = make(map[string]any)
+			document[part] = next
+		}
+
+		object, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("parameter %s holds both a value and other parameters", strings.Join(parts[:i+1], "/"))
+		}
+		document = object
+	}
+
+	last := parts[len(parts)-1]
+	if existing, ok := document[last]; ok {
+		if _, ok = existing.(map[string]any); ok {
+			return fmt.Errorf("parameter %s holds both a value and other parameters", strings.Join(parts, "/"))
+		}
+		return fmt.Errorf("parameter %s is set by several fields", strings.Join(parts, "/"))
+	}
+	document[last] = value
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// level is a custom type marshalling itself to JSON.
+type level int
+
+func (l level) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"level-%d"`, l)), nil
+}
+
+func TestMarshalConfig(t *testing.T) {
+	type config struct {
+		DB struct {
+			Host     string `sky:"host"`
+			Port     int    `sky:"port"`
+			Password string `sky:"password,secret"`
+		} `sky:"db"`
+		Timeout  time.Duration `sky:"timeout"`
+		Addr     netip.Addr    `sky:"addr"`
+		Tags     []string      `sky:"tags"`
+		Level    level         `sky:"level"`
+		Disabled bool          `sky:"/shared/disabled"`
+	}
+
+	source := &mockSource{path: "/app/", ps: mockParameterStore{
+		"/app/db/host":     "db1",
+		"/app/db/port":     "5432",
+		"/app/db/password": "hunter2",
+		"/app/timeout":     "5s",
+		"/app/addr":        "10.0.0.1",
+		"/app/tags":        "a;b",
+		"/app/level":       "2",
+		"/shared/disabled": "true",
+	}}
+	cfg := &config{}
+	_, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := MarshalConfig(cfg, FormatJSON, true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"db": {"host": "db1", "port": 5432, "password": "[REDACTED]"},
+		"timeout": "5s",
+		"addr": "10.0.0.1",
+		"tags": ["a", "b"],
+		"level": "level-2",
+		"shared": {"disabled": true}
+	}`, string(data))
+
+	data, err = MarshalConfig(cfg, FormatYAML, false)
+	assert.NoError(t, err)
+	assert.YAMLEq(t, `
+db: {host: db1, port: 5432, password: hunter2}
+timeout: 5s
+addr: 10.0.0.1
+tags: [a, b]
+level: 2
+shared: {disabled: true}
+`, string(data))
+
+	// The document can be loaded as a source
+	values, err := flattenDocument(data, FormatYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, "db1", values["db/host"])
+
+	_, err = MarshalConfig(cfg, DocumentFormat(42), true)
+	assert.ErrorIs(t, err, ErrUnknownDocumentFormat)
+
+	_, err = MarshalConfig(&struct {
+		A string `sky:"a"`
+		B struct {
+			C string `sky:"c"`
+		} `sky:"a"`
+	}{}, FormatJSON, true)
+	assert.ErrorContains(t, err, "parameter a holds both a value and other parameters")
+}
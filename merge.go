@@ -0,0 +1,166 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type mergeSource struct {
+	sources    []Source
+	id         string
+	ssmLayered bool // created by SSMLayeredSource, its layers are SSM sources sharing a client; see RebindSSMClient
+}
+
+// MergeSource creates a new source that presents the provided sources as a single source with the ID "merge".
+func MergeSource(sources ...Source) Source {
+	return MergeSourceWithID("merge", sources...)
+}
+
+// MergeSourceWithID creates a new source that presents the provided sources as a single source with a custom ID.
+// The sources are queried in order, with the last source's value taking precedence; this mirrors the precedence of
+// the sources passed to Parse. The merged source is refreshable only if all the underlying sources are refreshable.
+func MergeSourceWithID(id string, sources ...Source) Source {
+	return &mergeSource{
+		sources: sources,
+		id:      id,
+	}
+}
+
+func (s *mergeSource) Source(ctx context.Context, keys []string) (values map[string]string, err error) {
+	values, _, err = s.SourceWithMetadata(ctx, keys)
+	return
+}
+
+// SourceWithMetadata fetches the parameters from the underlying sources, along with the metadata provided by the
+// source each value is taken from, if any.
+func (s *mergeSource) SourceWithMetadata(ctx context.Context, keys []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
+	// Ensure there are keys to fetch
+	if len(keys) == 0 {
+		return
+	}
+
+	if len(s.sources) == 0 {
+		err = ErrNoSource
+		return
+	}
+
+	// Resolve the parameter names of the underlying sources for each of the keys
+	names := make([][]string, len(keys))
+	for i, key := range keys {
+		if names[i], err = s.sourceNames(key); err != nil {
+			return
+		}
+	}
+
+	values = make(map[string]string, len(keys))
+	metadata = make(map[string]Metadata, len(keys))
+
+	// Query each source in order; values from later sources override values from earlier ones.
+	for sourceIdx, source := range s.sources {
+		sourceKeys := make([]string, len(keys))
+		for i := range keys {
+			sourceKeys[i] = names[i][sourceIdx]
+		}
+
+		var sourceValues map[string]string
+		var sourceMetadata map[string]Metadata
+		if ms, ok := source.(MetadataSource); ok {
+			sourceValues, sourceMetadata, err = ms.SourceWithMetadata(ctx, sourceKeys)
+		} else {
+			sourceValues, err = source.Source(ctx, sourceKeys)
+		}
+		if err != nil {
+			err = fmt.Errorf("%w from source '%s' : %w", ErrGetParameters, source.ID(), err)
+			return
+		}
+
+		for i, key := range keys {
+			if value, ok := sourceValues[sourceKeys[i]]; ok {
+				values[key] = value
+				metadata[key] = sourceMetadata[sourceKeys[i]]
+			}
+		}
+	}
+
+	return
+}
+
+// ParameterName returns a name describing the parameter names of all the underlying sources, in order of precedence.
+func (s *mergeSource) ParameterName(parts []string) string {
+	names := make([]string, len(s.sources))
+	for i, source := range s.sources {
+		names[i] = source.ParameterName(parts)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[ ")
+	for i, source := range s.sources {
+		if i > 0 {
+			sb.WriteString(" < ")
+		}
+		sb.WriteString(source.ID() + ":" + names[i])
+	}
+	sb.WriteString(" ]")
+
+	return sb.String()
+}
+
+// sourceNames returns the parameter names of the underlying sources for the key; those given by the name returned by
+// ParameterName, followed by the version or label selector of the parameter, if any, or the key itself for all the
+// sources if it is not such a name, as for the absolute keys of fields.
+func (s *mergeSource) sourceNames(key string) (names []string, err error) {
+	names = make([]string, len(s.sources))
+
+	inner, ok := strings.CutPrefix(key, "[ ")
+	if !ok {
+		for i := range names {
+			names[i] = key
+		}
+		return
+	}
+
+	end := strings.LastIndex(inner, " ]")
+	if end < 0 {
+		err = fmt.Errorf("unknown parameter %q for source '%s'", key, s.id)
+		return
+	}
+	inner, selector := inner[:end], inner[end+len(" ]"):]
+
+	// Split the names of the sources, each prefixed by the ID of its source, at the separators before the next one
+	for i, source := range s.sources {
+		if inner, ok = strings.CutPrefix(inner, source.ID()+":"); !ok {
+			err = fmt.Errorf("unknown parameter %q for source '%s'", key, s.id)
+			return
+		}
+
+		next := len(inner)
+		if i < len(s.sources)-1 {
+			if next = strings.Index(inner, " < "+s.sources[i+1].ID()+":"); next < 0 {
+				err = fmt.Errorf("unknown parameter %q for source '%s'", key, s.id)
+				return
+			}
+		}
+
+		names[i] = inner[:next] + selector
+		inner = strings.TrimPrefix(inner[next:], " < ")
+	}
+
+	return
+}
+
+func (s *mergeSource) ID() string {
+	return s.id
+}
+
+func (s *mergeSource) Refreshable() bool {
+	for _, source := range s.sources {
+		if !source.Refreshable() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,110 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMergeSource(t *testing.T) {
+	ps := mockParameterStore{
+		"/project/param1": "project-value1",
+		"/project/param2": "project-value2",
+		"/project/param3": "project-value3",
+
+		"/app/param2": "app-value2",
+		"/app/param3": "app-value3",
+
+		"/instance/param3": "instance-value3",
+	}
+
+	layered := func() Source {
+		return MergeSourceWithID("layered",
+			&mockSource{ps: ps, path: "/project/", id: "project", refreshable: true},
+			&mockSource{ps: ps, path: "/app/", id: "app", refreshable: true},
+			&mockSource{ps: ps, path: "/instance/", id: "instance", refreshable: true},
+		)
+	}
+
+	t.Run("precedence", func(t *testing.T) {
+		cfg := &struct {
+			Param1 string `sky:",source:layered"`
+			Param2 string `sky:",source:layered"`
+			Param3 string `sky:",source:layered"`
+			Param4 string `sky:",source:layered,default:value4"`
+			Param5 string `sky:",source:other"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false,
+			layered(),
+			&mockSource{ps: mockParameterStore{"/other/param5": "other-value5"}, path: "/other/", id: "other"},
+		)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "project-value1", cfg.Param1)
+			assert.Equal(t, "app-value2", cfg.Param2)
+			assert.Equal(t, "instance-value3", cfg.Param3)
+			assert.Equal(t, "value4", cfg.Param4)
+			assert.Equal(t, "other-value5", cfg.Param5)
+		}
+	})
+
+	t.Run("missing parameter", func(t *testing.T) {
+		cfg := &struct {
+			Param6 string `sky:",source:layered"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, layered())
+		assert.ErrorIs(t, err, ErrParameterNotFound)
+	})
+
+	t.Run("error from underlying source", func(t *testing.T) {
+		cfg := &struct {
+			Param1 string `sky:"an_error"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, layered())
+		assert.ErrorIs(t, err, errMockSourceError)
+	})
+
+	t.Run("absolute key", func(t *testing.T) {
+		ps["/shared/param7"] = "shared-value7"
+		defer delete(ps, "/shared/param7")
+
+		cfg := &struct {
+			Param7 string `sky:"/shared/param7,source:layered"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, layered())
+		if assert.NoError(t, err) {
+			assert.Equal(t, "shared-value7", cfg.Param7)
+		}
+	})
+
+	t.Run("selector", func(t *testing.T) {
+		ps["/project/param8:3"] = "project-value8"
+		ps["/app/param8:3"] = "app-value8"
+		defer delete(ps, "/project/param8:3")
+		defer delete(ps, "/app/param8:3")
+
+		cfg := &struct {
+			Param8 string `sky:"param8,source:layered,version:3"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, layered())
+		if assert.NoError(t, err) {
+			assert.Equal(t, "app-value8", cfg.Param8)
+		}
+	})
+
+	t.Run("parameter name", func(t *testing.T) {
+		s := layered()
+		assert.Equal(t, "[ project:/project/some_param < app:/app/some_param < instance:/instance/some_param ]",
+			s.ParameterName([]string{"SomeParam"}))
+	})
+
+	t.Run("refreshable", func(t *testing.T) {
+		assert.True(t, layered().Refreshable())
+		assert.False(t, MergeSource(layered(), &mockSource{ps: ps}).Refreshable())
+		assert.Equal(t, "merge", MergeSource().ID())
+	})
+}
@@ -0,0 +1,87 @@
+package skyconf
+
+import (
+	"context"
+	"time"
+)
+
+// Metadata describes the value of a parameter, as provided by the source it was fetched from. Fields unknown to the
+// source are left zero.
+type Metadata struct {
+	// Version is the version of the parameter.
+	Version int64
+	// LastModified is the time the parameter was last changed.
+	LastModified time.Time
+	// Type is the type of the parameter, such as String or SecureString.
+	Type string
+	// ARN is the Amazon Resource Name of the parameter.
+	ARN string
+}
+
+// MetadataSource is a Source that can also provide the metadata of the parameters it fetches. When a source implements
+// MetadataSource, SourceWithMetadata is used in place of Source.
+type MetadataSource interface {
+	Source
+	// SourceWithMetadata fetches the parameters from the source, along with their metadata, both keyed by parameter name.
+	SourceWithMetadata(ctx context.Context, params []string) (values map[string]string, metadata map[string]Metadata,
+		err error)
+}
+
+// FieldStatus is the state of a field of a configuration struct, as returned by Refresher.Status.
+type FieldStatus struct {
+	// ID is the identifier of the field.
+	ID string
+	// Description is the description of the field, given by the `desc` or `skydoc` tags.
+	Description string
+	// Source is the ID of the source the value of the field was last set from; empty if the value was not set from a
+	// source.
+	Source string
+	// Parameter is the name of the parameter the value of the field was last set from.
+	Parameter string
+	// Provenance tells where the current value of the field comes from: a source, its default value, the value it
+	// was set to before parsing, or none.
+	Provenance Provenance
+	// Metadata is the metadata of the value, if provided by the source. It is not known for values applied using
+	// Refresher.Receive.
+	Metadata Metadata
+	// Refresh is the refresh interval of the field; 0 if it is not refreshed periodically.
+	Refresh time.Duration
+	// Paused is true if the refresh of the field is paused.
+	Paused bool
+	// Stale is true if the value of the field was taken from a cache when parsing, and has not been refreshed since;
+	// see WithCache.
+	Stale bool
+	// Suppressed is the time until which the changes of the field are suppressed, as it changes too often; zero if
+	// they are not. See WithFlapGuard.
+	Suppressed time.Time
+}
+
+// Status returns the state of each field of the configuration struct, in the order of the fields.
+func (u *updater) Status() []FieldStatus {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	now := u.now()
+	status := make([]FieldStatus, len(u.fields))
+	for i, f := range u.fields {
+		status[i] = FieldStatus{
+			ID:          f.field.options.id,
+			Description: f.field.options.doc,
+			Parameter:   f.key,
+			Provenance:  f.origin,
+			Metadata:    f.metadata,
+			Refresh:     f.field.options.refresh,
+			Paused:      f.paused,
+			Stale:       f.stale,
+		}
+
+		if f.source != nil {
+			status[i].Source = f.source.ID()
+		}
+		if now.Before(f.flap.until) {
+			status[i].Suppressed = f.flap.until
+		}
+	}
+
+	return status
+}
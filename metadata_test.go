@@ -0,0 +1,97 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// versionedSource is a mock source that provides the version of the values as metadata, incrementing it on each fetch.
+type versionedSource struct {
+	*mockSource
+	version atomic.Int64
+}
+
+func (v *versionedSource) SourceWithMetadata(ctx context.Context, params []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
+	if values, err = v.Source(ctx, params); err != nil {
+		return
+	}
+
+	version := v.version.Add(1)
+	metadata = make(map[string]Metadata, len(values))
+	for key := range values {
+		metadata[key] = Metadata{Version: version, Type: "String", ARN: "arn:" + key}
+	}
+
+	return
+}
+
+func TestStatus(t *testing.T) {
+	versioned := &versionedSource{mockSource: &mockSource{
+		ps:          mockParameterStore{"/path/param1": "value1"},
+		path:        "/path/",
+		id:          "versioned",
+		refreshable: true,
+	}}
+	plain := &mockSource{
+		ps:   mockParameterStore{"/path/param2": "value2"},
+		path: "/path/",
+		id:   "plain",
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1m,source:versioned"`
+		Param2 string `sky:",source:plain,desc:The second parameter"`
+		Param3 string `sky:",optional"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, versioned, plain)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []FieldStatus{
+		{
+			ID:         "Param1",
+			Source:     "versioned",
+			Parameter:  "/path/param1",
+			Provenance: ProvenanceSource,
+			Metadata:   Metadata{Version: 1, Type: "String", ARN: "arn:/path/param1"},
+			Refresh:    time.Minute,
+		},
+		{ID: "Param2", Description: "The second parameter", Source: "plain", Parameter: "/path/param2",
+			Provenance: ProvenanceSource},
+		{ID: "Param3", Provenance: ProvenanceZero},
+	}, r.Status())
+
+	// The metadata is updated by a refresh, even if the value has not changed
+	assert.NoError(t, r.Pause("Param2"))
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	status := r.Status()
+	assert.Equal(t, int64(2), status[0].Metadata.Version)
+	assert.True(t, status[1].Paused)
+}
+
+func TestMergeSourceMetadata(t *testing.T) {
+	versioned := &versionedSource{mockSource: &mockSource{
+		ps:   mockParameterStore{"/a/param1": "a-value1"},
+		path: "/a/",
+	}}
+	plain := &mockSource{ps: mockParameterStore{"/b/param2": "b-value2"}, path: "/b/"}
+
+	merged := MergeSource(versioned, plain).(MetadataSource)
+	key1, key2 := merged.ParameterName([]string{"param1"}), merged.ParameterName([]string{"param2"})
+
+	values, metadata, err := merged.SourceWithMetadata(context.Background(), []string{key1, key2})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{key1: "a-value1", key2: "b-value2"}, values)
+	assert.Equal(t, map[string]Metadata{
+		key1: {Version: 1, Type: "String", ARN: "arn:/a/param1"},
+		key2: {},
+	}, metadata)
+}
@@ -0,0 +1,49 @@
+package skyconf
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics records measurements of the parse and refresh activity. It is deliberately small so that it can be backed by
+// Prometheus collectors, expvar, OpenTelemetry instruments or anything else.
+type Metrics interface {
+	// ObserveParse records the duration and the outcome of a call to Parse.
+	ObserveParse(d time.Duration, err error)
+	// ObserveFetch records the latency and the outcome of fetching a number of parameters from a source.
+	ObserveFetch(sourceID string, keys int, d time.Duration, err error)
+	// ObserveRefresh records the outcome of refreshing fields from a source; err is the first error that occurred.
+	ObserveRefresh(sourceID string, err error)
+	// FieldUpdated records that the value of the field with the given ID was changed by a refresh.
+	FieldUpdated(id string)
+}
+
+// DeprecationMetrics is implemented by Metrics that also record the use of deprecated parameters, to track which
+// services still rely on them; see the `deprecated` and `alias` tags.
+type DeprecationMetrics interface {
+	// DeprecatedParameter records that the value of the field with the given ID was loaded from a deprecated parameter.
+	DeprecatedParameter(id, sourceID, parameter string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveParse(time.Duration, error) {}
+
+func (nopMetrics) ObserveFetch(string, int, time.Duration, error) {}
+
+func (nopMetrics) ObserveRefresh(string, error) {}
+
+func (nopMetrics) FieldUpdated(string) {}
+
+// reportDeprecated records that the value of the field was loaded from a deprecated parameter of the source; either
+// the field is tagged with `deprecated`, or the parameter is one of its aliases.
+func (o *options) reportDeprecated(ctx context.Context, field fieldInfo, source Source, key string) {
+	if field.options.deprecated != "" {
+		o.logger.WarnContext(ctx, "deprecated parameter in use",
+			"field", field.options.id, "source", source.ID(), "parameter", key, "message", field.options.deprecated)
+	}
+
+	if m, ok := o.metrics.(DeprecationMetrics); ok {
+		m.DeprecatedParameter(field.options.id, source.ID(), key)
+	}
+}
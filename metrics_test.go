@@ -0,0 +1,146 @@
+package skyconf
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockMetrics struct {
+	m        sync.Mutex
+	parses   []error
+	fetches  map[string]int
+	refresh  map[string][]error
+	updated  []string
+	keyCount int
+	retired  []string
+	throttle map[string][]time.Duration
+	flapping map[string][]time.Duration
+}
+
+func (mm *mockMetrics) ObserveParse(_ time.Duration, err error) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	mm.parses = append(mm.parses, err)
+}
+
+func (mm *mockMetrics) ObserveFetch(sourceID string, keys int, _ time.Duration, _ error) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	if mm.fetches == nil {
+		mm.fetches = make(map[string]int)
+	}
+	mm.fetches[sourceID]++
+	mm.keyCount += keys
+}
+
+func (mm *mockMetrics) ObserveRefresh(sourceID string, err error) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	if mm.refresh == nil {
+		mm.refresh = make(map[string][]error)
+	}
+	mm.refresh[sourceID] = append(mm.refresh[sourceID], err)
+}
+
+func (mm *mockMetrics) FieldUpdated(id string) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	mm.updated = append(mm.updated, id)
+}
+
+func (mm *mockMetrics) DeprecatedParameter(id, sourceID, parameter string) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	mm.retired = append(mm.retired, id+"="+sourceID+":"+parameter)
+}
+
+func (mm *mockMetrics) Throttled(sourceID string, interval time.Duration) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	if mm.throttle == nil {
+		mm.throttle = make(map[string][]time.Duration)
+	}
+	mm.throttle[sourceID] = append(mm.throttle[sourceID], interval)
+}
+
+func (mm *mockMetrics) FieldSuppressed(id string, d time.Duration) {
+	mm.m.Lock()
+	defer mm.m.Unlock()
+	if mm.flapping == nil {
+		mm.flapping = make(map[string][]time.Duration)
+	}
+	mm.flapping[id] = append(mm.flapping[id], d)
+}
+
+func TestMetrics(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/param1": "value1",
+			"/path/param2": "value2",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1m"`
+		Param2 string `sky:",refresh:1m,id:second"`
+	}{}
+
+	mm := &mockMetrics{}
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithMetrics(mm))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []error{nil}, mm.parses)
+	assert.Equal(t, map[string]int{"mock": 1}, mm.fetches)
+	assert.Equal(t, 2, mm.keyCount)
+
+	source.set("/path/param2", "new-value2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	assert.Equal(t, map[string]int{"mock": 2}, mm.fetches)
+	assert.Equal(t, map[string][]error{"mock": {nil}}, mm.refresh)
+	assert.Equal(t, []string{"second"}, mm.updated)
+
+	// A failing parse is recorded too
+	_, err = ParseWithOptions(context.Background(), cfg, nil, WithMetrics(mm))
+	assert.ErrorIs(t, err, ErrNoSource)
+	assert.Equal(t, []error{nil, ErrNoSource}, mm.parses)
+}
+
+func TestDeprecatedParameters(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/timeout":  "5s",
+			"/path/old_host": "localhost",
+		},
+		path: "/path/",
+	}
+
+	cfg := &struct {
+		Timeout time.Duration `sky:"timeout,deprecated:use the client deadline instead"`
+		Retries int           `sky:"retries,deprecated,default:3"`
+		Host    string        `sky:"host,alias:oldHost"`
+	}{}
+
+	mm := &mockMetrics{}
+	_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithMetrics(mm), WithLogger(logger))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Only the values loaded from deprecated parameters are reported; not default values
+	assert.ElementsMatch(t, []string{"timeout=mock:/path/timeout", "host=mock:/path/old_host"}, mm.retired)
+	assert.Contains(t, buf.String(), `msg="deprecated parameter in use" field=timeout source=mock parameter=/path/timeout message="use the client deadline instead"`)
+	assert.NotContains(t, buf.String(), "field=retries")
+}
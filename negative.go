@@ -0,0 +1,61 @@
+package skyconf
+
+import (
+	cfclock "code.cloudfoundry.org/clock"
+	"sync"
+	"time"
+)
+
+// WithNegativeCache makes an SSM source remember the parameters it did not find, and leave them out of the requests it
+// makes until recheck has elapsed since they were last looked up. This saves requests when refreshing fields whose
+// parameters rarely exist, such as optional fields kept when their parameter is deleted, as set by the `ondelete` tag,
+// as long as recheck is longer than their refresh interval. A parameter created meanwhile is not found until it is
+// looked up again. Parameters are looked up on every fetch if recheck is 0.
+func WithNegativeCache(recheck time.Duration) SourceOption {
+	return func(o *sourceOptions) {
+		o.negativeRecheck = recheck
+	}
+}
+
+// missingCache holds the parameters a source did not find, until they are due to be looked up again.
+type missingCache struct {
+	m       sync.Mutex
+	recheck time.Duration
+	clock   cfclock.Clock
+	due     map[string]time.Time // the time each parameter not found is to be looked up again
+}
+
+func newMissingCache(recheck time.Duration) *missingCache {
+	return &missingCache{recheck: recheck, clock: cfclock.NewClock(), due: make(map[string]time.Time)}
+}
+
+// filter returns the keys, in order, of the parameters to look up; those not known to be missing, or due to be looked
+// up again.
+func (c *missingCache) filter(keys []string) (lookup []string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := c.clock.Now()
+	for _, key := range keys {
+		if due, ok := c.due[key]; !ok || !now.Before(due) {
+			lookup = append(lookup, key)
+		}
+	}
+
+	return
+}
+
+// update records the fetched keys whose parameters were not found, and forgets those that were.
+func (c *missingCache) update(fetched []string, values map[string]string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	due := c.clock.Now().Add(c.recheck)
+	for _, key := range fetched {
+		if _, ok := values[key]; ok {
+			delete(c.due, key)
+		} else {
+			c.due[key] = due
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package skyconf
+
+import (
+	"code.cloudfoundry.org/clock/fakeclock"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNegativeCache(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{
+		"/app/host": {Value: "db1", Version: 1},
+	})
+	source := SSMSourceWithOptions(fake.client(), "/app", "ssm", WithNegativeCache(time.Hour)).(*ssmSource)
+	clock := fakeclock.NewFakeClock(time.Now())
+	source.missing.clock = clock
+
+	var cfg struct {
+		Host    string `sky:"host,refresh:1m"`
+		Feature bool   `sky:"feature,optional,refresh:1m,ondelete:keep"`
+	}
+	r, err := Parse(context.Background(), &cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "db1", cfg.Host)
+	assert.False(t, cfg.Feature)
+
+	// Parameters found missing are left out of the requests until rechecked
+	keys := []string{"/app/host", "/app/feature"}
+	values, _, err := source.SourceWithMetadata(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/app/host": "db1"}, values)
+	assert.Equal(t, []string{"/app/host"}, source.missing.filter(keys))
+
+	fake.put("/app/feature", "true")
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.False(t, cfg.Feature)
+
+	// They are looked up again once due
+	clock.Increment(time.Hour)
+	assert.Equal(t, keys, source.missing.filter(keys))
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.True(t, cfg.Feature)
+
+	// Parameters deleted are recorded as missing, and so are not requested when all of them are
+	delete(fake.parameters, "/app/host")
+	delete(fake.parameters, "/app/feature")
+	_, _, err = source.SourceWithMetadata(context.Background(), keys)
+	assert.NoError(t, err)
+	fake.calls = nil
+	values, _, err = source.SourceWithMetadata(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+	assert.Empty(t, fake.calls)
+}
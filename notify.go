@@ -0,0 +1,82 @@
+package skyconf
+
+import (
+	"context"
+)
+
+// ChangeNotifier is implemented by sources that can tell when parameters change. While refreshing, a change triggers a
+// refresh of the fields tagged with `refresh` whose value was set from the parameter, without waiting for their
+// refresh interval.
+type ChangeNotifier interface {
+	// Changes returns a channel receiving the names of the parameters that changed, until the context is done.
+	Changes(ctx context.Context) (<-chan string, error)
+}
+
+// sourceChange is a change to a parameter of a source.
+type sourceChange struct {
+	source Source
+	key    string
+}
+
+// watchChanges forwards the changes to the parameters of the sources that implement ChangeNotifier to the returned
+// channel, until the context is done. Errors subscribing to the changes of a source are passed to the error function;
+// the fields are then only refreshed at their intervals.
+func (u *updater) watchChanges(ctx context.Context, ef func(err error)) <-chan sourceChange {
+	changes := make(chan sourceChange)
+
+	for _, source := range u.sources {
+		notifier, ok := source.(ChangeNotifier)
+		if !ok {
+			continue
+		}
+
+		keys, err := notifier.Changes(ctx)
+		if err != nil {
+			ef(err)
+			continue
+		}
+
+		u.wg.Add(1)
+		go func(source Source) {
+			defer u.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case key, ok := <-keys:
+					if !ok {
+						return
+					}
+
+					select {
+					case changes <- sourceChange{source: source, key: key}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(source)
+	}
+
+	return changes
+}
+
+// changedFields returns the refreshable fields whose value was set from the parameter of the source.
+func (u *updater) changedFields(c sourceChange) *refreshedFields {
+	rf := &refreshedFields{}
+	for _, sourceFields := range u.timings {
+		fields, ok := sourceFields[c.source]
+		if !ok {
+			continue
+		}
+
+		for i, f := range fields.fields {
+			if fields.keys[i] == c.key {
+				rf.fields = append(rf.fields, f)
+				rf.keys = append(rf.keys, c.key)
+			}
+		}
+	}
+
+	return rf
+}
@@ -0,0 +1,92 @@
+package skyconf
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OfflineEnv is the environment variable enabling the offline mode set by WithOffline, when set to a true value, as
+// parsed by strconv.ParseBool.
+const OfflineEnv = "SKYCONF_OFFLINE"
+
+// WithOffline makes Parse serve the parameters of all the sources, including the overrides of the instance, from the
+// fixtures, keyed by parameter name, rather than fetch them, when the SKYCONF_OFFLINE environment variable is set to
+// true; it has no effect otherwise. This allows integration tests and machines without access to the sources to run
+// the same code as in production: the sources keep their IDs and name the parameters as they do, and the values are
+// parsed and refreshed as if fetched. See OfflineSource.
+func WithOffline(fixtures map[string]string) Option {
+	return func(o *options) {
+		o.offline = fixtures
+	}
+}
+
+// OfflineSource returns a source with the ID of the source, naming the parameters as it does, that serves them from the
+// fixtures, keyed by parameter name, rather than fetch them. Parameters not in the fixtures are not found. The source
+// is refreshable if the source is, and lists the keys of the fixtures under a path for fields tagged with `subtree`.
+func OfflineSource(source Source, fixtures map[string]string) Source {
+	return &offlineSource{source: source, fixtures: fixtures}
+}
+
+type offlineSource struct {
+	source   Source
+	fixtures map[string]string
+}
+
+func (s *offlineSource) Source(_ context.Context, params []string) (values map[string]string, err error) {
+	values = make(map[string]string, len(params))
+	for _, param := range params {
+		if value, ok := s.fixtures[param]; ok {
+			values[param] = value
+		}
+	}
+
+	return
+}
+
+// ListKeys returns the names of the fixtures under the path formed by the parts, relative to the path.
+func (s *offlineSource) ListKeys(_ context.Context, parts []string) (keys []string, err error) {
+	prefix := strings.TrimSuffix(s.ParameterName(parts), "/") + "/"
+	for name := range s.fixtures {
+		if key, ok := strings.CutPrefix(name, prefix); ok && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return
+}
+
+func (s *offlineSource) ParameterName(parts []string) string {
+	return s.source.ParameterName(parts)
+}
+
+func (s *offlineSource) Refreshable() bool {
+	return s.source.Refreshable()
+}
+
+func (s *offlineSource) ID() string {
+	return s.source.ID()
+}
+
+// offlineSources replaces the sources with offline sources serving the fixtures set by WithOffline, if the offline
+// mode is enabled by the environment.
+func (o *options) offlineSources(ctx context.Context, sources []Source) []Source {
+	if o.offline == nil {
+		return sources
+	}
+	if offline, _ := strconv.ParseBool(os.Getenv(OfflineEnv)); !offline {
+		return sources
+	}
+
+	o.logger.InfoContext(ctx, "serving the parameters of the sources offline", "fixtures", len(o.offline))
+
+	offline := make([]Source, len(sources))
+	for i, source := range sources {
+		offline[i] = OfflineSource(source, o.offline)
+	}
+
+	return offline
+}
@@ -0,0 +1,54 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithOffline(t *testing.T) {
+	// The SSM source has no client, so it fails any fetch
+	source := SSMSourceWithID(nil, "/app", "ssm")
+	fixtures := map[string]string{
+		"/app/db/host":     "localhost",
+		"/app/db/port":     "5432",
+		"/app/limits/cpu":  "2",
+		"/app/limits/mem":  "512",
+		"/other/log_level": "debug",
+	}
+
+	type config struct {
+		DB struct {
+			Host string `sky:"host,refresh:1m"`
+			Port int    `sky:"port"`
+		} `sky:"db"`
+		LogLevel string `sky:"log_level,default:info"`
+	}
+
+	// Without the environment variable, the sources are used
+	var cfg config
+	_, err := ParseWithOptions(context.Background(), &cfg, []Source{source}, WithOffline(fixtures))
+	assert.ErrorContains(t, err, "ssm client is nil")
+
+	// With it, the parameters are served from the fixtures, as named by the sources
+	t.Setenv(OfflineEnv, "1")
+	cfg = config{}
+	r, err := ParseWithOptions(context.Background(), &cfg, []Source{source}, WithOffline(fixtures))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "ssm", r.Status()[0].Source)
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	// The option is required
+	_, err = Parse(context.Background(), &cfg, false, source)
+	assert.Error(t, err)
+
+	// Offline sources list the keys of the fixtures
+	keys, err := OfflineSource(source, fixtures).(KeyLister).ListKeys(context.Background(), []string{"limits"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cpu", "mem"}, keys)
+}
@@ -0,0 +1,109 @@
+package skyconf
+
+import (
+	"go.opentelemetry.io/otel/trace"
+	"hash"
+	"log/slog"
+	"time"
+)
+
+// Option configures the behaviour of ParseWithOptions and the returned Refresher.
+type Option func(o *options)
+
+type options struct {
+	withUntagged bool
+	prefix       []string
+	metrics      Metrics
+	tracer       trace.Tracer
+	logger       *slog.Logger
+	transformer  ValueTransformer
+	transformers map[string]ValueTransformer
+
+	losslessUpdates bool
+	gate            Gate
+	propagate       PropagateFunc
+	hash            func() hash.Hash
+	equal           func(current, updated any) bool
+	strict          bool
+	reportUnknown   UnknownParametersFunc
+	maxDepth        int
+	skipUnsupported bool
+	reportSkipped   SkippedFieldsFunc
+	lazyPointers    bool
+	bestEffort      bool
+	reportSource    SourceErrorFunc
+	retry           RetryPolicy
+	cache           Cache
+	cacheMaxAge     time.Duration
+	minRefresh      time.Duration
+	coalesce        time.Duration
+	batchWindow     time.Duration
+	jitter          float64
+	aligned         bool
+	maxRefreshes    int
+	refreshTimeout  time.Duration
+
+	instanceOverrides func(instance string) Source
+	offline           map[string]string
+	snapshotEncrypt   func(value string) (string, error)
+	expvarName        string
+	audit             AuditFunc
+	history           int
+	validators        []validator
+	flapLimit         int
+	flapWindow        time.Duration
+	maxStreamBytes    int
+	emptyAsMissing    bool
+	profiles          []string
+}
+
+// WithUntagged includes fields not tagged with `sky`; see Parse.
+func WithUntagged() Option {
+	return func(o *options) {
+		o.withUntagged = true
+	}
+}
+
+// WithPrefix prefixes the key of every field with the parts, such as the name of the service or the environment, as if
+// the configuration struct were nested in structs keyed by the parts. Absolute keys are not prefixed.
+func WithPrefix(parts ...string) Option {
+	return func(o *options) {
+		o.prefix = append(o.prefix, parts...)
+	}
+}
+
+// WithMetrics sets the Metrics used to record parse and refresh measurements.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+func makeOptions(opts []Option) *options {
+	o := &options{jitter: defaultJitter, history: defaultHistory}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Set defaults for anything not configured
+	if o.metrics == nil {
+		o.metrics = nopMetrics{}
+	}
+	if o.tracer == nil {
+		o.tracer = defaultTracer()
+	}
+	if o.maxDepth <= 0 {
+		o.maxDepth = defaultMaxDepth
+	}
+	if o.hash == nil {
+		o.hash = defaultHash
+	}
+	if o.gate == nil {
+		o.gate = openGate{}
+	}
+	if o.logger == nil {
+		o.logger = slog.New(discardHandler{})
+	}
+
+	return o
+}
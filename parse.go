@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"maps"
+	"slices"
+	"time"
 )
 
 // Source can format a parameter name and fetch a set of parameters from a source.
@@ -23,10 +26,51 @@ type Source interface {
 type Refresher interface {
 	// Refresh starts a new goroutine that updates the configuration at specified intervals until the context is
 	// cancelled. It returns a channel that sends updated field IDs. If an error occurs, the provided error function is
-	// called. If no error function is provided, the error is ignored.
+	// called. If no error function is provided, the error is ignored. Errors fetching the parameters from a source are
+	// of type *SourceError, and errors setting the value of a field, *FieldError, naming the fields, parameters and
+	// source involved.
 	Refresh(ctx context.Context, ef func(err error)) <-chan string
 	// RefreshOnce refreshes the configuration once, returning the first error that occurs.
 	RefreshOnce(ctx context.Context) (err error)
+	// RefreshNow reloads the whole configuration once, including fields not tagged with `refresh`, returning the first
+	// error that occurs.
+	RefreshNow(ctx context.Context) (err error)
+	// Start refreshes the configuration once, as RefreshOnce does, then starts refreshing it in the background, as
+	// Refresh does, until the context is cancelled or Close is called. It returns once the first refresh is done, with
+	// its error, if any, in which case the background refresh is not started; readiness can then be gated on a working
+	// refresh. Errors of the background refreshes are logged as warnings; updates can be followed with Watch.
+	Start(ctx context.Context) error
+	// Close stops refreshing, waits for any refreshes in flight to complete and closes the updates channel, without
+	// requiring the context passed to Refresh to be cancelled. It returns once everything has been shut down.
+	Close() error
+	// Pause stops refreshing the fields with the given IDs, or all the fields if no IDs are given, until they are
+	// resumed. It returns an error if any of the IDs is unknown.
+	Pause(ids ...string) error
+	// Resume resumes refreshing the fields with the given IDs, or all the fields if no IDs are given. It returns an
+	// error if any of the IDs is unknown.
+	Resume(ids ...string) error
+	// Receive applies the values propagated from another instance, keyed by parameter name, as if they were fetched
+	// from the source with the given ID by a refresh. It returns the first error that occurs.
+	Receive(ctx context.Context, sourceID string, values map[string]string) error
+	// Preview fetches the values of all the fields from the sources, as RefreshNow does, and returns the changes it
+	// would make, without setting the fields or sending updates; changes to paused fields are included.
+	Preview(ctx context.Context) ([]PendingChange, error)
+	// Pending returns the changes to the fields tagged with `manual` found by the refreshes and staged until approved,
+	// in the order of the fields.
+	Pending() []PendingChange
+	// Apply applies the staged changes to the fields with the given IDs, or to all the fields if no IDs are given, and
+	// sends their updates, once validated again by the validators of the fields; see WithValidator. It returns an
+	// error if any of the IDs is unknown, or the errors of all the fields failing to be validated or set, joined; the
+	// other fields are still set.
+	Apply(ids ...string) error
+	// Rollback restores the value the field with the given ID had before it was last changed by a refresh, and sends
+	// its update; the value rolled back from is not applied again until the parameter changes in the source. It can be
+	// called repeatedly to go further back in the history of the field; see WithHistory. It returns an error wrapping
+	// ErrFieldNotFound if the ID is unknown, or ErrNoHistory if there is no previous value.
+	Rollback(id string) error
+	// Status returns the state of each field of the configuration struct, including the parameter and the source its
+	// value was last set from, and the metadata of the value, such as its version, if provided by the source.
+	Status() []FieldStatus
 }
 
 // ParseSSM retrieves configuration from AWS SSM and populates the provided struct. It is a convenience function for
@@ -54,153 +98,535 @@ var ErrBadDefaultFieldValue = errors.New("failed to set default value for field"
 var ErrParameterNotFound = errors.New("parameter not found in source")
 
 // Parse fetches configuration from the provided sources into the given struct.
-// If a source is specified for a field, its value is queried only from that source.
+// If a source is specified for a field, its value is queried only from that source; if several are, separated by '|',
+// the first listed that has the parameter takes precedence, regardless of the order of the sources.
 // Otherwise, all sources are queried in order, with the last source's value taking precedence.
 // Fields not tagged with `sky` are ignored unless `withUntagged` is true.
 // Returns a Refresher for automatic configuration refresh.
 //
 // The configuration struct must have fields tagged with `sky` and the following tags. All tags are optional.
 //   - default: sets the default value for the field.
+//   - defaultfrom: sets the default value for the field to the value of the field with the given ID, once all the
+//     sources have been applied, if neither was it set beforehand nor found in any source. Fields can default to
+//     fields defaulting to others in turn, but not in a cycle. It conflicts with default.
 //   - optional: marks the field as optional, suppressing errors if the field is not found in the source.
-//   - flatten: flattens the field thereby ignoring the key of the outer struct.
-//   - source: specifies the source for the field.
+//   - flatten: flattens a struct field, naming its fields as if they were fields of the enclosing struct; the key of the
+//     field, if any, is then ignored, while its source still applies to its fields. Anonymous struct
+//     fields are flattened unless they are given a key. It has no effect on other fields. squash is an alias.
+//   - source: specifies the source for the field, or the sources, separated by '|', in order of precedence.
 //   - refresh: sets the refresh duration for the field; duration must be in Go time.Duration format and greater than 0.
 //   - id: sets the identifier for the field, used for update notifications.
+//   - desc: describes the field; the description is included in errors and in the output of String, StringWithValues,
+//     ExportTemplate and Refresher.Status. It can also be given by the companion `skydoc` tag, which can contain commas.
+//   - secret: marks the field as holding a secret, redacting its value in logs.
+//   - alias: alternate keys of the parameter, separated by '|', tried in order if it is not found; a deprecation notice
+//     is logged when a value is found under an alias, which allows parameters to be renamed without downtime.
+//   - deprecated: marks the parameter as deprecated; when a value is loaded for the field, a warning is logged with the
+//     given message, if any, and the use is recorded if the metrics implement DeprecationMetrics, as is the use of an
+//     alias.
+//   - transform: transforms the value obtained from a source using the named transformers, separated by '|', in
+//     order; see WithNamedTransformer.
+//   - encoding: decodes the value of a byte slice or array field from hex or base64.
+//   - version: fetches the given version of the parameter, rather than the latest.
+//   - label: fetches the version of the parameter the given label is attached to, rather than the latest; a refresh
+//     picks up a new value only when the label is moved to another version.
+//   - ondelete: what to do when a refresh no longer finds the parameter in the source: "error" passes an error wrapping
+//     ErrMissingKeyOnRefresh to the error function on each refresh, as by default; "keep" leaves the field untouched;
+//     "zero" and "default" set it to its zero or default value and send an update, once.
+//   - manual: stages the changes to the value of the field found on refresh rather than applying them, until approved
+//     with Refresher.Apply; see Refresher.Pending. It is meant for sensitive fields whose changes must be reviewed.
+//   - when: fetches the field only when the field of the same struct with the given name, set first, has the given
+//     value, as in "TLSEnabled=true", or does not, as in "Mode!=local"; otherwise, the field is neither required nor
+//     set from the sources, keeping its default value, if any. The conditions are evaluated when parsing; fields whose
+//     condition does not hold are not refreshed.
+//   - profiles: the profiles the field is part of, separated by '|', as in "prod|staging"; the field, or the fields of
+//     the struct, are only populated if one of them is active, and ignored otherwise. Profiles prefixed by '!' exclude
+//     the field when active, as in "!dev". See WithProfiles.
+//   - allowempty: takes empty values of the parameter even if they count as missing; see WithEmptyAsMissing.
+//
+// Unknown options are ignored; use ParseTag or AnalyzeStruct to find them, such as misspelt ones.
+//
+// A key beginning with "/" is absolute; it names the parameter as is in every source, regardless of the key of the
+// enclosing structs and of the path of the source. Absolute keys can only be given to fields that are not structs.
+//
+// Fields that are maps with string keys and struct values are populated from a subtree of parameters; each entry is
+// keyed by a name found directly under the path of the field, using sources that implement KeyLister. Likewise, fields
+// that are slices of structs are populated from entries indexed 0, 1, 2... under the path of the field. The entries are
+// determined when parsing; entries added to the sources later are not picked up by a refresh.
 func Parse(ctx context.Context, cfg interface{}, withUntagged bool, sources ...Source) (r Refresher, err error) {
+	var opts []Option
+	if withUntagged {
+		opts = append(opts, WithUntagged())
+	}
+
+	return ParseWithOptions(ctx, cfg, sources, opts...)
+}
+
+// ParseWithOptions is like Parse, but its behaviour can be configured using options. Fields not tagged with `sky` are
+// ignored unless the WithUntagged option is provided.
+func ParseWithOptions(ctx context.Context, cfg interface{}, sources []Source, opts ...Option) (r Refresher, err error) {
+	o := makeOptions(opts)
+
+	start := time.Now()
+	ctx, endSpan := o.startSpan(ctx, "skyconf.Parse")
+	defer func() {
+		endSpan(err)
+		o.metrics.ObserveParse(time.Since(start), err)
+	}()
+
 	if len(sources) == 0 {
 		err = ErrNoSource
 		return
 	}
 
+	// Append the overrides of the instance, taking precedence over the other sources
+	if sources, err = o.appendInstanceOverrides(ctx, sources); err != nil {
+		return
+	}
+
+	// Serve the parameters from the fixtures rather than the sources, if offline
+	sources = o.offlineSources(ctx, sources)
+
 	// Get the list of fields from the configuration struct to process.
 	var fields []fieldInfo
-	fields, err = extractFields(withUntagged, nil, cfg, fieldOptions{})
+	fields, err = o.extractFields(cfg)
 	if err != nil {
 		err = fmt.Errorf("failed to extract fields: %w", err)
 		return
 	}
 
-	// Check if we have all the specified sources
-	for _, field := range fields {
+	// Expand the fields populated from subtrees of parameters into the fields of their entries
+	var commitSubtrees func()
+	fields, commitSubtrees, err = o.expandSubtrees(ctx, fields, sources)
+	if err != nil {
+		return
+	}
+
+	// Check if we have all the transformers the fields refer to
+	if err = o.checkTransformers(fields); err != nil {
+		return
+	}
+
+	if err = o.checkRefreshIntervals(fields); err != nil {
+		return
+	}
+
+	// Check if the fields the validators are registered for exist
+	if err = o.checkValidators(fields); err != nil {
+		return
+	}
+
+	// Resolve the conditions of the conditional fields
+	var conditions map[int]condition
+	var rounds []int
+	if conditions, rounds, err = fieldConditions(fields); err != nil {
+		return
+	}
+
+	// Order the fields taking their default values from other fields
+	var defaultsFrom []defaultFrom
+	if defaultsFrom, err = defaultFromOrder(fields); err != nil {
+		return
+	}
+
+	// Check if we have all the specified sources, and find the last source each field can be taken from, after which
+	// it is known to be missing
+	lastSources := make([]int, len(fields))
+	for i, field := range fields {
+		lastSources[i] = len(sources) - 1
 		if field.options.source == "" {
 			continue
 		}
 
-		found := false
-		for _, source := range sources {
-			if source.ID() == field.options.source {
-				found = true
-				break
+		for _, id := range field.options.sourceIDs() {
+			if !slices.ContainsFunc(sources, func(s Source) bool { return s.ID() == id }) {
+				err = fmt.Errorf("'%s' : %w", id, ErrSourceNotFound)
+				return
 			}
 		}
-
-		if !found {
-			err = fmt.Errorf("'%s' : %w", field.options.source, ErrSourceNotFound)
-			return
+		for j, source := range sources {
+			if field.options.fromSource(source.ID()) {
+				lastSources[i] = j
+			}
 		}
 	}
 
-	// First, process any default values for the fields
-	for _, field := range fields {
+	// First, process any default values for the fields, which do not override the values set beforehand
+	origins := make([]Provenance, len(fields))
+	for i, field := range fields {
+		origins[i] = ProvenanceZero
+		if !field.structField.IsZero() {
+			origins[i] = ProvenancePreset
+		}
+
 		// If there is no default value, continue
 		if field.options.defaultValue == "" {
 			continue
 		}
 
 		// Process the default value for the field
-		err = processFieldValue(true, field.options.defaultValue, field.structField)
+		err = decodeFieldValue(true, field.options.defaultValue, field.structField, field.options)
 		if err != nil {
-			err = fmt.Errorf("%w of type %s: %w", ErrBadDefaultFieldValue, field.structField.Type(), err)
+			err = field.fieldError(StageDefault, nil, "",
+				fmt.Errorf("%w of type %s: %w", ErrBadDefaultFieldValue, field.structField.Type(), err))
 			return
 		}
-	}
-
-	// Create an updater to handle refreshable fields.
-	upd := &updater{}
 
-	// Format the keys for each field based on the source by matching the source ID.
-	for sourceIdx, source := range sources {
-		var keys []string
-		var fieldsMap = make(map[string]fieldInfo)
-		for _, field := range fields {
-			if field.options.source == "" || field.options.source == source.ID() {
-				key := source.ParameterName(field.nameParts)
-				keys = append(keys, key)
-				fieldsMap[key] = field
-			}
+		if origins[i] == ProvenanceZero {
+			origins[i] = ProvenanceDefault
 		}
 
-		// Fetch the parameters from the source
-		var values map[string]string
-		values, err = source.Source(ctx, keys)
-		if err != nil {
-			err = fmt.Errorf("%w from source '%s' : %w", ErrGetParameters, source.ID(), err)
-			return
-		}
+		o.logger.DebugContext(ctx, "applied default value",
+			"field", field.options.id, "value", field.logValue(field.options.defaultValue))
+	}
 
-		// Process the fields based on the values obtained from the source
-		for key, field := range fieldsMap {
-			value, ok := values[key]
+	// Create an updater to keep track of the fields and handle refreshable fields.
+	upd := newUpdater(o, sources, fields)
+	for i, origin := range origins {
+		upd.fields[i].origin = origin
+	}
 
-			// If the field is not found in the source, check if it is optional
-			if !ok {
-				// If a source is not specified and the current source is not the last source, continue
-				if field.options.source == "" && sourceIdx < len(sources)-1 {
-					continue
-				}
+	// Fields not found in any source, whose error depends on whether their lazy pointers get set
+	var missing []missingField
 
-				// If the field is non-zero value, continue
-				// The field might have a non-zero value set by the default value or a previous source or from the struct initialisation.
-				if !field.structField.IsZero() {
-					continue
-				}
+	// Fetch the fields in rounds, each after the fields they are conditional on, skipping those whose conditions do not
+	// hold
+	skipped := make([]bool, len(fields))
+	lastRound := slices.Max(append(rounds, 0))
+
+	// The values fetched from each source across the rounds, to cache them at once; nil for the sources that failed
+	fetched := make([]map[string]string, len(sources))
+	for sourceIdx := range sources {
+		fetched[sourceIdx] = make(map[string]string)
+	}
+	for round := 0; round <= lastRound; round++ {
+		for idx, c := range conditions {
+			if rounds[idx] == round && !c.holds(fields) {
+				skipped[idx] = true
+				o.logger.DebugContext(ctx, "skipped conditional field",
+					"field", fields[idx].options.id, "when", fields[idx].options.when)
+			}
+		}
 
-				// If the field is optional, continue
-				if field.options.optional {
+		// Format the keys for each field based on the source by matching the source ID.
+		for sourceIdx, source := range sources {
+			var keys []string
+			var paths []string
+			var fieldsMap = make(map[string][]int)
+			requested := make(map[string]bool)
+			for idx, field := range fields {
+				if rounds[idx] != round || skipped[idx] {
 					continue
 				}
 
-				// If the field is not optional, and no default value is provided, return an error
-
-				var src string
-				if field.options.source == "" && len(sources) > 1 {
-					src = "(any)"
-				} else {
-					src = source.ID()
+				if field.options.fromSource(source.ID()) {
+					key := field.parameterName(source)
+					fieldsMap[key] = append(fieldsMap[key], idx)
+					paths = append(paths, field.path)
+
+					// Request the aliases of the parameter along with it, in case it is not found
+					for _, k := range append([]string{key}, field.aliasNames(source)...) {
+						if !requested[k] {
+							requested[k] = true
+							keys = append(keys, k)
+						}
+					}
 				}
-
-				err = fmt.Errorf("%w - %s:%s", ErrParameterNotFound, src, key)
-				return
 			}
 
-			// Process the field using the value obtained from the source
-			if err = processFieldValue(false, value, field.structField); err != nil {
-				err = fmt.Errorf("%w of type %s; parameter-key: %s; %w", ErrBadFieldValue, field.structField.Type(), key, err)
-				return
+			// Skip the source if none of the conditional fields of the round are taken from it
+			if round > 0 && len(keys) == 0 {
+				continue
 			}
 
-			// If the field is refreshable, add it to the updater
-			// NOTE that the field is added to the updater only if the value is successfully set the first time.
-			if field.options.refresh != 0 {
-				err = upd.add(field, key, source, value)
-				if err != nil {
+			// Fetch the parameters from the source
+			var values map[string]string
+			var metadata map[string]Metadata
+			var fetchErr error
+			var stale bool
+			var unfetched map[string]bool
+			values, metadata, err = o.fetchWithRetry(ctx, source, keys)
+			partial := values
+			if err != nil {
+				// Keep the values last cached for the source, rather than replace them with those of other rounds
+				fetched[sourceIdx] = nil
+			}
+			if err == nil {
+				if fetched[sourceIdx] != nil {
+					maps.Copy(fetched[sourceIdx], values)
+				}
+			} else if values, stale = o.loadCached(ctx, source, keys); stale {
+				// Fall back to the values last fetched from the source
+				err = nil
+			} else {
+				err = &SourceError{Source: source.ID(), Parameters: keys, Fields: paths, Err: err}
+				if !o.bestEffort {
 					return
 				}
+
+				// Carry on without the values of the source, failing only the fields that require them, or with those it
+				// fetched before it failed, if it returned partial results
+				o.reportSourceError(ctx, source.ID(), err)
+				if unfetched = unfetchedKeys(err); unfetched != nil {
+					values = partial
+				}
+				fetchErr, err = err, nil
+			}
+
+			// Process the fields based on the values obtained from the source
+			for _, indices := range fieldsMap {
+				for _, idx := range indices {
+					field := fields[idx]
+					key, value, ok := o.lookupValue(ctx, field, source, values)
+
+					// If the field is not found in the source, check if it is optional
+					if !ok {
+						// If the current source is not the last source the field can be taken from, continue
+						if sourceIdx < lastSources[idx] {
+							continue
+						}
+
+						// If the field is non-zero value, continue
+						// The field might have a non-zero value set by the default value or a previous source or from the struct initialisation.
+						if !field.structField.IsZero() {
+							continue
+						}
+
+						// If the field is optional, or takes its default value from another field, continue
+						if field.options.optional || field.options.defaultFrom != "" {
+							continue
+						}
+
+						// If the field is not optional, and no default value is provided, return an error
+
+						if fetchErr != nil && (unfetched == nil || unfetched[key]) {
+							err = fetchErr
+						} else {
+							err = ErrParameterNotFound
+						}
+						if field.options.doc != "" {
+							err = fmt.Errorf("%w (%s)", err, field.options.description())
+						}
+						fe := field.fieldError(StageParse, source, key, err)
+						if len(field.options.sourceIDs()) != 1 && len(sources) > 1 {
+							// List the parameters looked up in all the sources
+							fe.Source = "(any)"
+							fe.Candidates = field.candidates(sources)
+						}
+						err = fe
+
+						// A field of a struct behind a lazy pointer is only required if the pointer gets set
+						if len(field.pointers) > 0 {
+							missing = append(missing, missingField{field: field, err: err})
+							err = nil
+							continue
+						}
+
+						return
+					}
+
+					// Keep the value of a source the field prefers, as listed before this one by its `source` tag
+					if current := upd.fields[idx].source; current != nil && field.options.prefers(current.ID(), source.ID()) {
+						continue
+					}
+
+					// Process the field using the value obtained from the source, after transforming it
+					var decoded string
+					if decoded, err = o.transform(ctx, field, value); err == nil {
+						err = field.decode(decoded)
+					}
+					if err != nil {
+						err = field.valueError(StageParse, source, key, err)
+						return
+					}
+
+					// Set the pointers to the structs enclosing the field, if they are lazy
+					field.allocate()
+
+					o.logger.DebugContext(ctx, "set field value",
+						"field", field.options.id, "source", source.ID(), "parameter", key, "value", field.logValue(value))
+
+					if field.options.deprecated != "" || key != field.parameterName(source) {
+						o.reportDeprecated(ctx, field, source, key)
+					}
+
+					// Record the parameter and the source of the value with the updater
+					// NOTE that a refreshable field is refreshed only if the value is successfully set the first time.
+					oldHash := upd.fields[idx].valueHash
+					err = upd.add(idx, key, source, value, metadata[key])
+					if err != nil {
+						return
+					}
+					upd.fields[idx].stale = stale
+					upd.audit(ctx, upd.fields[idx], StageParse, source, key, oldHash, value, false)
+				}
 			}
 		}
 	}
 
-	// If there are no refreshable fields, return an empty refresher
-	if upd.empty() {
-		r = noRefresh
+	// Cache the values fetched from the sources in all the rounds, as they replace those cached before
+	for sourceIdx, source := range sources {
+		if fetched[sourceIdx] != nil {
+			o.storeCached(ctx, source, fetched[sourceIdx])
+		}
+	}
+
+	// Set the fields taking their default values from other fields, now that those are known
+	if err = upd.applyDefaultsFrom(ctx, defaultsFrom); err != nil {
 		return
 	}
 
+	// Fail on the first field missing from a struct whose pointer has been set
+	for _, m := range missing {
+		if !m.field.unset() {
+			err = m.err
+			return
+		}
+	}
+
+	// Check the sources for parameters that map to none of the fields
+	if o.strict {
+		if err = o.checkUnknownParameters(ctx, fields, sources); err != nil {
+			return
+		}
+	}
+
+	// Set the entries of the fields populated from subtrees of parameters
+	commitSubtrees()
+
 	// Setup locking if the configuration struct is lockable
 	upd.setupLock(cfg)
 
 	// Return the updater as the refresher
 	r = upd
 
+	// Publish the statistics of the refresher, if requested
+	o.publishExpvar(ctx, cfg, r)
+
+	return
+}
+
+// missingField is a field not found in any of the sources.
+type missingField struct {
+	field fieldInfo
+	err   error
+}
+
+// fetch fetches the values of the keys from the source, along with their metadata if the source implements
+// MetadataSource or StreamSource, recording the measurements, a trace span and a log entry.
+func (o *options) fetch(ctx context.Context, source Source, keys []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
+	start := time.Now()
+	ctx, endSpan := o.startSpan(ctx, "skyconf.Source", attrSourceID.String(source.ID()), attrKeyCount.Int(len(keys)))
+	ctx = o.withThrottleReporter(ctx, source)
+	if ss, ok := source.(StreamSource); ok {
+		values, metadata, err = o.stream(ctx, ss, keys)
+	} else if ms, ok := source.(MetadataSource); ok {
+		values, metadata, err = ms.SourceWithMetadata(ctx, keys)
+	} else {
+		values, err = source.Source(ctx, keys)
+	}
+	endSpan(err)
+	o.metrics.ObserveFetch(source.ID(), len(keys), time.Since(start), err)
+
+	if err != nil {
+		o.logger.DebugContext(ctx, "failed to fetch parameters", "source", source.ID(), "keys", keys, "error", err)
+	} else {
+		o.logger.DebugContext(ctx, "fetched parameters", "source", source.ID(), "keys", keys, "found", len(values))
+	}
+
+	return
+}
+
+// resolvedValue is the value of a field resolved from the sources.
+type resolvedValue struct {
+	source   Source
+	key      string
+	value    string
+	metadata Metadata
+}
+
+// resolveValues fetches the values of the fields from the sources, respecting the precedence of the sources, and
+// returns them indexed by the position of the field. A field is fetched from a source only if include returns true.
+// Fields not found in any source are omitted.
+func resolveValues(ctx context.Context, o *options, sources []Source, fields []fieldInfo,
+	include func(idx int, source Source) bool) (resolved map[int]resolvedValue, err error) {
+
+	resolved = make(map[int]resolvedValue)
+
+	for _, source := range sources {
+		// Collect the keys of the fields that can be fetched from this source
+		var keys, paths []string
+		fieldsMap := make(map[string][]int)
+		requested := make(map[string]bool)
+		for idx, field := range fields {
+			if !field.options.fromSource(source.ID()) {
+				continue
+			}
+
+			if !include(idx, source) {
+				continue
+			}
+
+			key := field.parameterName(source)
+			fieldsMap[key] = append(fieldsMap[key], idx)
+			paths = append(paths, field.path)
+
+			for _, k := range append([]string{key}, field.aliasNames(source)...) {
+				if !requested[k] {
+					requested[k] = true
+					keys = append(keys, k)
+				}
+			}
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		var values map[string]string
+		var metadata map[string]Metadata
+		values, metadata, err = o.fetch(ctx, source, keys)
+		if err != nil {
+			err = &SourceError{Source: source.ID(), Parameters: keys, Fields: paths, Err: err}
+			return
+		}
+
+		// Values from later sources take precedence, unless the field prefers the earlier ones
+		for _, indices := range fieldsMap {
+			for _, idx := range indices {
+				if rv, ok := resolved[idx]; ok && fields[idx].options.prefers(rv.source.ID(), source.ID()) {
+					continue
+				}
+				if key, value, ok := o.lookupValue(ctx, fields[idx], source, values); ok {
+					resolved[idx] = resolvedValue{source: source, key: key, value: value, metadata: metadata[key]}
+				}
+			}
+		}
+	}
+
 	return
 }
+
+// lookupValue returns the value of the parameter of the field among the values fetched from the source. If the
+// parameter is not found, the value of the first of its alias keys found is returned, and a deprecation notice logged.
+func (o *options) lookupValue(ctx context.Context, field fieldInfo, source Source,
+	values map[string]string) (key, value string, ok bool) {
+
+	key = field.parameterName(source)
+	if value, ok = values[key]; ok && !o.isMissing(field, value) {
+		return
+	}
+
+	for _, alias := range field.aliasNames(source) {
+		if value, ok = values[alias]; ok && !o.isMissing(field, value) {
+			o.logger.WarnContext(ctx, "parameter found under a deprecated alias",
+				"field", field.options.id, "source", source.ID(), "parameter", alias, "replacement", key)
+			key = alias
+			return
+		}
+	}
+
+	return key, "", false
+}
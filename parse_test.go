@@ -1,10 +1,13 @@
 package skyconf
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -36,6 +39,21 @@ func (ps mockParameterStore) getValues(params []string) (values map[string]strin
 	return
 }
 
+func (ps mockParameterStore) listKeys(path string) (keys []string, err error) {
+	if ps == nil {
+		err = errInvalidSource
+		return
+	}
+
+	for p := range ps {
+		if key, ok := strings.CutPrefix(p, path+"/"); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return
+}
+
 func (ps mockParameterStore) set(key, value string) {
 	ps[key] = value
 }
@@ -55,6 +73,10 @@ func (m *mockSource) Source(_ context.Context, params []string) (values map[stri
 	return m.ps.getValues(params)
 }
 
+func (m *mockSource) ListKeys(_ context.Context, parts []string) (keys []string, err error) {
+	return m.ps.listKeys(strings.TrimSuffix(makeParameterName(m.path, parts), "/"))
+}
+
 func (m *mockSource) ParameterName(parts []string) string {
 	return makeParameterName(m.path, parts)
 }
@@ -203,6 +225,19 @@ func TestParse(t *testing.T) {
 				return assert.ErrorIs(t, err, ErrParameterNotFound)
 			},
 		},
+		{
+			name: "error if field is not found includes its description",
+			cfg: &struct {
+				Param1 string `sky:"param1,desc:URL of the billing API"`
+			}{},
+			sources: []Source{&mockSource{
+				ps: map[string]string{},
+			}},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrParameterNotFound) &&
+					assert.ErrorContains(t, err, "mock:param1: parameter not found in source (URL of the billing API)")
+			},
+		},
 		{
 			name: "error if field is not found in any source",
 			cfg: &struct {
@@ -236,6 +271,29 @@ func TestParse(t *testing.T) {
 				return assert.ErrorIs(t, err, ErrSourceNotFound)
 			},
 		},
+		{
+			name: "success with version and label",
+			cfg: &struct {
+				Param1 string `sky:"param1,version:2"`
+				Param2 string `sky:"param2,label:stable"`
+			}{},
+			sources: []Source{&mockSource{
+				ps: mockParameterStore{
+					"/path/param1":        "latest-value1",
+					"/path/param1:2":      "value1",
+					"/path/param2":        "latest-value2",
+					"/path/param2:stable": "value2",
+				},
+				path: "/path/",
+			}},
+			wantErr: assert.NoError,
+			want: func(t *testing.T, cfg interface{}) {
+				assert.Equal(t, &struct {
+					Param1 string `sky:"param1,version:2"`
+					Param2 string `sky:"param2,label:stable"`
+				}{Param1: "value1", Param2: "value2"}, cfg)
+			},
+		},
 		{
 			name: "success with multiple sources",
 			cfg:  &multiSourceConfig{},
@@ -318,3 +376,332 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestLazyPointers(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/db/host": "db.example.com",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	type tls struct {
+		Cert string `sky:"cert"`
+		Key  string `sky:"key,default:key.pem"`
+	}
+
+	type db struct {
+		Host string `sky:"host"`
+		TLS  *tls   `sky:"tls"`
+	}
+
+	cfg := &struct {
+		DB  *db  `sky:"db"`
+		TLS *tls `sky:"tls,optional"`
+	}{}
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithLazyPointers())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.NotNil(t, cfg.DB) {
+		assert.Equal(t, "db.example.com", cfg.DB.Host)
+		assert.Nil(t, cfg.DB.TLS)
+	}
+	assert.Nil(t, cfg.TLS)
+
+	// Once one of its fields is found, the struct is set, with its default values
+	source.set("/path/tls/cert", "cert.pem")
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	if assert.NotNil(t, cfg.TLS) {
+		assert.Equal(t, &tls{Cert: "cert.pem", Key: "key.pem"}, cfg.TLS)
+	}
+	assert.Nil(t, cfg.DB.TLS)
+}
+
+func TestAbsoluteKeys(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/app/level":          "info",
+			"/shared/global/feature_x": "true",
+		},
+		path: "/path/app/",
+	}
+
+	cfg := &struct {
+		Level    string `sky:"level"`
+		Features struct {
+			X bool `sky:"/shared/global/feature_x"`
+		} `sky:"features"`
+	}{}
+
+	_, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "info", cfg.Level)
+	assert.True(t, cfg.Features.X)
+
+	str, err := String(cfg, false, false, source)
+	assert.NoError(t, err)
+	assert.Contains(t, str, "anyOf:[ mock:/shared/global/feature_x ]")
+
+	// Absolute keys only name parameters, not trees of them
+	_, err = Parse(context.Background(), &struct {
+		Features struct {
+			X bool `sky:"x"`
+		} `sky:"/shared/features"`
+	}{}, false, source)
+	assert.ErrorIs(t, err, ErrBadTags)
+}
+
+func TestSourceList(t *testing.T) {
+	app := &mockSource{id: "app", path: "/app/", ps: mockParameterStore{"/app/level": "debug"}, refreshable: true}
+	project := &mockSource{id: "project", path: "/project/", refreshable: true, ps: mockParameterStore{
+		"/project/level": "info",
+		"/project/port":  "8080",
+	}}
+	global := &mockSource{id: "global", path: "/global/", refreshable: true, ps: mockParameterStore{
+		"/global/level": "warn",
+		"/global/port":  "80",
+		"/global/host":  "localhost",
+	}}
+
+	type config struct {
+		Level string `sky:"level,source:app|project|global"`
+		Port  int    `sky:"port,source:app|project|global"`
+		Host  string `sky:"host,source:app|project"`
+		Other string `sky:"level"`
+	}
+
+	// Fields fail only if none of the sources listed has the parameter
+	var cfg config
+	_, err := Parse(context.Background(), &cfg, false, global, project, app)
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+	assert.ErrorContains(t, err, "host")
+
+	// The first source listed that has the parameter takes precedence, whatever the order of the sources
+	project.ps["/project/host"] = "db1"
+	for _, sources := range [][]Source{{app, project, global}, {global, project, app}} {
+		cfg = config{}
+		_, err = Parse(context.Background(), &cfg, false, sources...)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "debug", cfg.Level)
+		assert.Equal(t, 8080, cfg.Port)
+		assert.Equal(t, "db1", cfg.Host)
+	}
+
+	// Fields without the tag still take the value of the last source
+	assert.Equal(t, "debug", cfg.Other)
+	cfg = config{}
+	r, err := Parse(context.Background(), &cfg, false, project, app, global)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "warn", cfg.Other)
+
+	// Refreshes follow the same precedence
+	delete(app.ps, "/app/level")
+	project.ps["/project/port"] = "8081"
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, 8081, cfg.Port)
+
+	// Every source listed must be provided
+	_, err = Parse(context.Background(), &cfg, false, project, app)
+	assert.ErrorIs(t, err, ErrSourceNotFound)
+
+	_, err = Parse(context.Background(), &struct {
+		Level string `sky:"level,source:app|"`
+	}{}, false, app)
+	assert.ErrorIs(t, err, ErrBadTags)
+}
+
+func TestValueOutOfRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		cfg   any
+		path  string
+	}{
+		{
+			name:  "int8",
+			value: "300",
+			cfg: &struct {
+				DB struct {
+					Port int8 `sky:"value"`
+				} `sky:"db"`
+			}{},
+			path: "DB.Port",
+		},
+		{
+			name:  "negative uint",
+			value: "-1",
+			cfg: &struct {
+				DB struct {
+					Port uint16 `sky:"value"`
+				} `sky:"db"`
+			}{},
+			path: "DB.Port",
+		},
+		{
+			name:  "float32",
+			value: "1e40",
+			cfg: &struct {
+				DB struct {
+					Ratio float32 `sky:"value"`
+				} `sky:"db"`
+			}{},
+			path: "DB.Ratio",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &mockSource{
+				ps:   mockParameterStore{"/db/value": tt.value},
+				path: "/",
+			}
+
+			_, err := Parse(context.Background(), tt.cfg, false, source)
+			assert.ErrorIs(t, err, ErrValueOutOfRange)
+			assert.ErrorIs(t, err, ErrBadFieldValue)
+			assert.ErrorContains(t, err, tt.path)
+			assert.ErrorContains(t, err, "mock:/db/value")
+		})
+	}
+
+	// Values in range are still accepted
+	cfg := &struct {
+		Port int8 `sky:"port"`
+	}{}
+	_, err := Parse(context.Background(), cfg, false, &mockSource{ps: mockParameterStore{"/port": "-128"}, path: "/"})
+	assert.NoError(t, err)
+	assert.Equal(t, int8(-128), cfg.Port)
+}
+
+func TestAliases(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/db/hostname": "legacy-host",
+			"/path/db/new_port": "5432",
+			"/path/db/port":     "5431",
+			"/shared/db_user":   "admin",
+		},
+		path: "/path/",
+	}
+
+	cfg := &struct {
+		DB struct {
+			Host string `sky:"host,alias:hostname|server"`
+			Port int    `sky:"newPort,alias:port"`
+			User string `sky:"user,alias:/shared/db_user"`
+			Name string `sky:"name,alias:db_name,optional"`
+		} `sky:"db"`
+	}{}
+
+	_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithLogger(logger))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The parameter is preferred to its aliases, which are tried in order
+	assert.Equal(t, "legacy-host", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, "admin", cfg.DB.User)
+	assert.Equal(t, "", cfg.DB.Name)
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="parameter found under a deprecated alias" field=host source=mock parameter=/path/db/hostname replacement=/path/db/host`)
+	assert.Contains(t, out, `parameter=/shared/db_user replacement=/path/db/user`)
+	assert.NotContains(t, out, "parameter=/path/db/port")
+
+	// The error names the parameter rather than its aliases
+	_, err = Parse(context.Background(), &struct {
+		Name string `sky:"name,alias:db_name"`
+	}{}, false, source)
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+	assert.ErrorContains(t, err, "mock:/path/name")
+
+	// An absolute key can only have absolute aliases
+	_, err = Parse(context.Background(), &struct {
+		User string `sky:"/shared/db_user,alias:user"`
+	}{}, false, source)
+	assert.ErrorIs(t, err, ErrBadTags)
+	_, _, err = ParseTag("/shared/db_user,alias:/legacy/db_user|user")
+	assert.ErrorContains(t, err, `relative alias "user" of an absolute key`)
+}
+
+func TestWithPrefix(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/billing/prod/db/host":   "db.internal",
+			"/path/billing/prod/log_level": "debug",
+			"/shared/region":               "eu-west-1",
+		},
+		path: "/path/",
+	}
+
+	var cfg struct {
+		DB struct {
+			Host string `sky:"host"`
+		} `sky:"db"`
+		LogLevel string `sky:"logLevel"`
+		Region   string `sky:"/shared/region"`
+	}
+	_, err := ParseWithOptions(context.Background(), &cfg, []Source{source}, WithPrefix("billing"), WithPrefix("prod"))
+	assert.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.DB.Host)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+}
+
+// largeConfig returns a pointer to a new configuration struct of n fields of various types, and the source of their
+// values.
+func largeConfig(n int) (cfg interface{}, source *mockSource) {
+	types := []struct {
+		t     reflect.Type
+		value string
+	}{
+		{reflect.TypeOf(""), "value"},
+		{reflect.TypeOf(0), "42"},
+		{reflect.TypeOf(time.Duration(0)), "1m30s"},
+		{reflect.TypeOf(false), "true"},
+		{reflect.TypeOf([]int{}), "1;2;3;4;5;6;7;8"},
+		{reflect.TypeOf(map[string]int{}), "a:1;b:2;c:3;d:4"},
+		{reflect.TypeOf([]*string{}), "a;b;c;d"},
+	}
+
+	source = &mockSource{ps: mockParameterStore{}, path: "/app/", refreshable: true}
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		ft := types[i%len(types)]
+		key := fmt.Sprintf("field%d", i)
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: ft.t,
+			Tag:  reflect.StructTag(fmt.Sprintf(`sky:"%s,refresh:1m"`, key)),
+		}
+		source.ps["/app/"+key] = ft.value
+	}
+
+	return reflect.New(reflect.StructOf(fields)).Interface(), source
+}
+
+func BenchmarkParse(b *testing.B) {
+	cfg, source := largeConfig(500)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(context.Background(), cfg, false, source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
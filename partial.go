@@ -0,0 +1,67 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrPartialFetch is matched, using errors.Is, by the errors of sources returning the values of some of the parameters
+// requested, fetched before they failed; see WithPartialResults.
+var ErrPartialFetch = errors.New("parameters partially fetched")
+
+// PartialFetchError is the error returned by a source along with the values of the parameters it fetched before the
+// context was done, when partial results are enabled using WithPartialResults, or of those that did not fail when
+// fetched one by one, as set by WithGetParameterFallback. It matches ErrPartialFetch and the error that stopped the
+// fetch with errors.Is.
+type PartialFetchError struct {
+	// Unfetched are the names of the parameters requested that were not fetched.
+	Unfetched []string
+	// Err is the error that stopped the fetch.
+	Err error
+}
+
+func (e *PartialFetchError) Error() string {
+	return fmt.Sprintf("%s: %d not fetched: %s", ErrPartialFetch, len(e.Unfetched), e.Err)
+}
+
+func (e *PartialFetchError) Unwrap() []error {
+	return []error{ErrPartialFetch, e.Err}
+}
+
+// WithPartialResults makes an SSM source return the values of the parameters it fetched before the context was done,
+// such as when its deadline is exceeded between two batches of parameters, along with a *PartialFetchError listing
+// those it did not fetch, rather than discard them. Parse uses the partial results in best-effort mode, as set by
+// WithBestEffort, so that only the fields whose parameters were not fetched fail or fall back to their defaults.
+func WithPartialResults() SourceOption {
+	return func(o *sourceOptions) {
+		o.partialResults = true
+	}
+}
+
+// partialFetch wraps the error of a fetch stopped before the unfetched parameters in a *PartialFetchError, if partial
+// results are enabled and the fetch was stopped as the context is done; nil if not, the values fetched then being
+// discarded.
+func (s *ssmSource) partialFetch(ctx context.Context, unfetched []string, err error) *PartialFetchError {
+	if !s.partialResults || ctx.Err() == nil {
+		return nil
+	}
+
+	return &PartialFetchError{Unfetched: slices.Clone(unfetched), Err: err}
+}
+
+// unfetchedKeys returns the keys of the parameters not fetched from the source, as reported by a *PartialFetchError.
+func unfetchedKeys(err error) map[string]bool {
+	var pe *PartialFetchError
+	if !errors.As(err, &pe) {
+		return nil
+	}
+
+	unfetched := make(map[string]bool, len(pe.Unfetched))
+	for _, key := range pe.Unfetched {
+		unfetched[key] = true
+	}
+
+	return unfetched
+}
@@ -0,0 +1,96 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// cancellingSSM serves the SSM API using fakeSSM until the given number of calls is made, then cancels the context of
+// the caller, failing the calls.
+type cancellingSSM struct {
+	*fakeSSM
+	calls  int
+	cancel context.CancelFunc
+}
+
+func (c *cancellingSSM) Do(req *http.Request) (*http.Response, error) {
+	if c.calls--; c.calls < 0 {
+		c.cancel()
+		return nil, context.Canceled
+	}
+
+	return c.fakeSSM.Do(req)
+}
+
+func TestPartialResults(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{})
+	var keys []string
+	for i := range 10 {
+		fake.put(fmt.Sprintf("/app/p%d", i), fmt.Sprintf("value%d", i))
+		keys = append(keys, fmt.Sprintf("/app/p%d", i))
+	}
+	fake.put("/app/host", "db1")
+	fake.put("/app/port", "5433")
+	keys = append(keys, "/app/host", "/app/port")
+
+	// newSource returns a source whose context is cancelled after the first batch of parameters is fetched
+	newSource := func(opts ...SourceOption) (Source, context.Context) {
+		ctx, cancel := context.WithCancel(context.Background())
+		client := ssmpkg.New(ssmpkg.Options{
+			Region:      "eu-west-1",
+			Credentials: aws.AnonymousCredentials{},
+			HTTPClient:  &cancellingSSM{fakeSSM: fake, calls: 1, cancel: cancel},
+		})
+		return SSMSourceWithOptions(client, "/app", "ssm", opts...), ctx
+	}
+
+	// The values of the first batch are returned along with the error
+	source, ctx := newSource(WithPartialResults())
+	values, _, err := source.(MetadataSource).SourceWithMetadata(ctx, keys)
+	assert.ErrorIs(t, err, ErrPartialFetch)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, values, 10)
+	assert.Equal(t, "value0", values["/app/p0"])
+	var pe *PartialFetchError
+	if assert.ErrorAs(t, err, &pe) {
+		assert.Equal(t, []string{"/app/host", "/app/port"}, pe.Unfetched)
+	}
+
+	// Without the option, they are discarded
+	source, ctx = newSource()
+	values, _, err = source.(MetadataSource).SourceWithMetadata(ctx, keys)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrPartialFetch)
+	assert.Nil(t, values)
+
+	// In best-effort mode, the fields whose parameters were fetched are set, and the others fall back to their defaults
+	var cfg struct {
+		P0, P1, P2, P3, P4, P5, P6, P7, P8, P9 string
+		Host                                   string `sky:"host,default:localhost"`
+		Port                                   int    `sky:"port,optional"`
+	}
+	var reported error
+	source, ctx = newSource(WithPartialResults())
+	_, err = ParseWithOptions(ctx, &cfg, []Source{source}, WithUntagged(),
+		WithBestEffort(func(_ context.Context, _ string, err error) { reported = err }))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, reported, ErrPartialFetch)
+	assert.Equal(t, "value0", cfg.P0)
+	assert.Equal(t, "value9", cfg.P9)
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Zero(t, cfg.Port)
+
+	// Fields whose parameters were not fetched, and have no default, fail with the error of the source
+	var required struct {
+		P0, P1, P2, P3, P4, P5, P6, P7, P8, P9 string
+		Host                                   string `sky:"host"`
+	}
+	source, ctx = newSource(WithPartialResults())
+	_, err = ParseWithOptions(ctx, &required, []Source{source}, WithUntagged(), WithBestEffort(nil))
+	assert.ErrorIs(t, err, ErrPartialFetch)
+}
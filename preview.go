@@ -0,0 +1,90 @@
+package skyconf
+
+import (
+	"context"
+	"slices"
+)
+
+// PendingChange is a change to the value of a field found in the sources but not applied yet, as returned by
+// Refresher.Preview.
+type PendingChange struct {
+	// ID is the identifier of the field.
+	ID string
+	// Field is the name of the field, qualified with those of the enclosing structs, as in "DB.Port".
+	Field string
+	// Source is the ID of the source the value would be set from; that the value was last set from if deleted.
+	Source string
+	// Parameter is the name of the parameter the value would be set from; that the value was last set from if deleted.
+	Parameter string
+	// Value is the value of the parameter in the source, redacted for fields tagged with `secret`; empty if deleted.
+	Value string
+	// Current is the current value of the field, formatted as by String and redacted for fields tagged with `secret`.
+	Current string
+	// Paused is true if the refresh of the field is paused, in which case the change is not applied until it is
+	// resumed.
+	Paused bool
+	// Deleted is true if the parameter the value was last set from is no longer found in any source.
+	Deleted bool
+}
+
+// Preview fetches the values of all the fields from the refreshable sources, as RefreshNow does, and returns the
+// changes it would make, in the order of the fields, without setting the fields or sending updates.
+func (u *updater) Preview(ctx context.Context) (changes []PendingChange, err error) {
+	fields := make([]fieldInfo, len(u.fields))
+	for i, f := range u.fields {
+		fields[i] = f.field
+	}
+
+	var resolved map[int]resolvedValue
+	resolved, err = resolveValues(ctx, u.opts, u.sources, fields, func(idx int, source Source) bool {
+		if !source.Refreshable() {
+			return false
+		}
+
+		u.m.Lock()
+		defer u.m.Unlock()
+
+		f := u.fields[idx]
+		return (f.source == nil || f.source.Refreshable()) && slices.Contains(f.sources, source)
+	})
+	if err != nil {
+		return
+	}
+
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	for idx, f := range u.fields {
+		rv, ok := resolved[idx]
+		switch {
+		case ok && u.opts.hashValue(rv.value) == f.valueHash:
+			continue
+		case !ok && (f.source == nil || !f.source.Refreshable() || f.deleted || f.field.options.onDelete == "keep"):
+			continue
+		}
+
+		change := PendingChange{
+			ID:      f.field.options.id,
+			Field:   f.field.path,
+			Paused:  f.paused,
+			Deleted: !ok,
+		}
+		if ok {
+			change.Source, change.Parameter, change.Value = rv.source.ID(), rv.key, f.field.logValue(rv.value)
+		} else {
+			change.Source, change.Parameter = f.source.ID(), f.key
+		}
+
+		f.rlocker.Lock()
+		change.Current, err = formatFieldValue(f.field.structField)
+		f.rlocker.Unlock()
+		if err != nil {
+			return
+		}
+		change.Current = f.field.logValue(change.Current)
+
+		changes = append(changes, change)
+	}
+
+	return
+}
@@ -0,0 +1,64 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/app/host":     "db1",
+			"/app/port":     "5432",
+			"/app/password": "secret1",
+			"/app/user":     "admin",
+			"/app/timeout":  "30",
+		},
+		path:        "/app/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Host     string `sky:"host,refresh:1m"`
+		Port     int    `sky:"port"`
+		Password string `sky:"password,secret"`
+		User     string `sky:"user"`
+		Timeout  int    `sky:"timeout"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	changes, err := r.Preview(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	source.ps = mockParameterStore{
+		"/app/host":     "db2",
+		"/app/port":     "5432",
+		"/app/password": "secret2",
+		"/app/timeout":  "60",
+	}
+	assert.NoError(t, r.Pause("timeout"))
+
+	changes, err = r.Preview(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []PendingChange{
+		{ID: "host", Field: "Host", Source: "mock", Parameter: "/app/host", Value: "db2", Current: "db1"},
+		{ID: "password", Field: "Password", Source: "mock", Parameter: "/app/password", Value: redacted,
+			Current: redacted},
+		{ID: "user", Field: "User", Source: "mock", Parameter: "/app/user", Current: "admin", Deleted: true},
+		{ID: "timeout", Field: "Timeout", Source: "mock", Parameter: "/app/timeout", Value: "60", Current: "30",
+			Paused: true},
+	}, changes)
+
+	// Nothing is applied
+	assert.Equal(t, "db1", cfg.Host)
+	assert.Equal(t, "secret1", cfg.Password)
+	assert.Equal(t, "admin", cfg.User)
+	assert.Equal(t, 30, cfg.Timeout)
+	assert.Equal(t, ProvenanceSource, r.Status()[3].Provenance)
+}
@@ -0,0 +1,61 @@
+package skyconf
+
+import (
+	"strings"
+)
+
+// WithProfiles activates the profiles, such as the environment and the region the configuration is parsed for, as in
+// WithProfiles("prod", "eu"). Fields tagged with `profiles` are only populated if one of their profiles is active, and
+// none of the profiles they exclude; see Parse. No profile is active unless set with this option.
+func WithProfiles(profiles ...string) Option {
+	return func(o *options) {
+		o.profiles = append(o.profiles, profiles...)
+	}
+}
+
+// activeProfiles returns the set of the active profiles.
+func (o *options) activeProfiles() map[string]bool {
+	active := make(map[string]bool, len(o.profiles))
+	for _, profile := range o.profiles {
+		active[profile] = true
+	}
+
+	return active
+}
+
+// parseProfiles parses the value of a `profiles` tag, the profiles separated by '|', each optionally negated by '!'.
+// ok is false if any of the profiles is empty.
+func parseProfiles(val string) (profiles []string, ok bool) {
+	profiles = strings.Split(val, "|")
+	for _, profile := range profiles {
+		if strings.TrimPrefix(profile, "!") == "" {
+			return nil, false
+		}
+	}
+
+	return profiles, true
+}
+
+// inProfiles returns true if the field with the profiles is part of the configuration for the active profiles: if
+// none of its profiles, if any, is excluded by being negated, and either one of the others is active or there are no
+// others. All the fields are part of the configuration if the active profiles are not set.
+func inProfiles(active map[string]bool, profiles []string) bool {
+	if active == nil || len(profiles) == 0 {
+		return true
+	}
+
+	included, required := false, false
+	for _, profile := range profiles {
+		if name, negated := strings.CutPrefix(profile, "!"); negated {
+			if active[name] {
+				return false
+			}
+			continue
+		}
+
+		required = true
+		included = included || active[profile]
+	}
+
+	return included || !required
+}
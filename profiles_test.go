@@ -0,0 +1,57 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithProfiles(t *testing.T) {
+	source := &mockSource{id: "ssm", path: "/", ps: mockParameterStore{
+		"/host":            "db1",
+		"/replica_host":    "db2",
+		"/debug":           "true",
+		"/eu/gdpr_contact": "dpo@example.com",
+	}}
+
+	type eu struct {
+		GDPRContact string `sky:"gdpr_contact"`
+	}
+	type config struct {
+		Host        string `sky:"host"`
+		ReplicaHost string `sky:"replica_host,profiles:prod|staging"`
+		Debug       bool   `sky:"debug,profiles:!prod"`
+		EU          *eu    `sky:"eu,profiles:eu"`
+		Metrics     string `sky:"metrics,profiles:prod"`
+	}
+
+	// Only the fields of the active profiles are populated, and required
+	var cfg config
+	_, err := ParseWithOptions(context.Background(), &cfg, []Source{source}, WithProfiles("staging", "eu"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, config{Host: "db1", ReplicaHost: "db2", Debug: true, EU: &eu{GDPRContact: "dpo@example.com"}}, cfg)
+
+	_, err = ParseWithOptions(context.Background(), &config{}, []Source{source}, WithProfiles("prod"))
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+
+	// No profile is active by default
+	cfg = config{}
+	_, err = Parse(context.Background(), &cfg, false, source)
+	assert.NoError(t, err)
+	assert.Equal(t, config{Host: "db1", Debug: true}, cfg)
+
+	// The fields are listed as configured
+	fields, err := Fields(&config{}, WithProfiles("prod"))
+	assert.NoError(t, err)
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"Host", "ReplicaHost", "Metrics"}, paths)
+	assert.Equal(t, []string{"prod", "staging"}, fields[1].Options.Profiles)
+
+	_, _, err = ParseTag("host,profiles:prod||eu")
+	assert.ErrorContains(t, err, `invalid profiles "prod||eu"`)
+}
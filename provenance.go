@@ -0,0 +1,54 @@
+package skyconf
+
+import (
+	"strings"
+)
+
+// Provenance tells where the value of a field comes from; see FieldStatus.
+type Provenance string
+
+const (
+	// ProvenanceSource is the provenance of a value set from a parameter of a source.
+	ProvenanceSource Provenance = "source"
+	// ProvenanceDefault is the provenance of a value set from the `default` or `defaultfrom` tags of the field.
+	ProvenanceDefault Provenance = "default"
+	// ProvenancePreset is the provenance of a value set on the configuration struct before it was parsed, and not
+	// found in any source.
+	ProvenancePreset Provenance = "preset"
+	// ProvenanceZero is the provenance of the zero value of a field not found in any source, such as an optional field,
+	// or reset when its parameter was deleted; see the `ondelete` tag.
+	ProvenanceZero Provenance = "zero"
+)
+
+// String describes the provenance of the value of the field, as in "db_host <- ssm:/app/db/host" for a value set from a
+// source, or "timeout <- (default)" otherwise.
+func (s FieldStatus) String() string {
+	return s.ID + " <- " + s.from()
+}
+
+// from returns where the value of the field comes from, as formatted by String.
+func (s FieldStatus) from() string {
+	from := "(" + string(s.Provenance) + ")"
+	if s.Provenance == ProvenanceSource {
+		from = s.Source + ":" + s.Parameter
+	}
+
+	if s.Stale {
+		from += " (stale)"
+	}
+
+	return from
+}
+
+// Describe returns the provenance of the values of the fields of the configuration of the Refresher, one field per
+// line, in the order of the fields; see FieldStatus.String. It helps telling which of several sources a value is taken
+// from.
+func Describe(r Refresher) string {
+	status := r.Status()
+	lines := make([]string, len(status))
+	for i, s := range status {
+		lines[i] = s.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
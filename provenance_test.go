@@ -0,0 +1,73 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	global := &mockSource{
+		ps:          mockParameterStore{"/global/host": "global-host", "/global/port": "5432"},
+		path:        "/global/",
+		id:          "global",
+		refreshable: true,
+	}
+	local := &mockSource{
+		ps:          mockParameterStore{"/local/port": "6543", "/local/user": "admin"},
+		path:        "/local/",
+		id:          "local",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Host    string `sky:"host"`
+		Port    int    `sky:"port"`
+		User    string `sky:"user,refresh:1m,ondelete:default,default:nobody"`
+		Timeout int    `sky:"timeout,default:30"`
+		Region  string `sky:"region,optional"`
+		Name    string `sky:"name,optional"`
+	}{Name: "preset-name"}
+
+	r, err := Parse(context.Background(), cfg, false, global, local)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	provenances := func() (p []Provenance) {
+		for _, s := range r.Status() {
+			p = append(p, s.Provenance)
+		}
+		return
+	}
+	assert.Equal(t, []Provenance{ProvenanceSource, ProvenanceSource, ProvenanceSource, ProvenanceDefault,
+		ProvenanceZero, ProvenancePreset}, provenances())
+	assert.Equal(t, "host <- global:/global/host\n"+
+		"port <- local:/local/port\n"+
+		"user <- local:/local/user\n"+
+		"timeout <- (default)\n"+
+		"region <- (zero)\n"+
+		"name <- (preset)", Describe(r))
+
+	// The provenance is shown along with the values of the fields
+	str, err := StringWithProvenance(cfg, r, false, true, global, local)
+	if assert.NoError(t, err) {
+		lines := strings.Split(str, "\n")
+		if assert.Len(t, lines, 6) {
+			assert.True(t, strings.HasSuffix(lines[1], " = 6543 <- local:/local/port"), lines[1])
+			assert.True(t, strings.HasSuffix(lines[3], " = 30 <- (default)"), lines[3])
+		}
+	}
+
+	// A parameter deleted from the source is reset to its default value
+	local.ps = mockParameterStore{"/local/port": "6543"}
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "nobody", cfg.User)
+	assert.Equal(t, ProvenanceDefault, r.Status()[2].Provenance)
+
+	// And set from the source again once restored
+	local.set("/local/user", "root")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "user <- local:/local/user", r.Status()[2].String())
+}
@@ -0,0 +1,160 @@
+package skyconf
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// PushSource is implemented by sources whose values are pushed by an external system, e.g. received from a gRPC
+// stream, instead of being polled. While refreshing, the values pushed are applied to the fields whose value was set
+// from the source, whether or not they are tagged with `refresh`, and the updates are notified in the same way as for
+// the values fetched by a refresh.
+type PushSource interface {
+	Source
+	// Updates returns a channel receiving the values pushed, keyed by parameter name, until the context is done.
+	Updates(ctx context.Context) (<-chan map[string]string, error)
+}
+
+// sourcePush is a set of values pushed by a source.
+type sourcePush struct {
+	source Source
+	values map[string]string
+}
+
+// watchPushes forwards the values pushed by the sources that implement PushSource to the returned channel, until the
+// context is done. Errors subscribing to the updates of a source are passed to the error function.
+func (u *updater) watchPushes(ctx context.Context, ef func(err error)) <-chan sourcePush {
+	pushes := make(chan sourcePush)
+
+	for _, source := range u.sources {
+		ps, ok := source.(PushSource)
+		if !ok {
+			continue
+		}
+
+		updates, err := ps.Updates(ctx)
+		if err != nil {
+			ef(err)
+			continue
+		}
+
+		u.wg.Add(1)
+		go func(source Source) {
+			defer u.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case values, ok := <-updates:
+					if !ok {
+						return
+					}
+
+					select {
+					case pushes <- sourcePush{source: source, values: values}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(source)
+	}
+
+	return pushes
+}
+
+// Pusher is a PushSource adapter for external systems pushing values, for example a handler of a gRPC or xDS stream.
+// It serves the last values pushed when parsing, and delivers the values pushed afterward to the refreshers using it.
+type Pusher struct {
+	id          string
+	path        string
+	m           sync.Mutex
+	values      map[string]string
+	subscribers map[chan map[string]string]<-chan struct{}
+}
+
+// NewPushSource creates a new Pusher with the given ID. The parameter names are formed in the same way as for an SSM
+// source with the path, with or without a trailing slash.
+func NewPushSource(id, path string) *Pusher {
+	return &Pusher{
+		id:          id,
+		path:        strings.TrimSuffix(path, "/") + "/",
+		values:      make(map[string]string),
+		subscribers: make(map[chan map[string]string]<-chan struct{}),
+	}
+}
+
+// Push records the values, keyed by parameter name, and delivers them to the refreshers using the source. The values
+// of the parameters not included are left as they are. It blocks until the values are delivered, or the context is
+// done.
+func (p *Pusher) Push(ctx context.Context, values map[string]string) error {
+	p.m.Lock()
+	for k, v := range values {
+		p.values[k] = v
+	}
+
+	subscribers := make(map[chan map[string]string]<-chan struct{}, len(p.subscribers))
+	for ch, done := range p.subscribers {
+		subscribers[ch] = done
+	}
+	p.m.Unlock()
+
+	for ch, done := range subscribers {
+		select {
+		case ch <- values:
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Updates returns a channel receiving the values pushed, until the context is done.
+func (p *Pusher) Updates(ctx context.Context) (<-chan map[string]string, error) {
+	ch := make(chan map[string]string)
+
+	p.m.Lock()
+	p.subscribers[ch] = ctx.Done()
+	p.m.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		p.m.Lock()
+		delete(p.subscribers, ch)
+		p.m.Unlock()
+	}()
+
+	return ch, nil
+}
+
+// Source returns the last values pushed for the keys.
+func (p *Pusher) Source(_ context.Context, keys []string) (values map[string]string, err error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	values = make(map[string]string, len(keys))
+	for _, key := range keys {
+		if v, ok := p.values[key]; ok {
+			values[key] = v
+		}
+	}
+
+	return
+}
+
+func (p *Pusher) ParameterName(parts []string) string {
+	return makeParameterName(p.path, parts)
+}
+
+func (p *Pusher) ID() string {
+	return p.id
+}
+
+// Refreshable returns false; the values of the source are pushed rather than polled.
+func (p *Pusher) Refreshable() bool {
+	return false
+}
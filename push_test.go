@@ -0,0 +1,71 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPushSource(t *testing.T) {
+	// The path is the same with or without a trailing slash
+	pusher := NewPushSource("xds", "/app")
+	assert.Equal(t, NewPushSource("xds", "/app/").ParameterName([]string{"level"}), pusher.ParameterName([]string{"level"}))
+
+	assert.NoError(t, pusher.Push(context.Background(), map[string]string{
+		"/app/level":   "info",
+		"/app/timeout": "1s",
+	}))
+
+	cfg := &struct {
+		Level   string        `sky:"level"`
+		Timeout time.Duration `sky:"timeout"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, pusher)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, time.Second, cfg.Timeout)
+
+	errs := make(chan error, 1)
+	updates := r.Refresh(context.Background(), func(err error) {
+		errs <- err
+	})
+
+	// Wait for the refresher to subscribe to the updates
+	assert.Eventually(t, func() bool {
+		pusher.m.Lock()
+		defer pusher.m.Unlock()
+		return len(pusher.subscribers) == 1
+	}, time.Second, time.Millisecond)
+
+	// Pushed values are applied and notified, although the fields are not tagged with refresh
+	assert.NoError(t, pusher.Push(context.Background(), map[string]string{"/app/level": "debug"}))
+	select {
+	case id := <-updates:
+		assert.Equal(t, "level", id)
+	case <-time.After(time.Second):
+		assert.Fail(t, "pushed value not applied")
+	}
+
+	// Unchanged values are not notified, and invalid values are reported
+	assert.NoError(t, pusher.Push(context.Background(), map[string]string{
+		"/app/level":   "debug",
+		"/app/timeout": "soon",
+	}))
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrBadFieldValue)
+	case id := <-updates:
+		assert.Fail(t, "unexpected update", id)
+	case <-time.After(time.Second):
+		assert.Fail(t, "invalid value not reported")
+	}
+
+	r.Close()
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, time.Second, cfg.Timeout)
+}
@@ -0,0 +1,169 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RedisClient is the subset of a Redis client used by RedisSource. It can be implemented by a thin adapter around any
+// Redis client library.
+type RedisClient interface {
+	// MGet returns the values of the keys, in the same order as the keys; the value of a key that does not exist is nil.
+	MGet(ctx context.Context, keys ...string) ([]interface{}, error)
+	// PSubscribe subscribes to the channels matching the pattern, and returns a channel receiving the name of the
+	// channel of each message published, until the context is done.
+	PSubscribe(ctx context.Context, pattern string) (<-chan string, error)
+}
+
+// keyspaceChannelPrefix is the prefix of the channels keyspace notifications are published to, followed by the
+// database number, "__:" and the key.
+const keyspaceChannelPrefix = "__keyspace@"
+
+type redisSource struct {
+	client  RedisClient
+	prefix  string
+	id      string
+	limiter *limiter
+}
+
+// redisNotifyingSource is a Redis source that notifies changes to its keys using keyspace notifications.
+type redisNotifyingSource struct {
+	*redisSource
+}
+
+// WithKeyspaceNotifications makes a Redis source subscribe to the keyspace notifications of its keys while refreshing,
+// so that fields tagged with `refresh` are refreshed as soon as their key changes. Keyspace notifications must be
+// enabled on the Redis server, for example using `CONFIG SET notify-keyspace-events K$gx`.
+func WithKeyspaceNotifications() SourceOption {
+	return func(o *sourceOptions) {
+		o.keyspaceNotifications = true
+	}
+}
+
+// RedisSource creates a new Redis source reading the keys under the prefix.
+func RedisSource(client RedisClient, prefix string) Source {
+	return RedisSourceWithOptions(client, prefix, "redis")
+}
+
+// RedisSourceWithOptions creates a new Redis source reading the keys under the prefix, with a custom ID, configured
+// using the provided options.
+func RedisSourceWithOptions(client RedisClient, prefix, id string, opts ...SourceOption) Source {
+	// ensure a non-empty prefix ends with a colon
+	if prefix != "" && !strings.HasSuffix(prefix, ":") {
+		prefix += ":"
+	}
+
+	o := makeSourceOptions(opts)
+	s := &redisSource{
+		client:  client,
+		prefix:  prefix,
+		id:      id,
+		limiter: newLimiter(o),
+	}
+
+	if o.keyspaceNotifications {
+		return &redisNotifyingSource{s}
+	}
+
+	return s
+}
+
+// Source fetches the keys using a single MGET command.
+func (s *redisSource) Source(ctx context.Context, keys []string) (values map[string]string, err error) {
+	// Ensure there are keys to fetch
+	if len(keys) == 0 {
+		return
+	}
+
+	// Ensure the redis client is not nil
+	if s.client == nil {
+		err = fmt.Errorf("redis client is nil")
+		return
+	}
+
+	var result []interface{}
+	err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+		result, err = s.client.MGet(ctx, keys...)
+		return
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get keys: %w", err)
+		return
+	}
+
+	values = make(map[string]string, len(keys))
+	for i, v := range result {
+		if i >= len(keys) {
+			break
+		}
+
+		switch v := v.(type) {
+		case nil:
+			// The key does not exist
+		case string:
+			values[keys[i]] = v
+		case []byte:
+			values[keys[i]] = string(v)
+		default:
+			values[keys[i]] = fmt.Sprint(v)
+		}
+	}
+
+	return
+}
+
+// ParameterName joins the parts with a colon after converting them to snake case, following the Redis convention.
+func (s *redisSource) ParameterName(parts []string) string {
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		names[i] = ToSnakeCase(part)
+	}
+
+	return s.prefix + strings.Join(names, ":")
+}
+
+func (s *redisSource) ID() string {
+	return s.id
+}
+
+func (s *redisSource) Refreshable() bool {
+	return true
+}
+
+// Changes subscribes to the keyspace notifications of the keys under the prefix, in any database.
+func (s *redisNotifyingSource) Changes(ctx context.Context) (keys <-chan string, err error) {
+	// Ensure the redis client is not nil
+	if s.client == nil {
+		err = fmt.Errorf("redis client is nil")
+		return
+	}
+
+	var channels <-chan string
+	channels, err = s.client.PSubscribe(ctx, keyspaceChannelPrefix+"*__:"+s.prefix+"*")
+	if err != nil {
+		err = fmt.Errorf("failed to subscribe to keyspace notifications: %w", err)
+		return
+	}
+
+	changed := make(chan string)
+	go func() {
+		defer close(changed)
+		for channel := range channels {
+			// Extract the key from the channel name, __keyspace@<db>__:<key>
+			_, key, ok := strings.Cut(strings.TrimPrefix(channel, keyspaceChannelPrefix), "__:")
+			if !ok {
+				continue
+			}
+
+			select {
+			case changed <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	keys = changed
+	return
+}
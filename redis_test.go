@@ -0,0 +1,126 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a RedisClient storing the keys in memory, publishing a keyspace notification when a key is set.
+type fakeRedis struct {
+	m           sync.Mutex
+	keys        map[string]string
+	subscribers []chan string
+	patterns    []string
+}
+
+func (r *fakeRedis) MGet(_ context.Context, keys ...string) ([]interface{}, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if v, ok := r.keys[key]; ok {
+			values[i] = v
+		}
+	}
+
+	return values, nil
+}
+
+func (r *fakeRedis) PSubscribe(ctx context.Context, pattern string) (<-chan string, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	ch := make(chan string, 10)
+	r.subscribers = append(r.subscribers, ch)
+	r.patterns = append(r.patterns, pattern)
+
+	return ch, nil
+}
+
+func (r *fakeRedis) set(key, value string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.keys[key] = value
+	for _, ch := range r.subscribers {
+		ch <- "__keyspace@0__:" + key
+	}
+}
+
+func TestRedisSource(t *testing.T) {
+	client := &fakeRedis{keys: map[string]string{
+		"app:level":       "info",
+		"app:db:host":     "localhost",
+		"app:db:max_conn": "10",
+	}}
+
+	cfg := &struct {
+		Level string `sky:"level,refresh:1h"`
+		DB    struct {
+			Host    string `sky:"host"`
+			MaxConn int    `sky:"maxConn"`
+		} `sky:"db"`
+	}{}
+
+	source := RedisSourceWithOptions(client, "app", "cache", WithKeyspaceNotifications())
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 10, cfg.DB.MaxConn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := r.Refresh(ctx, func(err error) {
+		assert.NoError(t, err)
+	})
+
+	assert.Eventually(t, func() bool {
+		client.m.Lock()
+		defer client.m.Unlock()
+		return len(client.patterns) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "__keyspace@*__:app:*", client.patterns[0])
+
+	// A change to a refreshed key is applied without waiting for the refresh interval
+	client.set("app:level", "debug")
+	select {
+	case id := <-updates:
+		assert.Equal(t, "level", id)
+	case <-time.After(time.Second):
+		assert.Fail(t, "refresh not triggered by keyspace notification")
+	}
+
+	// Changes to keys that are not refreshed are ignored
+	client.set("app:db:host", "remote")
+	select {
+	case id := <-updates:
+		assert.Fail(t, "unexpected update", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r.Close()
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+}
+
+func TestRedisSourceWithoutNotifications(t *testing.T) {
+	source := RedisSource(&fakeRedis{keys: map[string]string{"level": "info"}}, "")
+
+	_, ok := source.(ChangeNotifier)
+	assert.False(t, ok)
+	assert.Equal(t, "db:max_conn", source.ParameterName([]string{"DB", "MaxConn"}))
+
+	values, err := source.Source(context.Background(), []string{"level", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"level": "info"}, values)
+}
@@ -5,8 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"hash/crc32"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -37,6 +38,59 @@ func (n nilRefresh) RefreshOnce(_ context.Context) (err error) {
 	return
 }
 
+func (n nilRefresh) RefreshNow(_ context.Context) (err error) {
+	return
+}
+
+func (n nilRefresh) Start(_ context.Context) error {
+	return nil
+}
+
+func (n nilRefresh) Close() error {
+	return nil
+}
+
+func (n nilRefresh) Receive(_ context.Context, _ string, _ map[string]string) error {
+	return nil
+}
+
+func (n nilRefresh) Preview(_ context.Context) ([]PendingChange, error) {
+	return nil, nil
+}
+
+func (n nilRefresh) Pending() []PendingChange {
+	return nil
+}
+
+func (n nilRefresh) Apply(ids ...string) error {
+	return n.checkIDs(ids)
+}
+
+func (n nilRefresh) Rollback(id string) error {
+	return n.checkIDs([]string{id})
+}
+
+func (n nilRefresh) Status() []FieldStatus {
+	return nil
+}
+
+func (n nilRefresh) Pause(ids ...string) error {
+	return n.checkIDs(ids)
+}
+
+func (n nilRefresh) Resume(ids ...string) error {
+	return n.checkIDs(ids)
+}
+
+// checkIDs returns an error for the first ID, as there are no fields.
+func (n nilRefresh) checkIDs(ids []string) error {
+	if len(ids) != 0 {
+		return fmt.Errorf("%w: %s", ErrFieldNotFound, ids[0])
+	}
+
+	return nil
+}
+
 // ----------------------------------------------------------------------------
 
 type nilLocker struct{}
@@ -47,47 +101,143 @@ func (n nilLocker) Unlock() {}
 
 var nilLock nilLocker
 
-// ----------------------------------------------------------------------------
+// RLocker is implemented by configuration structs that can be locked for reading, such as structs embedding a
+// sync.RWMutex. The Refresher takes the read lock when it only reads the fields, and the write lock of sync.Locker when
+// it sets them.
+type RLocker interface {
+	RLock()
+	RUnlock()
+}
+
+// readLocker is a sync.Locker taking the read lock of an RLocker.
+type readLocker struct {
+	l RLocker
+}
+
+func (r readLocker) Lock() {
+	r.l.RLock()
+}
 
-type refreshedFieldSource struct {
-	refreshedField
-	Source
+func (r readLocker) Unlock() {
+	r.l.RUnlock()
 }
 
+// WithRLock calls the function with the configuration struct locked for reading, if it implements RLocker, or locked
+// if it only implements sync.Locker, so that fields being refreshed are read consistently.
+func WithRLock[T any](cfg *T, fn func(cfg *T)) {
+	l := readLock(cfg)
+	l.Lock()
+	defer l.Unlock()
+
+	fn(cfg)
+}
+
+// readLock returns the lock to take to read the configuration struct; its read lock if it implements RLocker, or its
+// lock if it implements sync.Locker.
+func readLock(cfg interface{}) sync.Locker {
+	switch l := cfg.(type) {
+	case RLocker:
+		return readLocker{l}
+	case sync.Locker:
+		return l
+	}
+
+	return nilLock
+}
+
+// ----------------------------------------------------------------------------
+
+// refreshedField holds the state of a field: the parameter and the source its value was last set from.
 type refreshedField struct {
 	field     fieldInfo
 	key       string
-	valueHash uint32 // CRC32 of the value
+	source    Source
+	valueHash string     // hash of the value; see WithHash
+	metadata  Metadata   // metadata of the value, if provided by the source
+	origin    Provenance // where the value comes from; the source, unless not set from one
+	stale     bool       // the value was taken from a cache; see WithCache
+	paused    bool
+	deleted   bool         // the parameter was deleted from the source; see remove
+	staged    *stagedValue // the change awaiting approval, if the field is tagged with `manual`; see stage
+	value     string       // the value last set from a source
+	history   []revision   // the values set from the sources before, oldest first; see Rollback
+	rejected  string       // hash of the value rolled back from, not applied again by refreshes; see Rollback
+	flap      flapState    // the recent changes of the field; see WithFlapGuard
+	locker    sync.Locker  // taken to set the field; that of its configuration struct
+	rlocker   sync.Locker  // taken to read the field
+	sources   []Source     // the sources of its configuration struct; see RefresherGroup
 }
 
-// refreshInfo is a struct that holds the information needed to refresh a field.
+// refreshedFields is a group of fields that are refreshed together from a source.
 type refreshedFields struct {
-	fields      []fieldInfo
-	keys        []string
-	valueHashes []uint32 // CRC32 of the values
+	fields  []*refreshedField
+	keys    []string
+	running atomic.Bool // a refresh of the fields at their interval is in flight
 }
 
 // updater is a struct that holds the refresh information for the fields that have opted to be refreshed.
 type updater struct {
-	timings map[time.Duration]map[Source]*refreshedFields
-	raw     []*refreshedFieldSource
-	updates chan string
-	clock   cfclock.Clock
-	locker  sync.Locker
+	timings     map[time.Duration]map[Source]*refreshedFields
+	timingsOnce sync.Once
+	fields      []*refreshedField // all the fields of the configuration struct
+	sources     []Source
+	m           sync.Mutex // guards the state of the fields
+	updates     chan string
+	updatesM    sync.Mutex // guards sending to, and closing of, the updates channel and the watchers
+	closed      bool
+	watchers    map[string][]chan struct{}
+	queue       *updateQueue  // queue of notifications, if they must not be dropped
+	stop        chan struct{} // closed by Close
+	stopOnce    sync.Once
+	wg          sync.WaitGroup // tracks the goroutines started by Refresh
+	clock       cfclock.Clock
+	opts        *options
+	flags       []*Flags // flags kept current with the fields, guarded by m
+	fetches     fetchGroup
+	slots       chan struct{} // bounds the concurrent refreshes; see WithMaxConcurrentRefreshes
 }
 
 var ErrMissingKeyOnRefresh = errors.New("missing key on refresh")
 
+// newUpdater creates an updater for the fields, which are populated from the sources.
+func newUpdater(o *options, sources []Source, fields []fieldInfo) *updater {
+	u := &updater{
+		fields:  make([]*refreshedField, len(fields)),
+		sources: sources,
+		opts:    o,
+		stop:    make(chan struct{}),
+	}
+
+	if o.losslessUpdates {
+		u.queue = newUpdateQueue()
+	}
+	if o.maxRefreshes > 0 {
+		u.slots = make(chan struct{}, o.maxRefreshes)
+	}
+
+	for i, field := range fields {
+		u.fields[i] = &refreshedField{field: field, locker: nilLock, rlocker: nilLock, sources: sources}
+	}
+
+	return u
+}
+
 func (u *updater) setupLock(i interface{}) {
 	if i == nil {
 		return
 	}
 
 	// Check if the interface is a locker
+	var locker sync.Locker = nilLock
 	if l, ok := i.(sync.Locker); ok {
-		u.locker = l
-	} else {
-		u.locker = nilLock
+		locker = l
+	}
+
+	// Check if it can also be locked for reading
+	rlocker := readLock(i)
+
+	for _, f := range u.fields {
+		f.locker, f.rlocker = locker, rlocker
 	}
 }
 
@@ -102,21 +252,38 @@ func (u *updater) Refresh(ctx context.Context, ef func(err error)) <-chan string
 		ef = func(err error) {}
 	}
 
-	// Process the raw list
-	u.processRaw()
+	// Set up the updates channel
+	u.updatesM.Lock()
+	if u.updates == nil {
+		u.updates = make(chan string)
+
+		// Start delivering the queued notifications, if any
+		if u.queue != nil && !u.closed {
+			go u.queue.deliver(u.updates)
+		}
+	}
+	updates := u.updates
+	closed := u.closed
+	u.updatesM.Unlock()
+
+	// If the updater has been closed, there is nothing to do
+	if closed {
+		return updates
+	}
+
+	// Group the fields by their timings
+	u.processTimings()
 
 	// Initialise the clock
 	if u.clock == nil {
-		u.clock = newJitterTickerClock()
+		u.clock = newJitterTickerClock(cfclock.NewClock(), u.opts.jitter, u.opts.aligned)
 	}
 
 	// When a timer ticks, send the ticker-channel to a channel
 	tickChannel := make(chan (<-chan time.Time))
 
-	// Set up the updates channel
-	if u.updates == nil {
-		u.updates = make(chan string)
-	}
+	// Closed when the refresh goroutine returns, to stop the ticker goroutines
+	done := make(chan struct{})
 
 	// Map to keep track of the timings using the ticked channel
 	timings := make(map[<-chan time.Time]map[Source]*refreshedFields, len(u.timings))
@@ -128,10 +295,21 @@ func (u *updater) Refresh(ctx context.Context, ef func(err error)) <-chan string
 	for d, sfMap := range u.timings {
 		ticker := u.clock.NewTicker(d)
 		c := ticker.C()
+		u.wg.Add(1)
 		go func(c <-chan time.Time) {
-			for range c {
-				// Send the channel to tickChannel when the timer ticks
-				tickChannel <- c
+			defer u.wg.Done()
+			for {
+				select {
+				case <-c:
+					// Send the channel to tickChannel when the timer ticks
+					select {
+					case tickChannel <- c:
+					case <-done:
+						return
+					}
+				case <-done:
+					return
+				}
 			}
 		}(c)
 
@@ -142,17 +320,32 @@ func (u *updater) Refresh(ctx context.Context, ef func(err error)) <-chan string
 		tickers = append(tickers, ticker)
 	}
 
+	// Watch the sources that notify changes to their parameters, until the refresh goroutine returns
+	changesCtx, stopChanges := context.WithCancel(ctx)
+	changes := u.watchChanges(changesCtx, ef)
+	pushes := u.watchPushes(changesCtx, ef)
+
 	// Start the refresh goroutine.
+	u.wg.Add(1)
 	go func() {
-		defer close(u.updates)
-		defer close(tickChannel)
+		defer u.wg.Done()
 
-		// Stop tickers when this function returns
-		defer func(tickers []cfclock.Ticker) {
+		// Keep track of the refreshes in flight
+		var inFlight sync.WaitGroup
+
+		defer func() {
+			close(done)
+			stopChanges()
+
+			// Stop tickers when this function returns
 			for _, t := range tickers {
 				t.Stop()
 			}
-		}(tickers)
+
+			// Wait for the refreshes in flight before closing the updates channel
+			inFlight.Wait()
+			u.closeUpdates()
+		}()
 
 		// Loop to refresh fields
 		for {
@@ -161,6 +354,10 @@ func (u *updater) Refresh(ctx context.Context, ef func(err error)) <-chan string
 			case <-ctx.Done():
 				return
 
+			// Check if the updater has been closed
+			case <-u.stop:
+				return
+
 			// Check if any timer has ticked
 			case tc := <-tickChannel: // get the channel that ticked
 				// Get the fields to refresh using the ticked channel
@@ -169,15 +366,87 @@ func (u *updater) Refresh(ctx context.Context, ef func(err error)) <-chan string
 					continue
 				}
 
-				// Refresh the fields
-				for source, fields := range rf {
-					go u.refreshFieldsFromSource(ctx, source, fields, ef)
+				// Refresh the fields along with those of the other intervals firing in the same cycle, if the gate allows
+				// this instance to poll the sources
+				batch := u.batchTicks(ctx, tickChannel, timings, rf)
+				inFlight.Add(1)
+				go func(batch map[Source][]*refreshedFields) {
+					defer inFlight.Done()
+
+					if !u.opts.gate.Allow(ctx) {
+						return
+					}
+
+					var wg sync.WaitGroup
+					for source, groups := range batch {
+						// Skip the fields whose previous refresh is still in flight
+						var running []*refreshedFields
+						for _, fields := range groups {
+							if !fields.running.CompareAndSwap(false, true) {
+								u.opts.logger.DebugContext(ctx, "skipping refresh still in flight", "source", source.ID(),
+									"keys", fields.keys)
+								continue
+							}
+							running = append(running, fields)
+						}
+						if len(running) == 0 {
+							continue
+						}
+
+						wg.Add(1)
+						go func(source Source, running []*refreshedFields) {
+							defer wg.Done()
+							defer func() {
+								for _, fields := range running {
+									fields.running.Store(false)
+								}
+							}()
+							u.refreshFieldsFromSource(ctx, source, mergeRefreshedFields(running), ef)
+						}(source, running)
+					}
+					wg.Wait()
+				}(batch)
+
+			// Check if a parameter of a source has changed
+			case c := <-changes:
+				rf := u.changedFields(c)
+				if len(rf.fields) == 0 {
+					continue
 				}
+
+				inFlight.Add(1)
+				go func() {
+					defer inFlight.Done()
+					u.refreshFieldsFromSource(ctx, c.source, rf, ef)
+				}()
+
+			// Check if values have been pushed by a source
+			case p := <-pushes:
+				inFlight.Add(1)
+				go func() {
+					defer inFlight.Done()
+					if err := u.receive(ctx, p.source, p.values); err != nil {
+						ef(err)
+					}
+				}()
 			}
 		}
 	}()
 
-	return u.updates
+	return updates
+}
+
+// Close stops the refresh started by Refresh, waiting for the refreshes in flight to complete, and closes the updates
+// channel. It returns once everything has been shut down. Close must not be called concurrently with Refresh.
+func (u *updater) Close() error {
+	u.stopOnce.Do(func() {
+		close(u.stop)
+	})
+
+	u.wg.Wait()
+	u.closeUpdates()
+
+	return nil
 }
 
 func (u *updater) RefreshOnce(ctx context.Context) (err error) {
@@ -186,8 +455,8 @@ func (u *updater) RefreshOnce(ctx context.Context) (err error) {
 		return
 	}
 
-	// Process the raw list
-	u.processRaw()
+	// Group the fields by their timings
+	u.processTimings()
 
 	// Create a new context that will be used to cancel the refresh on first error.
 	var cancel context.CancelFunc
@@ -211,78 +480,156 @@ func (u *updater) RefreshOnce(ctx context.Context) (err error) {
 	return
 }
 
-func (u *updater) Updates() <-chan string {
-	return u.updates
+func (u *updater) Start(ctx context.Context) (err error) {
+	if err = u.RefreshOnce(ctx); err != nil {
+		return
+	}
+
+	u.Refresh(ctx, func(err error) {
+		u.opts.logger.WarnContext(ctx, "failed to refresh configuration", "error", err)
+	})
+
+	return
 }
 
-// add adds a field to the updater, into a "raw" list, removing any duplicates.
-func (u *updater) add(field fieldInfo, key string, source Source, value string) (err error) {
-	// If the source is not refreshable, return an error
-	if !source.Refreshable() {
-		return fmt.Errorf("%w: %s", ErrSourceNotRefreshable, source.ID())
+// RefreshNow reloads all the fields of the configuration struct from the refreshable sources, whether tagged with
+// `refresh` or not, respecting the precedence of the sources. Fields whose value was set from a source that is not
+// refreshable are left untouched, as are paused fields and fields not found in any source. It returns the first error
+// that occurs.
+func (u *updater) RefreshNow(ctx context.Context) (err error) {
+	fields := make([]fieldInfo, len(u.fields))
+	for i, f := range u.fields {
+		fields[i] = f.field
 	}
 
-	// CRC32 of the value
-	crc := crc32.ChecksumIEEE([]byte(value))
+	var resolved map[int]resolvedValue
+	resolved, err = resolveValues(ctx, u.opts, u.sources, fields, func(idx int, source Source) bool {
+		if !source.Refreshable() {
+			return false
+		}
+
+		u.m.Lock()
+		defer u.m.Unlock()
+
+		f := u.fields[idx]
+		return !f.paused && (f.source == nil || f.source.Refreshable()) && slices.Contains(f.sources, source)
+	})
+	if err != nil {
+		return
+	}
 
-	// Look through the raw list to see if the field is already added
-	// If already added, replace the key and source
-	for _, rfs := range u.raw {
-		if rfs.field.structField == field.structField {
-			rfs.key = key
-			rfs.Source = source
-			rfs.valueHash = crc
+	// Validate the values that have changed
+	var candidates []candidate
+	for idx, f := range u.fields {
+		if rv, ok := resolved[idx]; ok {
+			candidates = append(candidates, candidate{f: f, source: rv.source, key: rv.key, value: rv.value})
+		}
+	}
+	rejected := u.validate(ctx, candidates)
+
+	// Apply the values in the order of the fields
+	for idx, f := range u.fields {
+		rv, ok := resolved[idx]
+		if !ok {
+			continue
+		}
+
+		if rejectedErr, ok := rejected[f]; ok {
+			err = rejectedErr
+			return
+		}
+		if _, err = u.apply(ctx, f, rv.source, rv.key, rv.value, rv.metadata); err != nil {
+			err = f.field.valueError(StageRefresh, rv.source, rv.key, err)
 			return
 		}
 	}
 
-	// Otherwise, add the field to the raw list
-	u.raw = append(u.raw, &refreshedFieldSource{
-		refreshedField: refreshedField{
-			field:     field,
-			key:       key,
-			valueHash: crc,
-		},
-		Source: source,
-	})
+	return
+}
+
+func (u *updater) Updates() <-chan string {
+	return u.updates
+}
+
+// add records the parameter and the source that the value of the field at index idx was set from.
+func (u *updater) add(idx int, key string, source Source, value string, metadata Metadata) (err error) {
+	f := u.fields[idx]
+
+	// If the field is refreshable and the source is not refreshable, return an error
+	if f.field.options.refresh != 0 && !source.Refreshable() {
+		return fmt.Errorf("%w: %s", ErrSourceNotRefreshable, source.ID())
+	}
+
+	// Replace the key and source of any value set from a previous source
+	f.key = key
+	f.source = source
+	f.origin = ProvenanceSource
+	f.value = value
+	f.valueHash = u.opts.hashValue(value)
+	f.metadata = metadata
 
 	return
 }
 
-func (u *updater) processRaw() {
-	if u.timings == nil {
+// processTimings groups the refreshable fields by their refresh duration and source.
+func (u *updater) processTimings() {
+	u.timingsOnce.Do(func() {
 		u.timings = make(map[time.Duration]map[Source]*refreshedFields)
-	}
 
-	for _, rfs := range u.raw {
-		timing, ok := u.timings[rfs.field.options.refresh]
-		if !ok {
-			u.timings[rfs.field.options.refresh] = make(map[Source]*refreshedFields)
-			timing = u.timings[rfs.field.options.refresh]
+		// Refresh the fields with near-equal intervals together
+		var intervals []time.Duration
+		for _, f := range u.fields {
+			if f.refreshable() && !slices.Contains(intervals, f.field.options.refresh) {
+				intervals = append(intervals, f.field.options.refresh)
+			}
 		}
+		coalesced := u.opts.coalesceIntervals(intervals)
 
-		rf := timing[rfs.Source]
-		if rf == nil {
-			rf = &refreshedFields{}
-			timing[rfs.Source] = rf
-		}
+		for _, f := range u.fields {
+			if !f.refreshable() {
+				continue
+			}
 
-		// Add the field
-		rf.fields = append(rf.fields, rfs.field)
-		rf.keys = append(rf.keys, rfs.key)
-		rf.valueHashes = append(rf.valueHashes, rfs.valueHash)
-	}
+			d := coalesced[f.field.options.refresh]
+			timing, ok := u.timings[d]
+			if !ok {
+				timing = make(map[Source]*refreshedFields)
+				u.timings[d] = timing
+			}
 
-	// Clear the raw list
-	u.raw = nil
+			rf := timing[f.source]
+			if rf == nil {
+				rf = &refreshedFields{}
+				timing[f.source] = rf
+			}
+
+			// Add the field
+			rf.fields = append(rf.fields, f)
+			rf.keys = append(rf.keys, f.key)
+		}
+	})
 }
 
 func (u *updater) refreshFieldsFromSource(ctx context.Context, source Source, rf *refreshedFields, ef func(err error)) {
-	var err error
+	release, ok := u.acquire(ctx)
+	if !ok {
+		return
+	}
+	defer release()
+
+	var err, firstErr error
+
+	// Record the outcome of the refresh when done
+	defer func() {
+		u.opts.metrics.ObserveRefresh(source.ID(), firstErr)
+	}()
 
 	// handleErr will handle the error if err != nil and return true.
 	handleErr := func() bool {
 		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
 			ef(err)
 			err = nil
 			return true
@@ -291,43 +638,69 @@ func (u *updater) refreshFieldsFromSource(ctx context.Context, source Source, rf
 		return false
 	}
 
+	// Collect the fields due a refresh, skipping those paused or whose value has since been set from another source
+	var fields []*refreshedField
+	var keys []string
+	u.m.Lock()
+	for i, f := range rf.fields {
+		if f.paused || f.source != source {
+			continue
+		}
+		fields = append(fields, f)
+		keys = append(keys, rf.keys[i])
+	}
+	u.m.Unlock()
+
+	if len(fields) == 0 {
+		return
+	}
+
 	// Get the values for the keys
 	var values map[string]string
-	values, err = source.Source(ctx, rf.keys)
+	var metadata map[string]Metadata
+	fetchCtx, cancel := u.cycleContext(ctx)
+	defer cancel()
+	start := time.Now()
+	values, metadata, err = u.fetches.fetch(fetchCtx, u.opts, source, keys)
+	if err != nil {
+		if cause := context.Cause(fetchCtx); errors.Is(cause, ErrRefreshTimeout) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
+
+		se := &SourceError{Source: source.ID(), Parameters: keys, Elapsed: time.Since(start), Err: err}
+		for _, f := range fields {
+			se.Fields = append(se.Fields, f.field.path)
+		}
+		err = se
+	}
 	if handleErr() {
 		return
 	}
 
-	// Set the values for the fields
-	for i, field := range rf.fields {
-		if val, ok := values[rf.keys[i]]; ok {
-			// Check if the value has changed
-			crc := crc32.ChecksumIEEE([]byte(val))
-			if crc == rf.valueHashes[i] {
-				continue
-			}
-
-			u.locker.Lock()
-			err = processFieldValue(false, val, field.structField)
-			u.locker.Unlock()
-
-			// If there is no error, update the value hash and notify the updates channel
-			if err == nil {
-				rf.valueHashes[i] = crc
-
-				// Set a timeout for the updates channel
-				tc, cancel := context.WithTimeout(ctx, 500*time.Microsecond)
-
-				// When sending updates, make sure we don't block the goroutine if there are no listeners
-				select {
-				case u.updates <- rf.fields[i].options.id:
-				case <-tc.Done():
-				}
+	// Propagate the values to the instances that do not poll the sources
+	if u.opts.propagate != nil {
+		u.opts.propagate(ctx, source.ID(), values)
+	}
 
-				cancel()
+	// Validate the values that have changed
+	var candidates []candidate
+	for i, f := range fields {
+		if val, ok := values[keys[i]]; ok && !u.opts.isMissing(f.field, val) {
+			candidates = append(candidates, candidate{f: f, source: source, key: keys[i], value: val})
+		}
+	}
+	rejected := u.validate(ctx, candidates)
+
+	// Set the values for the fields, keeping those rejected by the validators
+	for i, f := range fields {
+		if val, ok := values[keys[i]]; ok && !u.opts.isMissing(f.field, val) {
+			if rejectedErr, ok := rejected[f]; ok {
+				err = rejectedErr
+			} else if _, err = u.apply(ctx, f, source, keys[i], val, metadata[keys[i]]); err != nil {
+				err = f.field.valueError(StageRefresh, source, keys[i], err)
 			}
 		} else {
-			err = fmt.Errorf("%w: %s", ErrMissingKeyOnRefresh, rf.keys[i])
+			err = u.remove(ctx, f, source, keys[i])
 		}
 
 		handleErr()
@@ -339,6 +712,266 @@ func (u *updater) refreshFieldsFromSource(ctx context.Context, source Source, rf
 	}
 }
 
+// apply sets the value of the field if it has changed since it was last set, and notifies the updates channel, unless
+// the change is staged until approved or suppressed as the field is flapping. It returns true if the value was changed.
+func (u *updater) apply(ctx context.Context, f *refreshedField, source Source, key, value string,
+	metadata Metadata) (changed bool, err error) {
+
+	if u.isRejected(f, value) {
+		return
+	}
+	if f.field.options.manual && u.stage(ctx, f, source, key, value, metadata) {
+		return
+	}
+	if u.suppress(ctx, f, source, key, value) {
+		return
+	}
+
+	return u.set(ctx, f, source, key, value, metadata, true)
+}
+
+// set sets the value of the field if it has changed since it was last set, and notifies the updates channel. If record
+// is true, the previous value is recorded in the history of the field. It returns true if the value was changed.
+func (u *updater) set(ctx context.Context, f *refreshedField, source Source, key, value string,
+	metadata Metadata, record bool) (changed bool, err error) {
+
+	hash := u.opts.hashValue(value)
+
+	// Transform the value if it has changed, without holding the locks, as transformers may be slow, such as to
+	// decrypt it
+	var decoded string
+	u.m.Lock()
+	if hash != f.valueHash {
+		u.m.Unlock()
+		decoded, err = u.opts.transform(ctx, f.field, value)
+		u.m.Lock()
+	}
+
+	oldHash := f.valueHash
+	previous := revision{source: f.source, key: f.key, value: f.value, metadata: f.metadata}
+	f.rejected = ""
+	f.key = key
+	f.source = source
+	f.origin = ProvenanceSource
+	f.metadata = metadata
+	f.stale = false
+	f.deleted = false
+
+	// Check if the value has changed, or has been set by another refresh meanwhile
+	if hash == f.valueHash {
+		u.m.Unlock()
+		err = nil
+		return
+	}
+
+	var same bool
+	if err == nil {
+		f.locker.Lock()
+		same, err = u.opts.setFieldValue(decoded, f.field)
+		if err == nil && !same {
+			f.field.allocate()
+			for _, flags := range u.flags {
+				flags.store(f.field)
+			}
+		}
+		f.locker.Unlock()
+	}
+
+	// If there is no error, update the value hash, and record the previous value
+	if err == nil {
+		f.valueHash = hash
+		f.value = value
+		changed = !same
+		if record && oldHash != "" {
+			u.record(f, previous)
+		}
+	}
+	u.m.Unlock()
+
+	if !changed {
+		return
+	}
+
+	u.opts.metrics.FieldUpdated(f.field.options.id)
+	u.opts.logger.DebugContext(ctx, "refreshed field value",
+		"field", f.field.options.id, "source", source.ID(), "parameter", key, "value", f.field.logValue(value))
+	u.audit(ctx, f, StageRefresh, source, key, oldHash, value, false)
+
+	u.notify(ctx, f.field.options.id)
+
+	return
+}
+
+// remove handles the deletion of the parameter of the field from the source, according to the `ondelete` tag of the
+// field: it returns an error wrapping ErrMissingKeyOnRefresh, leaves the field untouched, or resets it to its zero or
+// default value and notifies the updates channel, once until the parameter is set again.
+func (u *updater) remove(ctx context.Context, f *refreshedField, source Source, key string) (err error) {
+	switch f.field.options.onDelete {
+	case "", "error":
+		return f.field.fieldError(StageRefresh, source, key, ErrMissingKeyOnRefresh)
+	case "keep":
+		return
+	}
+
+	u.m.Lock()
+	if f.deleted {
+		u.m.Unlock()
+		return
+	}
+
+	f.locker.Lock()
+	f.field.structField.SetZero()
+	f.origin = ProvenanceZero
+	if f.field.options.onDelete == "default" && f.field.options.defaultValue != "" {
+		err = f.field.decode(f.field.options.defaultValue)
+		f.origin = ProvenanceDefault
+	}
+	for _, flags := range u.flags {
+		flags.store(f.field)
+	}
+	f.locker.Unlock()
+
+	// Set the value again when the parameter is restored, even if unchanged
+	oldHash := f.valueHash
+	f.deleted = true
+	f.valueHash = ""
+	u.m.Unlock()
+
+	if err != nil {
+		return f.field.fieldError(StageRefresh, source, key, fmt.Errorf("%w of type %s: %w", ErrBadDefaultFieldValue,
+			f.field.structField.Type(), err))
+	}
+
+	u.opts.metrics.FieldUpdated(f.field.options.id)
+	u.opts.logger.InfoContext(ctx, "reset field deleted from source", "field", f.field.options.id,
+		"source", source.ID(), "parameter", key, "ondelete", f.field.options.onDelete)
+	u.audit(ctx, f, StageRefresh, source, key, oldHash, "", true)
+
+	u.notify(ctx, f.field.options.id)
+
+	return
+}
+
+// notify sends the field ID to the updates channel, without blocking if there are no listeners.
+func (u *updater) notify(ctx context.Context, id string) {
+	u.updatesM.Lock()
+	defer u.updatesM.Unlock()
+
+	if u.closed {
+		return
+	}
+
+	// Wake up the watchers of the field; a pending wake-up is enough for a watcher to read the latest value.
+	for _, w := range u.watchers[id] {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+
+	// Queue the notification if it must not be dropped
+	if u.queue != nil {
+		u.queue.push(id)
+		return
+	}
+
+	if u.updates == nil {
+		return
+	}
+
+	// Set a timeout for the updates channel
+	tc, cancel := context.WithTimeout(ctx, 500*time.Microsecond)
+	defer cancel()
+
+	// When sending updates, make sure we don't block the goroutine if there are no listeners
+	select {
+	case u.updates <- id:
+	case <-tc.Done():
+	}
+}
+
+// closeUpdates closes the updates channel and the channels of the watchers.
+func (u *updater) closeUpdates() {
+	u.updatesM.Lock()
+	defer u.updatesM.Unlock()
+
+	if u.closed {
+		return
+	}
+
+	// Stop delivering the queued notifications before closing the channel; delivery starts with the channel.
+	if u.queue != nil {
+		u.queue.stop(u.updates != nil)
+	}
+
+	if u.updates == nil {
+		u.updates = make(chan string)
+	}
+	close(u.updates)
+
+	for _, ws := range u.watchers {
+		for _, w := range ws {
+			close(w)
+		}
+	}
+	u.watchers = nil
+	u.closed = true
+}
+
+// Pause stops refreshing the fields with the given IDs, or all the fields if no IDs are given, until they are resumed.
+func (u *updater) Pause(ids ...string) error {
+	return u.setPaused(true, ids)
+}
+
+// Resume resumes refreshing the fields with the given IDs, or all the fields if no IDs are given.
+func (u *updater) Resume(ids ...string) error {
+	return u.setPaused(false, ids)
+}
+
+func (u *updater) setPaused(paused bool, ids []string) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	// Ensure all the IDs are known before changing anything
+	for _, id := range ids {
+		found := false
+		for _, f := range u.fields {
+			if f.field.options.id == id {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%w: %s", ErrFieldNotFound, id)
+		}
+	}
+
+	for _, f := range u.fields {
+		if len(ids) == 0 || slices.Contains(ids, f.field.options.id) {
+			f.paused = paused
+		}
+	}
+
+	return nil
+}
+
+// refreshable returns true if the field is tagged with `refresh` and its value was set from a source.
+func (f *refreshedField) refreshable() bool {
+	return f.field.options.refresh != 0 && f.source != nil
+}
+
+// empty returns true if there are no fields to refresh periodically, or to update from the values pushed by a source.
 func (u *updater) empty() bool {
-	return len(u.raw) == 0
+	for _, f := range u.fields {
+		if f.refreshable() {
+			return false
+		}
+
+		if _, ok := f.source.(PushSource); ok {
+			return false
+		}
+	}
+
+	return true
 }
@@ -4,7 +4,10 @@ import (
 	"code.cloudfoundry.org/clock/fakeclock"
 	"context"
 	"github.com/stretchr/testify/assert"
+	"os"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -198,6 +201,9 @@ func TestParseAndRefreshUsingMockClock(t *testing.T) {
 					return
 				}
 
+				// Wait for the refreshes to complete, as ticks are skipped while they are in flight
+				waitIdle(t, r)
+
 				// Update the values in the sources
 				sources[0].(*mockSource).set("/path/global/param1", "new2-global-value1")
 				sources[0].(*mockSource).set("/path/global/param2", "new2-global-value2")
@@ -212,6 +218,9 @@ func TestParseAndRefreshUsingMockClock(t *testing.T) {
 					return
 				}
 
+				// Wait for the refreshes to complete, as ticks are skipped while they are in flight
+				waitIdle(t, r)
+
 				// Update the values in the sources
 				sources[0].(*mockSource).set("/path/global/param1", "new3-global-value1")
 				sources[0].(*mockSource).set("/path/global/param2", "new3-global-value2")
@@ -277,3 +286,400 @@ func TestParseAndRefreshUsingMockClock(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshNow(t *testing.T) {
+	global := &mockSource{
+		ps: mockParameterStore{
+			"/path/global/param1": "global-value1",
+			"/path/global/param2": "global-value2",
+		},
+		path:        "/path/global/",
+		id:          "global",
+		refreshable: true,
+	}
+	static := &mockSource{
+		ps: mockParameterStore{
+			"/path/static/param3": "static-value3",
+		},
+		path: "/path/static/",
+		id:   "static",
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1m"`
+		Param2 string
+		Param3 string `sky:",source:static"`
+		Param4 string `sky:",optional"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, true, global, static)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	global.set("/path/global/param1", "new-global-value1")
+	global.set("/path/global/param2", "new-global-value2")
+	global.set("/path/global/param4", "new-global-value4")
+	static.set("/path/static/param3", "new-static-value3")
+
+	// RefreshOnce only refreshes the fields tagged with refresh
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "new-global-value1", cfg.Param1)
+	assert.Equal(t, "global-value2", cfg.Param2)
+
+	// RefreshNow refreshes all the fields, except those from sources that are not refreshable
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.Equal(t, "new-global-value1", cfg.Param1)
+	assert.Equal(t, "new-global-value2", cfg.Param2)
+	assert.Equal(t, "static-value3", cfg.Param3)
+	assert.Equal(t, "new-global-value4", cfg.Param4)
+
+	// Errors fetching from a source are reported
+	global.ps = nil
+	assert.ErrorIs(t, r.RefreshNow(context.Background()), errInvalidSource)
+}
+
+func TestRefreshOnSignal(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/path/param1": "value1"},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Param1 string
+	}{}
+	r, err := Parse(context.Background(), cfg, true, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := make(chan os.Signal)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		refreshOnSignal(ctx, r, nil, c)
+	}()
+
+	source.set("/path/param1", "new-value1")
+	c <- syscall.SIGHUP
+	c <- syscall.SIGHUP // the first signal has been processed once the second one is received
+
+	cancel()
+	<-done
+
+	assert.Equal(t, "new-value1", cfg.Param1)
+}
+
+// blockingSource blocks fetching values, after the first fetch, until released.
+type blockingSource struct {
+	*mockSource
+	fetches int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSource) Source(ctx context.Context, params []string) (map[string]string, error) {
+	if atomic.AddInt32(&b.fetches, 1) > 1 {
+		b.entered <- struct{}{}
+		<-b.release
+	}
+
+	return b.mockSource.Source(ctx, params)
+}
+
+func TestClose(t *testing.T) {
+	source := &blockingSource{
+		mockSource: &mockSource{
+			ps:          mockParameterStore{"/path/param1": "value1"},
+			path:        "/path/",
+			refreshable: true,
+		},
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1s"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	clock := fakeclock.NewFakeClock(time.Now())
+	r.(*updater).clock = clock
+
+	updates := r.Refresh(context.Background(), nil)
+
+	// Trigger a refresh and wait until it is in flight
+	source.set("/path/param1", "value2")
+	clock.WaitForWatcherAndIncrement(time.Second + time.Millisecond)
+	<-source.entered
+
+	closed := make(chan struct{})
+	go func() {
+		assert.NoError(t, r.Close())
+		close(closed)
+	}()
+
+	// Close waits for the refresh in flight
+	select {
+	case <-closed:
+		assert.Fail(t, "closed before the refresh in flight completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(source.release)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for close")
+		return
+	}
+
+	// The updates channel is closed once the pending update has been delivered or dropped
+	for range updates {
+	}
+
+	assert.Equal(t, "value2", cfg.Param1)
+
+	// Refreshing after closing returns a closed channel
+	_, ok := <-r.Refresh(context.Background(), nil)
+	assert.False(t, ok)
+	assert.NoError(t, r.Close())
+}
+
+func TestPauseResume(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/param1": "value1",
+			"/path/param2": "value2",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1m"`
+		Param2 string `sky:",refresh:1m"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.ErrorIs(t, r.Pause("unknown"), ErrFieldNotFound)
+	assert.NoError(t, r.Pause("Param1"))
+
+	source.set("/path/param1", "new-value1")
+	source.set("/path/param2", "new-value2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.Equal(t, "value1", cfg.Param1)
+	assert.Equal(t, "new-value2", cfg.Param2)
+
+	// Pausing all the fields
+	assert.NoError(t, r.Pause())
+	source.set("/path/param2", "newer-value2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "new-value2", cfg.Param2)
+
+	assert.ErrorIs(t, r.Resume("Param1", "unknown"), ErrFieldNotFound)
+	assert.NoError(t, r.Resume("Param1"))
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "new-value1", cfg.Param1)
+	assert.Equal(t, "new-value2", cfg.Param2)
+
+	assert.NoError(t, r.Resume())
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "newer-value2", cfg.Param2)
+}
+
+type rwConfig struct {
+	Param1 string `sky:",refresh:1s"`
+	sync.RWMutex
+	reads, writes atomic.Int32
+}
+
+func (c *rwConfig) Lock() {
+	c.writes.Add(1)
+	c.RWMutex.Lock()
+}
+
+func (c *rwConfig) RLock() {
+	c.reads.Add(1)
+	c.RWMutex.RLock()
+}
+
+func TestReadLock(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/path/param1": "value1"},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &rwConfig{}
+	r, err := Parse(context.Background(), cfg, true, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Setting fields takes the write lock
+	source.set("/path/param1", "value2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, int32(1), cfg.writes.Load())
+
+	// Reading fields takes the read lock
+	_, err = NewFlags(r)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), cfg.reads.Load())
+
+	WithRLock(cfg, func(cfg *rwConfig) {
+		assert.Equal(t, "value2", cfg.Param1)
+	})
+	assert.Equal(t, int32(2), cfg.reads.Load())
+	assert.Equal(t, int32(1), cfg.writes.Load())
+
+	// Structs that are only lockable are locked
+	locked := &config{}
+	WithRLock(locked, func(cfg *config) {
+		assert.False(t, locked.m.TryLock())
+	})
+	assert.True(t, locked.m.TryLock())
+}
+
+func TestOnDelete(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/error":   "1",
+			"/path/keep":    "2",
+			"/path/zero":    "3",
+			"/path/default": "4",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Error   int `sky:"error,refresh:1m"`
+		Keep    int `sky:"keep,refresh:1m,ondelete:keep"`
+		Zero    int `sky:"zero,refresh:1m,ondelete:zero"`
+		Default int `sky:"default,refresh:1m,ondelete:default,default:10"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	zero, err := Watch[int](r, "zero")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	delete(source.ps, "/path/keep")
+	delete(source.ps, "/path/zero")
+	delete(source.ps, "/path/default")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 1, cfg.Error)
+	assert.Equal(t, 2, cfg.Keep)
+	assert.Equal(t, 0, cfg.Zero)
+	assert.Equal(t, 10, cfg.Default)
+
+	select {
+	case v := <-zero:
+		assert.Equal(t, 0, v)
+	case <-time.After(time.Second):
+		assert.Fail(t, "no update sent for the deleted field")
+	}
+
+	// The deletion is reported for the fields with the error policy, the default one
+	delete(source.ps, "/path/error")
+	err = r.RefreshOnce(context.Background())
+	assert.ErrorIs(t, err, ErrMissingKeyOnRefresh)
+	var fe *FieldError
+	if assert.ErrorAs(t, err, &fe) {
+		assert.Equal(t, "Error", fe.Field)
+	}
+	assert.Equal(t, 1, cfg.Error)
+
+	// The parameter is restored with its previous value
+	source.set("/path/error", "1")
+	source.set("/path/zero", "3")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 3, cfg.Zero)
+
+	// Invalid policies are rejected
+	_, err = Parse(context.Background(), &struct {
+		Param int `sky:"param,ondelete:ignore"`
+	}{}, false, source)
+	assert.ErrorIs(t, err, ErrBadTags)
+}
+
+func TestStart(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/path/param1": "value1"},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1s"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	clock := fakeclock.NewFakeClock(time.Now())
+	r.(*updater).clock = clock
+
+	// A failing first refresh is returned, without starting the background refresh
+	source.ps = nil
+	assert.ErrorIs(t, r.Start(context.Background()), errInvalidSource)
+	assert.Zero(t, clock.WatcherCount())
+
+	// Otherwise, the configuration is refreshed before returning, then in the background
+	source.ps = mockParameterStore{"/path/param1": "value2"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, r.Start(ctx))
+	assert.Equal(t, "value2", cfg.Param1)
+
+	updates, err := Watch[string](r, "Param1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	source.set("/path/param1", "value3")
+	clock.WaitForWatcherAndIncrement(time.Second + time.Millisecond)
+	select {
+	case v := <-updates:
+		assert.Equal(t, "value3", v)
+	case <-time.After(time.Second):
+		assert.Fail(t, "not refreshed in the background")
+	}
+}
+
+// waitIdle waits until none of the refreshes of the refresher are in flight.
+func waitIdle(t *testing.T, r Refresher) {
+	u := r.(*updater)
+	assert.Eventually(t, func() bool {
+		for _, sfMap := range u.timings {
+			for _, rf := range sfMap {
+				if rf.running.Load() {
+					return false
+				}
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+}
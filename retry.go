@@ -0,0 +1,73 @@
+package skyconf
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy sets how fetching the parameters from a source is retried when parsing.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first; no retries are made if it is less than 2.
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time waited between attempts; it is not capped if 0.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows by after each retry; 2 if less than 1.
+	Multiplier float64
+}
+
+// WithRetry makes parsing retry fetching the parameters from a source when it fails, backing off between attempts as
+// set by the policy, until the context is done. This helps when the sources are not yet reachable at startup.
+func WithRetry(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// backoff returns the time to wait before the given retry, starting from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		d *= multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+
+	return time.Duration(d)
+}
+
+// fetchWithRetry fetches the values of the keys from the source, retrying as set by the retry policy. It gives up
+// early, returning the last error, if the context would be done before the next attempt.
+func (o *options) fetchWithRetry(ctx context.Context, source Source, keys []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
+	for attempt := 1; ; attempt++ {
+		values, metadata, err = o.fetch(ctx, source, keys)
+		if err == nil || attempt >= o.retry.MaxAttempts {
+			return
+		}
+
+		d := o.retry.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return
+		}
+
+		o.logger.DebugContext(ctx, "retrying fetching parameters",
+			"source", source.ID(), "attempt", attempt, "backoff", d, "error", err)
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
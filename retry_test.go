@@ -0,0 +1,84 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// flakySource is a mock source that fails the first fetches.
+type flakySource struct {
+	*mockSource
+	failures int
+	attempts int
+}
+
+func (f *flakySource) Source(ctx context.Context, params []string) (map[string]string, error) {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return nil, errMockSourceError
+	}
+
+	return f.mockSource.Source(ctx, params)
+}
+
+func TestWithRetry(t *testing.T) {
+	type config struct {
+		Param1 string `sky:"param1"`
+	}
+
+	newSource := func(failures int) *flakySource {
+		return &flakySource{
+			mockSource: &mockSource{ps: mockParameterStore{"/path/param1": "value1"}, path: "/path/"},
+			failures:   failures,
+		}
+	}
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		source := newSource(2)
+		cfg := &config{}
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithRetry(policy))
+		assert.NoError(t, err)
+		assert.Equal(t, "value1", cfg.Param1)
+		assert.Equal(t, 3, source.attempts)
+	})
+
+	t.Run("gives up after the maximum attempts", func(t *testing.T) {
+		source := newSource(3)
+		_, err := ParseWithOptions(context.Background(), &config{}, []Source{source}, WithRetry(policy))
+		assert.ErrorIs(t, err, errMockSourceError)
+		assert.Equal(t, 3, source.attempts)
+	})
+
+	t.Run("gives up before the deadline", func(t *testing.T) {
+		source := newSource(3)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := ParseWithOptions(ctx, &config{}, []Source{source},
+			WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second}))
+		assert.ErrorIs(t, err, errMockSourceError)
+		assert.Equal(t, 1, source.attempts)
+	})
+
+	t.Run("no retries by default", func(t *testing.T) {
+		source := newSource(1)
+		_, err := ParseWithOptions(context.Background(), &config{}, []Source{source})
+		assert.ErrorIs(t, err, errMockSourceError)
+		assert.Equal(t, 1, source.attempts)
+	})
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, p.backoff(3))
+	assert.Equal(t, time.Second, p.backoff(5))
+
+	p = RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 3}
+	assert.Equal(t, 900*time.Millisecond, p.backoff(3))
+}
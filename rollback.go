@@ -0,0 +1,92 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// defaultHistory is the number of previous values kept for each field, unless set with WithHistory.
+const defaultHistory = 3
+
+// ErrNoHistory is returned by Refresher.Rollback when the field has no previous value to roll back to.
+var ErrNoHistory = errors.New("no previous value to roll back to")
+
+// WithHistory sets the number of previous values kept for each field changed by a refresh, which Refresher.Rollback can
+// restore; 3 by default, 0 to disable the rollback.
+func WithHistory(n int) Option {
+	return func(o *options) {
+		o.history = max(n, 0)
+	}
+}
+
+// revision is a value set in a field from a parameter of a source.
+type revision struct {
+	source   Source
+	key      string
+	value    string
+	metadata Metadata
+}
+
+// record appends the previous value of the field to its history, dropping the oldest values beyond the bound. The
+// lock of the updater must be held.
+func (u *updater) record(f *refreshedField, previous revision) {
+	if u.opts.history == 0 {
+		return
+	}
+
+	f.history = append(f.history, previous)
+	if n := len(f.history) - u.opts.history; n > 0 {
+		f.history = append(f.history[:0], f.history[n:]...)
+	}
+}
+
+// isRejected returns true if the value is the one the field was rolled back from, and the parameter has not changed
+// since.
+func (u *updater) isRejected(f *refreshedField, value string) bool {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	return f.rejected != "" && f.rejected == u.opts.hashValue(value)
+}
+
+// Rollback restores the previous value of the field with the given ID.
+func (u *updater) Rollback(id string) (err error) {
+	ctx := context.Background()
+
+	u.m.Lock()
+	var f *refreshedField
+	for _, rf := range u.fields {
+		if rf.field.options.id == id {
+			f = rf
+			break
+		}
+	}
+	if f == nil {
+		u.m.Unlock()
+		return fmt.Errorf("%w: %s", ErrFieldNotFound, id)
+	}
+	if len(f.history) == 0 {
+		u.m.Unlock()
+		return fmt.Errorf("%w: %s", ErrNoHistory, id)
+	}
+
+	previous := f.history[len(f.history)-1]
+	f.history = f.history[:len(f.history)-1]
+	rejected := f.valueHash
+	u.m.Unlock()
+
+	u.opts.logger.InfoContext(ctx, "rolling back field value", "field", id, "source", previous.source.ID(),
+		"parameter", previous.key, "value", f.field.logValue(previous.value))
+
+	if _, err = u.set(ctx, f, previous.source, previous.key, previous.value, previous.metadata, false); err != nil {
+		return f.field.valueError(StageRefresh, previous.source, previous.key, err)
+	}
+
+	// Keep the refreshes from applying the value rolled back from again
+	u.m.Lock()
+	f.rejected = rejected
+	u.m.Unlock()
+
+	return
+}
@@ -0,0 +1,81 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRollback(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/app/rate": "10", "/app/name": "a"},
+		path:        "/app/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Rate int    `sky:"rate,refresh:1m"`
+		Name string `sky:"name,refresh:1m"`
+	}{}
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithHistory(2), WithLosslessUpdates())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.ErrorIs(t, r.Rollback("rate"), ErrNoHistory)
+	assert.ErrorIs(t, r.Rollback("unknown"), ErrFieldNotFound)
+
+	for _, rate := range []string{"20", "30", "40"} {
+		source.set("/app/rate", rate)
+		assert.NoError(t, r.RefreshOnce(context.Background()))
+	}
+	assert.Equal(t, 40, cfg.Rate)
+
+	updates := r.Refresh(context.Background(), nil)
+	defer r.Close()
+
+	received := func() string {
+		select {
+		case id := <-updates:
+			return id
+		case <-time.After(time.Second):
+			return ""
+		}
+	}
+
+	// The rollback restores the previous value, and sends an update
+	assert.NoError(t, r.Rollback("rate"))
+	assert.Equal(t, 30, cfg.Rate)
+	assert.Equal(t, "rate", received())
+
+	// The value rolled back from is not applied again by the refreshes
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 30, cfg.Rate)
+
+	// The history is bounded
+	assert.NoError(t, r.Rollback("rate"))
+	assert.Equal(t, 20, cfg.Rate)
+	assert.Equal(t, "rate", received())
+	assert.ErrorIs(t, r.Rollback("rate"), ErrNoHistory)
+
+	// A new value of the parameter is applied
+	source.set("/app/rate", "50")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 50, cfg.Rate)
+	assert.Equal(t, "rate", received())
+
+	// Rolling back to the value the parameter is back to
+	source.set("/app/rate", "60")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "rate", received())
+	assert.NoError(t, r.Rollback("rate"))
+	assert.Equal(t, 50, cfg.Rate)
+	assert.Equal(t, "rate", received())
+	source.set("/app/rate", "50")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	source.set("/app/rate", "60")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 60, cfg.Rate)
+}
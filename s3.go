@@ -0,0 +1,143 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	s3pkg "github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type s3Source struct {
+	s3      *s3pkg.Client
+	bucket  string
+	key     string
+	format  DocumentFormat
+	id      string
+	limiter *limiter
+
+	m      sync.Mutex
+	etag   string            // ETag of the object last downloaded
+	values map[string]string // the document last downloaded, flattened
+}
+
+// S3Source creates a new source with the ID "s3" reading parameters from a JSON or YAML document stored in S3.
+func S3Source(s3 *s3pkg.Client, bucket, key string, format DocumentFormat) Source {
+	return S3SourceWithOptions(s3, bucket, key, format, "s3")
+}
+
+// S3SourceWithOptions creates a new source with a custom ID reading parameters from a JSON or YAML document stored in
+// S3, configured using the provided options.
+//
+// The document is flattened into parameters named after the path of each value in the document, with the parts
+// separated by '/'; a parameter is named after the parts of the name of a field, in snake case, in the same way as
+// for SSM. Lists of values, such as [1, 2], are joined with ';'; the items of lists of objects are named after their
+// index instead. The document is only downloaded again on refresh if it has changed, as told by its ETag.
+func S3SourceWithOptions(s3 *s3pkg.Client, bucket, key string, format DocumentFormat, id string,
+	opts ...SourceOption) Source {
+
+	return &s3Source{
+		s3:      s3,
+		bucket:  bucket,
+		key:     key,
+		format:  format,
+		id:      id,
+		limiter: newLimiter(makeSourceOptions(opts)),
+	}
+}
+
+func (s *s3Source) Source(ctx context.Context, keys []string) (values map[string]string, err error) {
+	// Ensure there are keys to fetch
+	if len(keys) == 0 {
+		return
+	}
+
+	var document map[string]string
+	if document, err = s.document(ctx); err != nil {
+		return
+	}
+
+	values = documentValues(document, keys)
+	return
+}
+
+// ListKeys lists the parameters of the document under the path formed by the parts.
+func (s *s3Source) ListKeys(ctx context.Context, parts []string) (keys []string, err error) {
+	var document map[string]string
+	if document, err = s.document(ctx); err != nil {
+		return
+	}
+
+	keys = documentKeys(document, s.ParameterName(parts))
+	return
+}
+
+func (s *s3Source) ParameterName(parts []string) string {
+	return makeParameterName("", parts)
+}
+
+func (s *s3Source) ID() string {
+	return s.id
+}
+
+func (s *s3Source) Refreshable() bool {
+	return true
+}
+
+// document returns the flattened document, downloading it only if it has changed since it was last downloaded.
+func (s *s3Source) document(ctx context.Context) (document map[string]string, err error) {
+	// Ensure the s3 client is not nil
+	if s.s3 == nil {
+		err = fmt.Errorf("s3 client is nil")
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	input := &s3pkg.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	}
+	if s.etag != "" {
+		input.IfNoneMatch = aws.String(s.etag)
+	}
+
+	var output *s3pkg.GetObjectOutput
+	var data []byte
+	err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+		if output, err = s.s3.GetObject(ctx, input); err != nil {
+			return
+		}
+		defer output.Body.Close()
+
+		// Read the object before the timeout of the request is cancelled, as the body is streamed
+		if data, err = io.ReadAll(output.Body); err != nil {
+			err = fmt.Errorf("failed to read object: %w", err)
+		}
+		return
+	})
+
+	// If the object has not changed, use the document last downloaded
+	var re *awshttp.ResponseError
+	if errors.As(err, &re) && re.HTTPStatusCode() == http.StatusNotModified {
+		return s.values, nil
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get object: %w", err)
+		return
+	}
+
+	if document, err = flattenDocument(data, s.format); err != nil {
+		return
+	}
+
+	s.etag = aws.ToString(output.ETag)
+	s.values = document
+
+	return
+}
@@ -0,0 +1,123 @@
+package skyconf
+
+import (
+	"bytes"
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3pkg "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an http client serving a single object, honouring If-None-Match, and counting the downloads.
+type fakeS3 struct {
+	m         sync.Mutex
+	path      string
+	body      string
+	version   int
+	downloads int
+}
+
+func (f *fakeS3) client() *s3pkg.Client {
+	return s3pkg.New(s3pkg.Options{
+		Region:       "eu-west-1",
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   f,
+		UsePathStyle: true,
+	})
+}
+
+func (f *fakeS3) put(body string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.body = body
+	f.version++
+}
+
+func (f *fakeS3) Do(req *http.Request) (*http.Response, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if req.URL.Path != f.path {
+		return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: http.NoBody}, nil
+	}
+
+	etag := `"` + strconv.Itoa(f.version) + `"`
+	if req.Header.Get("If-None-Match") == etag {
+		return &http.Response{StatusCode: http.StatusNotModified, Header: http.Header{}, Body: http.NoBody}, nil
+	}
+
+	f.downloads++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": []string{etag}},
+		Body:       io.NopCloser(contextReader{ctx: req.Context(), r: bytes.NewReader([]byte(f.body))}),
+	}, nil
+}
+
+// contextReader fails reads once the context is done, as the body of a response streamed by a transport does.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+func TestS3Source(t *testing.T) {
+	fake := &fakeS3{path: "/bucket/config.json"}
+	fake.put(`{
+		"level": "info",
+		"db": {"host": "localhost", "port": 5432},
+		"hosts": ["a", "b"],
+		"backends": [{"url": "http://one"}, {"url": "http://two"}],
+		"empty": null
+	}`)
+
+	type backend struct {
+		URL string `sky:"url"`
+	}
+
+	cfg := &struct {
+		Level string `sky:"level,refresh:1m"`
+		DB    struct {
+			Host string `sky:"host"`
+			Port int    `sky:"port"`
+		} `sky:"db"`
+		Hosts    []string  `sky:"hosts"`
+		Backends []backend `sky:"backends"`
+	}{}
+
+	source := S3SourceWithOptions(fake.client(), "bucket", "config.json", FormatJSON, "s3",
+		WithRequestTimeout(time.Minute))
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, []backend{{URL: "http://one"}, {URL: "http://two"}}, cfg.Backends)
+
+	// The object is not downloaded again unless it has changed
+	downloads := fake.downloads
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, downloads, fake.downloads)
+
+	fake.put(`{"level": "debug", "db": {"host": "localhost", "port": 5432}}`)
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, downloads+1, fake.downloads)
+	assert.Equal(t, "debug", cfg.Level)
+}
@@ -0,0 +1,45 @@
+package skyconf
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RefreshOnSignal starts a new goroutine that reloads the whole configuration using RefreshNow each time one of the
+// signals is received, until the context is cancelled. If no signals are provided, SIGHUP is used, allowing a reload to
+// be forced using `kill -HUP`. If an error occurs, the provided error function is called. If no error function is
+// provided, the error is ignored.
+func RefreshOnSignal(ctx context.Context, r Refresher, ef func(err error), sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+
+	go func() {
+		defer signal.Stop(c)
+		refreshOnSignal(ctx, r, ef, c)
+	}()
+}
+
+// refreshOnSignal calls RefreshNow each time a signal is received on the channel, until the context is cancelled.
+func refreshOnSignal(ctx context.Context, r Refresher, ef func(err error), c <-chan os.Signal) {
+	// If the error function is nil, set it to an empty function.
+	if ef == nil {
+		ef = func(err error) {}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c:
+			if err := r.RefreshNow(ctx); err != nil {
+				ef(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package skyconf
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fetchCall is a fetch in flight, or completed, of the parameters of a source.
+type fetchCall struct {
+	wg       sync.WaitGroup
+	dups     int // the number of callers waiting for the fetch, besides the one making it
+	values   map[string]string
+	metadata map[string]Metadata
+	err      error
+}
+
+// fetchGroup deduplicates the fetches of the same parameters from the same source made concurrently, so that a
+// RefreshOnce called during a scheduled refresh, or tickers firing together, do not request the parameters twice.
+type fetchGroup struct {
+	m     sync.Mutex
+	calls map[string]*fetchCall
+}
+
+// fetch fetches the values of the keys from the source, unless a fetch of the same keys from the source is in flight,
+// in which case its result is returned once done. The result is then shared by the callers and must not be modified;
+// it is also subject to the context of the caller that made the fetch.
+func (g *fetchGroup) fetch(ctx context.Context, o *options, source Source, keys []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	key := source.ID() + "\x00" + strings.Join(sorted, "\x00")
+
+	g.m.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*fetchCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.m.Unlock()
+		c.wg.Wait()
+		return c.values, c.metadata, c.err
+	}
+
+	c := &fetchCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.m.Unlock()
+
+	defer func() {
+		g.m.Lock()
+		delete(g.calls, key)
+		g.m.Unlock()
+		c.wg.Done()
+	}()
+
+	c.values, c.metadata, c.err = o.fetch(ctx, source, keys)
+	return c.values, c.metadata, c.err
+}
@@ -0,0 +1,78 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedSource is a source whose fetches all block until released, counting them.
+type gatedSource struct {
+	mockSource
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (b *gatedSource) Source(ctx context.Context, params []string) (values map[string]string, err error) {
+	b.calls.Add(1)
+	<-b.release
+	return b.mockSource.Source(ctx, params)
+}
+
+func TestFetchGroup(t *testing.T) {
+	source := &gatedSource{
+		mockSource: mockSource{
+			ps:          mockParameterStore{"/path/a": "a", "/path/b": "b"},
+			path:        "/path/",
+			refreshable: true,
+		},
+		release: make(chan struct{}),
+	}
+
+	var g fetchGroup
+	o := makeOptions(nil)
+
+	// Concurrent fetches of the same keys, in any order, are made once
+	var wg sync.WaitGroup
+	results := make([]map[string]string, 3)
+	for i, keys := range [][]string{{"/path/a", "/path/b"}, {"/path/b", "/path/a"}, {"/path/a", "/path/b"}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, _ = g.fetch(context.Background(), o, source, keys)
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		g.m.Lock()
+		defer g.m.Unlock()
+		for _, c := range g.calls {
+			return c.dups == 2
+		}
+		return false
+	}, time.Second, time.Millisecond)
+
+	// A fetch of other keys is not deduplicated
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _, _ = g.fetch(context.Background(), o, source, []string{"/path/a"})
+	}()
+	assert.Eventually(t, func() bool { return source.calls.Load() == 2 }, time.Second, time.Millisecond)
+
+	close(source.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), source.calls.Load())
+	for _, values := range results {
+		assert.Equal(t, map[string]string{"/path/a": "a", "/path/b": "b"}, values)
+	}
+	assert.Empty(t, g.calls)
+
+	// Once done, the parameters are fetched again
+	_, _, _ = g.fetch(context.Background(), o, source, []string{"/path/a", "/path/b"})
+	assert.Equal(t, int32(3), source.calls.Load())
+}
@@ -0,0 +1,109 @@
+package skyconftest
+
+import (
+	"errors"
+	"github.com/redmatter/go-skyconf"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes AssertSchema write the golden files rather than compare them, when
+// set to a true value, as in:
+//
+//	SKYCONFTEST_UPDATE=1 go test ./...
+const UpdateEnv = "SKYCONFTEST_UPDATE"
+
+// AssertSchema renders the schema of the configuration struct, as given by skyconf.Fields with the options, and
+// compares it with the golden file, failing the test if they differ. It catches the accidental changes to the tags and
+// the names of the fields that would change the parameters looked up in production. The golden file is written instead
+// when the environment variable UpdateEnv is set, or when it does not exist yet.
+//
+// The schema has a line per field, in the order of the fields, giving its path, the names of its parameter in a source
+// whose path is "/", including its aliases, its type, and the options of its tags that affect how its value is looked
+// up and set, as in:
+//
+//	DB.Port: /db/port (int) id=port default="5432" refresh=1m0s
+func AssertSchema(t testing.TB, cfg interface{}, goldenPath string, opts ...skyconf.Option) bool {
+	t.Helper()
+
+	schema, err := Schema(cfg, opts...)
+	if err != nil {
+		t.Errorf("failed to render the schema of %T: %v", cfg, err)
+		return false
+	}
+
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	golden, err := os.ReadFile(goldenPath)
+	switch {
+	case update || errors.Is(err, fs.ErrNotExist):
+		if err = os.MkdirAll(filepath.Dir(goldenPath), 0o755); err == nil {
+			err = os.WriteFile(goldenPath, []byte(schema), 0o644)
+		}
+		if err != nil {
+			t.Errorf("failed to write the golden file %s: %v", goldenPath, err)
+			return false
+		}
+		return true
+	case err != nil:
+		t.Errorf("failed to read the golden file %s: %v", goldenPath, err)
+		return false
+	}
+
+	return assert.Equal(t, string(golden), schema,
+		"schema of %T differs from %s; rerun the test with %s=1 to update it", cfg, goldenPath, UpdateEnv)
+}
+
+// Schema renders the schema of the configuration struct compared by AssertSchema.
+func Schema(cfg interface{}, opts ...skyconf.Option) (schema string, err error) {
+	var fields []skyconf.Field
+	if fields, err = skyconf.Fields(cfg, opts...); err != nil {
+		return
+	}
+
+	source := MapSource(nil)
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(f.Path + ": " + strings.Join(f.Parameters(source), "|") + " (" + f.Type.String() + ")")
+		sb.WriteString(" id=" + f.ID)
+
+		o := f.Options
+		attrs := []struct {
+			name  string
+			value string
+			set   bool
+		}{
+			{"source", o.Source, o.Source != ""},
+			{"default", strconv.Quote(o.Default), o.Default != ""},
+			{"defaultfrom", o.DefaultFrom, o.DefaultFrom != ""},
+			{"refresh", o.Refresh.String(), o.Refresh != 0},
+			{"optional", "", o.Optional},
+			{"secret", "", o.Secret},
+			{"manual", "", o.Manual},
+			{"allowempty", "", o.AllowEmpty},
+			{"subtree", "", f.Subtree},
+			{"ondelete", o.OnDelete, o.OnDelete != ""},
+			{"transform", strings.Join(o.Transform, "|"), len(o.Transform) > 0},
+			{"encoding", o.Encoding, o.Encoding != ""},
+			{"when", o.When, o.When != ""},
+			{"profiles", strings.Join(o.Profiles, "|"), len(o.Profiles) > 0},
+			{"deprecated", "", o.Deprecated != ""},
+		}
+		for _, attr := range attrs {
+			switch {
+			case !attr.set:
+			case attr.value == "":
+				sb.WriteString(" " + attr.name)
+			default:
+				sb.WriteString(" " + attr.name + "=" + attr.value)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,71 @@
+package skyconftest
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type schemaConfig struct {
+	DB struct {
+		Host string `sky:"host,alias:hostname,refresh:1m,ondelete:keep"`
+		Port int    `sky:"port,default:5432"`
+	} `sky:"db"`
+	Password string            `sky:"password,secret,source:secrets,transform:base64"`
+	Region   string            `sky:"/shared/region,optional"`
+	Timeout  time.Duration     `sky:"timeout,id:request_timeout,manual"`
+	Labels   map[string]string `sky:"labels,deprecated"`
+	Tenants  map[string]struct {
+		Name string `sky:"name"`
+	} `sky:"tenants"`
+}
+
+// recorder is a testing.TB recording the errors of the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSchema(t *testing.T) {
+	assert.True(t, AssertSchema(t, &schemaConfig{}, "testdata/schema.golden"))
+
+	// A change to a parameter name fails the assertion
+	var renamed struct {
+		DB struct {
+			Host string `sky:"hostname"`
+		} `sky:"db"`
+	}
+	r := &recorder{TB: t}
+	assert.False(t, AssertSchema(r, &renamed, "testdata/schema.golden"))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "-DB.Host: /db/host|/db/hostname (string) id=host refresh=1m0s ondelete=keep")
+		assert.Contains(t, r.errors[0], "+DB.Host: /db/hostname (string) id=hostname")
+		assert.Contains(t, r.errors[0], "rerun the test with SKYCONFTEST_UPDATE=1 to update it")
+	}
+
+	// A missing golden file is written, as it is when updating
+	golden := filepath.Join(t.TempDir(), "config", "schema.golden")
+	assert.True(t, AssertSchema(t, &renamed, golden))
+	b, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, "DB.Host: /db/hostname (string) id=hostname\n", string(b))
+
+	t.Setenv(UpdateEnv, "true")
+	assert.True(t, AssertSchema(t, &schemaConfig{}, golden))
+	b, err = os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "Password: /password (string) id=password source=secrets secret transform=base64\n")
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertSchema(r, schemaConfig{}, golden))
+	assert.Len(t, r.errors, 1)
+}
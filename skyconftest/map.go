@@ -0,0 +1,129 @@
+// Package skyconftest provides helpers to test code configured with skyconf: an in-memory source whose values can be
+// changed by the tests, and a way to drive refreshes without waiting for the refresh intervals.
+package skyconftest
+
+import (
+	"context"
+	"github.com/redmatter/go-skyconf"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Map is an in-memory, refreshable source backed by a map of parameter values, keyed by parameter name. It is safe
+// for concurrent use, so its values can be changed while the configuration is refreshed.
+type Map struct {
+	id     string
+	path   string
+	m      sync.Mutex
+	values map[string]string
+	err    error
+}
+
+// MapSource returns a Map with the ID "map" and the values, whose parameter names are formed in the same way as for
+// an SSM source with the path "/"; the value of a field keyed "db" then "host" is that of "/db/host". The map is
+// copied.
+func MapSource(values map[string]string) *Map {
+	return MapSourceWithID("map", "/", values)
+}
+
+// MapSourceWithID returns a Map with the ID and the values, whose parameter names are formed in the same way as for an
+// SSM source with the path. The map is copied.
+func MapSourceWithID(id, path string, values map[string]string) *Map {
+	m := &Map{id: id, path: path, values: make(map[string]string, len(values))}
+	for k, v := range values {
+		m.values[k] = v
+	}
+
+	return m
+}
+
+// Set sets the value of the parameter.
+func (m *Map) Set(name, value string) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.values[name] = value
+}
+
+// Delete deletes the parameter.
+func (m *Map) Delete(name string) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	delete(m.values, name)
+}
+
+// Fail makes the fetches from the source fail with the error, until called with nil.
+func (m *Map) Fail(err error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.err = err
+}
+
+// Source returns the values of the parameters found, or the error set with Fail.
+func (m *Map) Source(_ context.Context, params []string) (values map[string]string, err error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	values = make(map[string]string, len(params))
+	for _, name := range params {
+		if value, ok := m.values[name]; ok {
+			values[name] = value
+		}
+	}
+
+	return
+}
+
+// ListKeys returns the names of the parameters under the path formed by the parts, relative to it.
+func (m *Map) ListKeys(_ context.Context, parts []string) (keys []string, err error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	prefix := strings.TrimSuffix(m.ParameterName(parts), "/") + "/"
+	for name := range m.values {
+		if key, ok := strings.CutPrefix(name, prefix); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+// ParameterName joins the parts with '/' after converting them to snake case, prefixed with the path of the source.
+func (m *Map) ParameterName(parts []string) string {
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		names[i] = skyconf.ToSnakeCase(part)
+	}
+
+	return m.path + strings.Join(names, "/")
+}
+
+// Refreshable returns true.
+func (m *Map) Refreshable() bool {
+	return true
+}
+
+// ID returns the ID of the source.
+func (m *Map) ID() string {
+	return m.id
+}
+
+// Tick refreshes the fields tagged with `refresh` once, as if all their refresh intervals had elapsed, and returns the
+// first error that occurred. It lets tests apply the values changed in the sources deterministically, rather than wait
+// for the refresh intervals.
+func Tick(ctx context.Context, r skyconf.Refresher) error {
+	return r.RefreshOnce(ctx)
+}
@@ -0,0 +1,71 @@
+package skyconftest
+
+import (
+	"context"
+	"errors"
+	"github.com/redmatter/go-skyconf"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMapSource(t *testing.T) {
+	values := map[string]string{
+		"/db/host":           "db1",
+		"/log_level":         "info",
+		"/tenants/acme/name": "Acme",
+	}
+	source := MapSource(values)
+
+	cfg := &struct {
+		DB struct {
+			Host string `sky:"host,refresh:1h"`
+		} `sky:"db"`
+		LogLevel string `sky:"logLevel,refresh:1h,ondelete:default,default:warn"`
+		Tenants  map[string]struct {
+			Name string `sky:"name"`
+		} `sky:"tenants"`
+	}{}
+
+	r, err := skyconf.Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "db1", cfg.DB.Host)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "Acme", cfg.Tenants["acme"].Name)
+
+	// The values of the source are a copy
+	values["/db/host"] = "changed"
+	assert.NoError(t, Tick(context.Background(), r))
+	assert.Equal(t, "db1", cfg.DB.Host)
+
+	// The changes are applied on the next tick
+	source.Set("/db/host", "db2")
+	source.Delete("/log_level")
+	assert.Equal(t, "db1", cfg.DB.Host)
+	assert.NoError(t, Tick(context.Background(), r))
+	assert.Equal(t, "db2", cfg.DB.Host)
+	assert.Equal(t, "warn", cfg.LogLevel)
+
+	// The fetches fail until the error is cleared
+	errUnavailable := errors.New("unavailable")
+	source.Fail(errUnavailable)
+	source.Set("/db/host", "db3")
+	assert.ErrorIs(t, Tick(context.Background(), r), errUnavailable)
+	assert.Equal(t, "db2", cfg.DB.Host)
+	source.Fail(nil)
+	assert.NoError(t, Tick(context.Background(), r))
+	assert.Equal(t, "db3", cfg.DB.Host)
+}
+
+func TestMapSourceWithID(t *testing.T) {
+	source := MapSourceWithID("overrides", "/app/", nil)
+	assert.Equal(t, "overrides", source.ID())
+	assert.Equal(t, "/app/db/max_conns", source.ParameterName([]string{"db", "MaxConns"}))
+	assert.True(t, source.Refreshable())
+
+	source.Set("/app/db/max_conns", "10")
+	values, err := source.Source(context.Background(), []string{"/app/db/max_conns", "/app/db/host"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/app/db/max_conns": "10"}, values)
+}
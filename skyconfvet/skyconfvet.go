@@ -0,0 +1,186 @@
+// Package skyconfvet defines an Analyzer checking the `sky` tags of the structs of a package statically, so that tag
+// errors are caught when vetting the code rather than by Parse at runtime. It reports:
+//
+//   - tags that ParseTag rejects: unknown options, options missing their value, such as an empty default or source,
+//     refresh intervals that do not parse, and conflicting options;
+//   - `flatten` on fields that are not structs;
+//   - fields of a configuration struct sharing the same ID, whether given by the `id` tag or defaulting to their key.
+//
+// The analyzer is run by the skyconfvet command, either on its own or as a vet tool:
+//
+//	go vet -vettool=$(which skyconfvet) ./...
+package skyconfvet
+
+import (
+	"github.com/redmatter/go-skyconf"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Analyzer checks the `sky` tags of the structs of a package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "skyconfvet",
+	Doc:      "check the sky tags of skyconf configuration structs",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		s, ok := pass.TypesInfo.TypeOf(st).(*types.Struct)
+		if !ok {
+			return
+		}
+
+		checkTags(pass, st)
+		checkIDs(pass, st, s)
+	})
+
+	return nil, nil
+}
+
+// checkTags reports the problems of the `sky` tags of the fields of the struct.
+func checkTags(pass *analysis.Pass, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		tag, ok := skyTag(field)
+		if !ok {
+			continue
+		}
+
+		_, options, err := skyconf.ParseTag(tag)
+		if err != nil {
+			pass.Reportf(field.Tag.Pos(), "bad sky tag %q: %v", tag, err)
+			continue
+		}
+
+		if t := pass.TypesInfo.TypeOf(field.Type); options.Flatten && configStruct(t) == nil {
+			pass.Reportf(field.Tag.Pos(), "flatten on a field of type %s, not a struct", t)
+		}
+	}
+}
+
+// skyTag returns the value of the `sky` tag of the field; false if it has none, or is ignored using "-".
+func skyTag(field *ast.Field) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+
+	tags, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+
+	tag, ok := reflect.StructTag(tags).Lookup("sky")
+	return tag, ok && tag != "-"
+}
+
+// leaf is a field populated from a parameter, found in the tree of structs of a field of a configuration struct.
+type leaf struct {
+	id    string
+	path  string
+	field int // the index of the field of the configuration struct the leaf is found under
+}
+
+// checkIDs reports the fields of the configuration struct whose IDs are those of other fields. Each duplicate is only
+// reported in the innermost struct declaring both fields, at the field leading to the second one.
+func checkIDs(pass *analysis.Pass, st *ast.StructType, s *types.Struct) {
+	// The positions of the fields, several of which may be declared together
+	var positions []token.Pos
+	for _, field := range st.Fields.List {
+		for range max(len(field.Names), 1) {
+			positions = append(positions, field.Pos())
+		}
+	}
+	if len(positions) != s.NumFields() {
+		return
+	}
+
+	seen := make(map[string]leaf)
+	for i := 0; i < s.NumFields(); i++ {
+		for _, l := range leaves(s.Field(i), s.Tag(i), "", []types.Type{s}) {
+			l.field = i
+			other, ok := seen[l.id]
+			switch {
+			case !ok:
+				seen[l.id] = l
+			case other.field != i:
+				pass.Reportf(positions[i], "duplicate field id %q of %s, also that of %s", l.id, l.path, other.path)
+			}
+		}
+	}
+}
+
+// leaves returns the fields populated from parameters found under the field with the tag, recursing into structs.
+func leaves(v *types.Var, tags, prefix string, stack []types.Type) (found []leaf) {
+	tag, ok := reflect.StructTag(tags).Lookup("sky")
+	if !ok || tag == "-" || !v.Exported() {
+		return
+	}
+
+	key, options, err := skyconf.ParseTag(tag)
+	if err != nil {
+		return
+	}
+
+	path := v.Name()
+	if prefix != "" {
+		path = prefix + "." + path
+	}
+
+	// The fields of a nested struct are the leaves, unless the struct is recursive
+	if s := configStruct(v.Type()); s != nil {
+		if slices.ContainsFunc(stack, func(t types.Type) bool { return types.Identical(t, s) }) {
+			return
+		}
+		for i := 0; i < s.NumFields(); i++ {
+			found = append(found, leaves(s.Field(i), s.Tag(i), path, append(slices.Clip(stack), s))...)
+		}
+		return
+	}
+
+	id := options.ID
+	if id == "" {
+		id = key
+	}
+	if id == "" {
+		id = v.Name()
+	}
+
+	return []leaf{{id: id, path: path}}
+}
+
+// configStruct returns the struct the type is, or points to, if its fields are populated from parameters; nil if it is
+// not a struct, or if it decodes itself from a value, implementing skyconf.Setter, encoding.TextUnmarshaler or
+// encoding.BinaryUnmarshaler.
+func configStruct(t types.Type) *types.Struct {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+
+	s, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	methods := types.NewMethodSet(types.NewPointer(t))
+	for _, name := range []string{"Set", "UnmarshalText", "UnmarshalBinary"} {
+		if methods.Lookup(nil, name) != nil {
+			return nil
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,30 @@
+package a
+
+import (
+	"net/url"
+	"time"
+)
+
+type Config struct {
+	Host    string        `sky:"host,optinal"`         // want `bad sky tag "host,optinal": unknown tag option "optinal"`
+	Timeout time.Duration `sky:"timeout,refresh:soon"` // want `bad sky tag "timeout,refresh:soon": invalid duration "soon"`
+	Region  string        `sky:"region,default"`       // want `bad sky tag "region,default": tag "default" missing a value`
+	Source  string        `sky:"source,source:"`       // want `bad sky tag "source,source:": tag "source" missing a value`
+	Port    int           `sky:"port,flatten"`         // want `flatten on a field of type int, not a struct`
+	URL     url.URL       `sky:"url"`
+	Since   time.Time     `sky:"since,flatten"` // want `flatten on a field of type time.Time, not a struct`
+	DB      DB            `sky:"db,flatten"`
+	Cache   *Cache        `sky:"cache"` // want `duplicate field id "url" of Cache.URL, also that of URL`
+	Ignored string        `sky:"-"`
+	Name    string
+}
+
+type DB struct {
+	User     string `sky:"user"`
+	Username string `sky:"username,id:user"` // want `duplicate field id "user" of Username, also that of User`
+}
+
+type Cache struct {
+	URL  string `sky:"url"`
+	Next *Cache `sky:"next,id:next"`
+}
@@ -1,15 +1,92 @@
 package skyconf
 
 import (
-	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 )
 
-var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
-var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
+// maxSnakeCases is the number of results of ToSnakeCase memoised, beyond which the results are no longer memoised, so
+// that converting arbitrary strings does not grow the memo without bounds.
+const maxSnakeCases = 4096
 
+// snakeCases memoises the results of ToSnakeCase, keyed by input; the keys of a configuration are few and converted
+// over and over, for every source and refresh. snakeCasesLen is the number of results memoised.
+var (
+	snakeCases    sync.Map
+	snakeCasesLen atomic.Int64
+)
+
+// verbatimMarker prefixes the parts of the keys that are used as they are rather than converted to snake case, such as
+// the keys of the entries of subtrees, as listed in the sources.
+const verbatimMarker = "\x00"
+
+// verbatim marks the part of a key to be kept as is by ToSnakeCase.
+func verbatim(part string) string {
+	return verbatimMarker + part
+}
+
+// unmarked returns the part of a key without its verbatim marker, if any.
+func unmarked(part string) string {
+	return strings.TrimPrefix(part, verbatimMarker)
+}
+
+// ToSnakeCase converts the key of a field, such as a Go field name, to snake case, which is how the keys are named in
+// the sources by default: "CoreAPIBaseURL" becomes "core_api_base_url".
+//
+// Words start at an uppercase letter following a lowercase letter or a digit, as in "baseURL", and at an uppercase
+// letter followed by a lowercase letter, unless it starts the string, as in "APIBase". Digits belong to the word they
+// follow, so that "HTTP2Enabled" becomes "http2_enabled" and "S3Bucket" "s3_bucket". Letters are recognised in any
+// script. Any other character is kept as is; an underscore is still added after it before a word, so that
+// "Field_Name" becomes "field__name" and "Image.Id" "image._id", as the keys have always been named. The keys of the
+// entries of subtrees, as listed in the sources, are kept as they are.
 func ToSnakeCase(str string) string {
-	snake := matchFirstCap.ReplaceAllString(str, "${1}_${2}")
-	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}")
-	return strings.ToLower(snake)
+	if part, ok := strings.CutPrefix(str, verbatimMarker); ok {
+		return part
+	}
+
+	if snake, ok := snakeCases.Load(str); ok {
+		return snake.(string)
+	}
+
+	snake := toSnakeCase(str)
+	if snakeCasesLen.Load() < maxSnakeCases {
+		if _, loaded := snakeCases.LoadOrStore(str, snake); !loaded {
+			snakeCasesLen.Add(1)
+		}
+	}
+	return snake
+}
+
+func toSnakeCase(str string) string {
+	var sb strings.Builder
+	sb.Grow(len(str) + len(str)/4)
+
+	// prev and next are the runes around r; 0 at the start and at the end of the string
+	var prev rune
+	r, size := utf8.DecodeRuneInString(str)
+	for len(str) > 0 {
+		raw := str[:size]
+		str = str[size:]
+		var next rune
+		if len(str) > 0 {
+			next, size = utf8.DecodeRuneInString(str)
+		}
+
+		if unicode.IsUpper(r) {
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || prev != 0 && unicode.IsLower(next) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		} else {
+			// Write the bytes as is, should they not be valid UTF-8
+			sb.WriteString(raw)
+		}
+
+		prev, r = r, next
+	}
+
+	return sb.String()
 }
@@ -2,22 +2,75 @@ package skyconf
 
 import (
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"testing"
 )
 
 func TestToSnakeCase(t *testing.T) {
 	tests := map[string]string{
+		"":                               "",
+		"a":                              "a",
+		"A":                              "a",
 		"UUID":                           "uuid",
 		"uuid":                           "uuid",
 		"OtherUUID":                      "other_uuid",
 		"otherUUID":                      "other_uuid",
 		"Other_UUID":                     "other_uuid",
+		"Other_Uuid":                     "other__uuid",
 		"CoreAPIBaseURL":                 "core_api_base_url",
 		"core_api_base_url":              "core_api_base_url",
 		"/prefix_path/core_api_base_url": "/prefix_path/core_api_base_url",
+		"/Prefix/BasePath":               "/_prefix/_base_path",
+		"Foo.BarBaz":                     "foo._bar_baz",
+		"kebab-Case":                     "kebab-_case",
+
+		// Separators, named as they have always been
+		"Field_Name": "field__name",
+		"some-Key":   "some-_key",
+		"Image.Id":   "image._id",
+
+		// Acronyms
+		"ID":         "id",
+		"UserID":     "user_id",
+		"DBHost":     "db_host",
+		"HTTPServer": "http_server",
+		"MaxQPS":     "max_qps",
+		"ABc":        "a_bc",
+		"aBcDe":      "a_bc_de",
+
+		// Digits
+		"V2":           "v2",
+		"Level2":       "level2",
+		"HTTP2Enabled": "http2_enabled",
+		"Http2Enabled": "http2_enabled",
+		"S3Bucket":     "s3_bucket",
+		"Base64URL":    "base64_url",
+		"Int32Value":   "int32_value",
+		"ISO8601Date":  "iso8601_date",
+		"K8sCluster":   "k8s_cluster",
+		"A1B2":         "a1_b2",
+		"2FA":          "2_fa",
+
+		// Unicode letters
+		"ÄpfelBaum":   "äpfel_baum",
+		"ÉtéURL":      "été_url",
+		"ΑλφαΒήτα":    "αλφα_βήτα",
+		"日本Config":    "日本_config",
+		"Größe":       "größe",
+		"NaïveÜber":   "naïve_über",
+		"invalid\xff": "invalid\xff",
 	}
 
 	for input, expected := range tests {
-		assert.Equal(t, expected, ToSnakeCase(input))
+		assert.Equal(t, expected, ToSnakeCase(input), input)
+
+		// The result is memoised
+		assert.Equal(t, expected, ToSnakeCase(input), input)
+	}
+
+	// The memo is bounded
+	for i := range 2 * maxSnakeCases {
+		ToSnakeCase(strconv.Itoa(i))
 	}
+	assert.LessOrEqual(t, snakeCasesLen.Load(), int64(maxSnakeCases))
 }
@@ -0,0 +1,113 @@
+package skyconf
+
+import "fmt"
+
+// WithSnapshotEncryption makes Snapshot encrypt the values of the fields tagged with `secret` using encrypt, rather
+// than redact them, so that the secrets of two snapshots can be compared without being disclosed; for example, using a
+// keyed hash.
+func WithSnapshotEncryption(encrypt func(value string) (string, error)) Option {
+	return func(o *options) {
+		o.snapshotEncrypt = encrypt
+	}
+}
+
+// Snapshot returns the current values of the fields of the configuration struct, keyed by field ID and formatted as by
+// String, such as for support bundles or to compare the configuration of two running instances. The values of fields
+// tagged with `secret` are redacted, unless encrypted as set by WithSnapshotEncryption. The fields are those returned by
+// Fields with the same options, except the fields of structs behind nil pointers, which are left out and untouched. The
+// configuration struct is locked for reading while its fields are read, if it implements RLocker or sync.Locker.
+func Snapshot(cfg interface{}, opts ...Option) (snapshot map[string]string, err error) {
+	return makeOptions(opts).snapshot(cfg)
+}
+
+// snapshot returns the current values of the fields of the configuration struct, keyed by field ID; see Snapshot.
+func (o *options) snapshot(cfg interface{}) (snapshot map[string]string, err error) {
+	l := readLock(cfg)
+	l.Lock()
+	defer l.Unlock()
+
+	var fields []fieldInfo
+	if fields, err = o.readFields(cfg); err != nil {
+		return
+	}
+
+	snapshot = make(map[string]string, len(fields))
+	for _, field := range fields {
+		if field.unset() {
+			continue
+		}
+
+		var value string
+		if value, err = formatFieldValue(field.structField); err != nil {
+			err = fmt.Errorf("failed to format field %s: %w", field.path, err)
+			return nil, err
+		}
+
+		if field.options.secret {
+			if o.snapshotEncrypt == nil {
+				value = redacted
+			} else if value, err = o.snapshotEncrypt(value); err != nil {
+				err = fmt.Errorf("failed to encrypt field %s: %w", field.path, err)
+				return nil, err
+			}
+		}
+
+		snapshot[field.options.id] = value
+	}
+
+	return
+}
+
+// Clone returns a deep copy of the configuration struct, holding copies of the current values of its fields, secrets
+// included, formatted as by Snapshot and decoded as by Parse with the same options. The fields that Parse does not
+// populate, such as locks and untagged fields unless WithUntagged is given, are left zero in the copy. The configuration
+// struct is locked for reading while its fields are read, if it implements RLocker or sync.Locker.
+func Clone[T any](cfg *T, opts ...Option) (clone *T, err error) {
+	o := makeOptions(opts)
+
+	l := readLock(cfg)
+	l.Lock()
+	defer l.Unlock()
+
+	var fields, cloned []fieldInfo
+	if fields, err = o.readFields(cfg); err != nil {
+		return
+	}
+
+	clone = new(T)
+	if cloned, err = o.readFields(clone); err != nil {
+		return nil, err
+	}
+
+	for i, field := range fields {
+		if field.unset() || field.structField.IsZero() {
+			continue
+		}
+
+		var value string
+		if value, err = formatFieldValue(field.structField); err != nil {
+			err = fmt.Errorf("failed to format field %s: %w", field.path, err)
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+
+		if err = cloned[i].decode(value); err != nil {
+			err = fmt.Errorf("failed to copy field %s: %w", field.path, err)
+			return nil, err
+		}
+		cloned[i].allocate()
+	}
+
+	return
+}
+
+// readFields extracts the fields of the configuration struct to read them, leaving its nil pointers untouched.
+func (o *options) readFields(cfg interface{}) ([]fieldInfo, error) {
+	read := *o
+	read.lazyPointers = true
+	read.reportSkipped = nil
+
+	return read.extractFields(cfg)
+}
@@ -0,0 +1,88 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotAndClone(t *testing.T) {
+	type limits struct {
+		CPU int `sky:"cpu"`
+	}
+	type config struct {
+		sync.RWMutex
+		Host     string            `sky:"host"`
+		Password string            `sky:"password,secret"`
+		Timeout  time.Duration     `sky:"timeout"`
+		Tags     []string          `sky:"tags"`
+		Weights  map[string]int    `sky:"weights"`
+		Limits   *limits           `sky:"limits"`
+		Optional *limits           `sky:"optional"`
+		Labels   map[string]string `sky:"labels,optional"`
+	}
+
+	source := &mockSource{path: "/app/", ps: mockParameterStore{
+		"/app/host":         "db1",
+		"/app/password":     "hunter2",
+		"/app/timeout":      "5s",
+		"/app/tags":         "a;b",
+		"/app/weights":      "x:1;y:2",
+		"/app/limits/cpu":   "4",
+		"/app/optional/cpu": "1",
+	}}
+	cfg := &config{}
+	_, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cfg.Optional = nil
+
+	snapshot, err := Snapshot(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]string{
+		"host":     "db1",
+		"password": redacted,
+		"timeout":  "5s",
+		"tags":     "a;b",
+		"weights":  "x:1;y:2",
+		"cpu":      "4",
+		"labels":   "",
+	}, snapshot)
+
+	// Nil pointers are left untouched
+	assert.Nil(t, cfg.Optional)
+
+	// Secrets can be encrypted rather than redacted
+	snapshot, err = Snapshot(cfg, WithSnapshotEncryption(func(value string) (string, error) {
+		return strings.ToUpper(value), nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "HUNTER2", snapshot["password"])
+
+	// Clones are deep copies
+	clone, err := Clone(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, cfg.Host, clone.Host)
+	assert.Equal(t, cfg.Password, clone.Password)
+	assert.Equal(t, cfg.Timeout, clone.Timeout)
+	assert.Equal(t, cfg.Tags, clone.Tags)
+	assert.Equal(t, cfg.Weights, clone.Weights)
+	assert.Equal(t, cfg.Limits, clone.Limits)
+	assert.Nil(t, clone.Optional)
+	assert.Nil(t, clone.Labels)
+
+	clone.Tags[0] = "changed"
+	clone.Weights["x"] = 10
+	clone.Limits.CPU = 8
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, 1, cfg.Weights["x"])
+	assert.Equal(t, 4, cfg.Limits.CPU)
+}
@@ -2,16 +2,54 @@ package skyconf
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"slices"
 	"strings"
+	"sync/atomic"
 )
 
+// ErrNotSSMSource is returned by RebindSSM when the source was not created by one of the SSMSource functions.
+var ErrNotSSMSource = errors.New("source is not an SSM source")
+
 type ssmSource struct {
-	ssm  *ssmpkg.Client
-	path string
-	id   string
+	client         atomic.Pointer[ssmClientRef] // replaced by RebindSSM
+	path           string
+	id             string
+	verbatim       bool // the keys are not converted to snake case; see WithVerbatimKeys
+	streaming      bool // the parameters under the path are read by path; see WithStreaming
+	partialResults bool // the values fetched before the context is done are returned; see WithPartialResults
+	batchSize      int  // the number of parameters requested by each GetParameters call; see WithBatchSize
+	fallback       bool // failed batches are fetched one by one; see WithGetParameterFallback
+	limiter        *limiter
+	cache          *parameterCache         // values of the parameters last fetched, if changes are detected
+	missing        *missingCache           // parameters not found, left out until rechecked; see WithNegativeCache
+	clientOptions  []func(*ssmpkg.Options) // applied to the client; see WithSSMClientOptions
+}
+
+// WithVerbatimKeys makes an SSM source name the parameters using the keys of the fields as is, rather than converting
+// them to snake case, so that parameters whose names contain uppercase letters or dots, such as the public parameters
+// of AWS under /aws/service, can be referenced relative to the path of the source. The names of untagged fields are
+// then used as is too. A single such parameter can otherwise be referenced by an absolute key, which is never
+// converted.
+func WithVerbatimKeys() SourceOption {
+	return func(o *sourceOptions) {
+		o.verbatimKeys = true
+	}
+}
+
+// WithSSMClientOptions makes an SSM source use a copy of its client, and of any client it is rebound to by RebindSSM,
+// with the functions applied to its options; for example, to send the requests to a custom endpoint, such as that of
+// LocalStack, or to add middleware or a retryer. The options of the client given are left untouched.
+func WithSSMClientOptions(optFns ...func(*ssmpkg.Options)) SourceOption {
+	return func(o *sourceOptions) {
+		o.clientOptions = append(o.clientOptions, optFns...)
+	}
 }
 
 // SSMSource creates a new SSM source.
@@ -21,56 +59,218 @@ func SSMSource(ssm *ssmpkg.Client, path string) Source {
 
 // SSMSourceWithID creates a new SSM source with a custom ID.
 func SSMSourceWithID(ssm *ssmpkg.Client, path, id string) Source {
-	// ensure path ends with a slash
-	if !strings.HasSuffix(path, "/") {
-		path += "/"
-	}
+	return SSMSourceWithOptions(ssm, path, id)
+}
 
-	return &ssmSource{
-		ssm:  ssm,
-		path: path,
-		id:   id,
-	}
+// SSMSourceWithOptions creates a new SSM source with a custom ID, configured using the provided options.
+func SSMSourceWithOptions(ssm *ssmpkg.Client, path, id string, opts ...SourceOption) Source {
+	return SSMSourceWithClient(ssm, path, id, opts...)
+}
+
+// SSMSourceAssumeRole creates a new SSM source with a custom ID, fetching the parameters as the role with the given
+// ARN, such as a role in a central account the parameters are shared from. The role is assumed using STS with the
+// credentials of the configuration; its temporary credentials are cached and renewed before they expire.
+func SSMSourceAssumeRole(cfg aws.Config, roleARN, path, id string, opts ...SourceOption) Source {
+	cfg = cfg.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN))
+
+	return SSMSourceWithOptions(ssmpkg.NewFromConfig(cfg), path, id, opts...)
+}
+
+// ssmDefaultsLayer is the name of the layer of the parameters shared by all the environments; see SSMLayeredSource.
+const ssmDefaultsLayer = "defaults"
+
+// SSMLayeredSource creates a new SSM source with the ID "ssm", layering the parameters under the path of the environment
+// over those under the defaults path shared by all the environments: with the base "/app" and the environment "prod",
+// parameters are looked up under /app/defaults/ and /app/prod/, the latter taking precedence. The layers are fetched
+// with the IDs "defaults" and that of the environment, sharing the limits set by the options; see MergeSource.
+func SSMLayeredSource(ssm *ssmpkg.Client, base, env string, opts ...SourceOption) Source {
+	base = strings.TrimSuffix(base, "/") + "/"
+
+	defaults := SSMSourceWithOptions(ssm, base+ssmDefaultsLayer, ssmDefaultsLayer, opts...).(*ssmSource)
+	layer := SSMSourceWithOptions(ssm, base+env, env, opts...).(*ssmSource)
+	layer.limiter = defaults.limiter
+
+	merged := MergeSourceWithID("ssm", defaults, layer).(*mergeSource)
+	merged.ssmLayered = true
+
+	return merged
+}
+
+// RebindSSM replaces the client of the SSM source with the given one, such as a client with renewed credentials or
+// assuming another role, without parsing the configuration again; the fields of the Refresher keep being refreshed from
+// the source, with their state. Fetches in flight complete with the previous client. It returns ErrNotSSMSource if the
+// source was not created by one of the SSMSource functions or by SSMLayeredSource, whose layers are rebound together.
+func RebindSSM(source Source, client *ssmpkg.Client) error {
+	return RebindSSMClient(source, client)
 }
 
 func (s *ssmSource) Source(ctx context.Context, keys []string) (values map[string]string, err error) {
+	values, _, err = s.SourceWithMetadata(ctx, keys)
+	return
+}
+
+// SourceWithMetadata fetches the parameters using the GetParameters API, along with their version, last modified time,
+// type and ARN.
+func (s *ssmSource) SourceWithMetadata(ctx context.Context, keys []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
 	// Ensure there are keys to fetch
 	if len(keys) == 0 {
 		return
 	}
 
 	// Ensure the ssm client is not nil
-	if s.ssm == nil {
-		err = fmt.Errorf("ssm client is nil")
+	client, err := s.ssmClient()
+	if err != nil {
 		return
 	}
 
-	// Loop over the keys in batches of 10; AWS SSM GetParameters API has a limit of 10 parameters per request
-	for i := 0; i < len(keys); i += 10 {
-		end := i + 10
-		if end > len(keys) {
-			end = len(keys)
+	// If changes are detected, only fetch the parameters that changed since they were last fetched
+	fetch := keys
+	if s.cache != nil {
+		fetch, err = s.changedKeys(ctx, client, keys)
+		if err != nil {
+			return
 		}
+	}
+
+	// Leave out the parameters recently found to be missing
+	if s.missing != nil {
+		fetch = s.missing.filter(fetch)
+	}
+
+	// Loop over the keys in batches; AWS SSM GetParameters API has a limit of 10 parameters per request
+	if len(fetch) > 0 {
+		values = make(map[string]string, len(fetch))
+		metadata = make(map[string]Metadata, len(fetch))
+	}
+	var failed []*ParameterError
+	for i := 0; i < len(fetch); i += s.batchSize {
+		batch := fetch[i:min(i+s.batchSize, len(fetch))]
 
 		// Use GetParameters API to fetch the parameters
 		input := &ssmpkg.GetParametersInput{
-			Names:          keys[i:end],
+			Names:          batch,
 			WithDecryption: aws.Bool(true),
 		}
 
 		var output *ssmpkg.GetParametersOutput
-		output, err = s.ssm.GetParameters(ctx, input)
+		err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+			output, err = client.GetParameters(ctx, input)
+			return
+		})
+		if getter := s.fallBack(ctx, client, err); err != nil && getter != nil {
+			// Fetch the parameters of the batch one by one, so that only those failing are not fetched
+			var errs []*ParameterError
+			errs, err = s.getEach(ctx, getter, batch, values, metadata)
+			failed = append(failed, errs...)
+			if err == nil {
+				continue
+			}
+		}
 		if err != nil {
 			err = fmt.Errorf("failed to get parameters: %w", err)
+
+			// Keep the values of the batches fetched before the context was done, if partial results are enabled
+			unfetched, _ := parameterErrors(failed)
+			pe := s.partialFetch(ctx, append(unfetched, fetch[i:]...), err)
+			if pe == nil || i == 0 {
+				values, metadata = nil, nil
+				return
+			}
+
+			values, metadata = s.record(keys, fetch[:i], pe.Unfetched, values, metadata)
+			err = pe
 			return
 		}
 
 		// Map the parameters for easier access
-		if values == nil {
-			values = make(map[string]string, len(output.Parameters))
+		for _, p := range output.Parameters {
+			key := parameterKey(p)
+			values[key] = aws.ToString(p.Value)
+			metadata[key] = parameterMetadata(p)
+		}
+	}
+
+	// Return the values of the parameters fetched along with the errors of those that failed, if any
+	unfetched, failedErr := parameterErrors(failed)
+	values, metadata = s.record(keys, fetch, unfetched, values, metadata)
+	if failedErr != nil {
+		err = &PartialFetchError{Unfetched: unfetched, Err: failedErr}
+	}
+
+	return
+}
+
+// record records the parameters fetched, other than those unfetched, with the caches of the source, if any, and
+// returns the values completed with those of the parameters requested that have not changed, if changes are detected.
+func (s *ssmSource) record(keys, fetched, unfetched []string, values map[string]string,
+	metadata map[string]Metadata) (map[string]string, map[string]Metadata) {
+
+	if len(unfetched) > 0 {
+		isUnfetched := func(key string) bool { return slices.Contains(unfetched, key) }
+		keys = slices.DeleteFunc(slices.Clone(keys), isUnfetched)
+		fetched = slices.DeleteFunc(slices.Clone(fetched), isUnfetched)
+	}
+
+	if s.missing != nil {
+		s.missing.update(fetched, values)
+	}
+
+	// Complete the values fetched with those of the parameters that have not changed
+	if s.cache != nil {
+		values, metadata = s.cache.update(keys, fetched, values, metadata)
+	}
+
+	return values, metadata
+}
+
+// parameterKey returns the key of the parameter as requested; the name, followed by the version or label selector
+// used to fetch it, if any.
+func parameterKey(p types.Parameter) string {
+	name, selector := aws.ToString(p.Name), aws.ToString(p.Selector)
+	if selector == "" {
+		return name
+	}
+
+	return name + ":" + strings.TrimPrefix(selector, ":")
+}
+
+// ListKeys lists the parameters under the path formed by the parts using the GetParametersByPath API.
+func (s *ssmSource) ListKeys(ctx context.Context, parts []string) (keys []string, err error) {
+	// Ensure the ssm client is not nil
+	client, err := s.ssmClient()
+	if err != nil {
+		return
+	}
+
+	path := strings.TrimSuffix(s.ParameterName(parts), "/")
+	if path == "" {
+		path = "/"
+	}
+
+	paginator := ssmpkg.NewGetParametersByPathPaginator(client, &ssmpkg.GetParametersByPathInput{
+		Path:      aws.String(path),
+		Recursive: aws.Bool(true),
+	})
+
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for paginator.HasMorePages() {
+		var output *ssmpkg.GetParametersByPathOutput
+		err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+			output, err = paginator.NextPage(ctx)
+			return
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to get parameters by path: %w", err)
+			return
 		}
+
 		for _, p := range output.Parameters {
-			values[aws.ToString(p.Name)] = aws.ToString(p.Value)
+			if key, ok := strings.CutPrefix(aws.ToString(p.Name), prefix); ok {
+				keys = append(keys, key)
+			}
 		}
 	}
 
@@ -78,16 +278,25 @@ func (s *ssmSource) Source(ctx context.Context, keys []string) (values map[strin
 }
 
 func (s *ssmSource) ParameterName(parts []string) string {
+	if s.verbatim {
+		names := make([]string, len(parts))
+		for i, part := range parts {
+			names[i] = unmarked(part)
+		}
+		return s.path + strings.Join(names, "/")
+	}
+
 	return makeParameterName(s.path, parts)
 }
 
 func makeParameterName(path string, parts []string) string {
-	// Join the parts with a slash after converting them to snake case
+	// Join the parts with a slash after converting them to snake case, leaving the parts of the caller untouched
+	names := make([]string, len(parts))
 	for i, part := range parts {
-		parts[i] = ToSnakeCase(part)
+		names[i] = ToSnakeCase(part)
 	}
 
-	return path + strings.Join(parts, "/")
+	return path + strings.Join(names, "/")
 }
 
 func (s *ssmSource) ID() string {
@@ -0,0 +1,479 @@
+package skyconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSSMParameter is a parameter held by fakeSSM.
+type fakeSSMParameter struct {
+	Value   string
+	Version int64
+	Labels  []string
+	History map[string]string // values of the earlier versions, keyed by version
+}
+
+// fakeSSM is an http client serving the SSM API from an in-memory set of parameters, recording the calls made.
+type fakeSSM struct {
+	m          sync.Mutex
+	parameters map[string]*fakeSSMParameter
+	calls      []string        // the operations called, in order
+	fetched    map[string]int  // the number of times each parameter was fetched
+	denied     map[string]bool // the parameters whose access is denied, failing the requests including them
+}
+
+func newFakeSSM(parameters map[string]*fakeSSMParameter) *fakeSSM {
+	return &fakeSSM{parameters: parameters, fetched: make(map[string]int)}
+}
+
+func (f *fakeSSM) client() *ssmpkg.Client {
+	return ssmpkg.New(ssmpkg.Options{
+		Region:      "eu-west-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  f,
+	})
+}
+
+func (f *fakeSSM) put(name, value string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	p, ok := f.parameters[name]
+	if !ok {
+		p = &fakeSSMParameter{}
+		f.parameters[name] = p
+	}
+	p.Value = value
+	p.Version++
+}
+
+func (f *fakeSSM) Do(req *http.Request) (*http.Response, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	op := strings.TrimPrefix(req.Header.Get("X-Amz-Target"), "AmazonSSM.")
+	f.calls = append(f.calls, op)
+
+	var input map[string]json.RawMessage
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		return nil, err
+	}
+
+	var output any
+	switch op {
+	case "GetParameters":
+		var names []string
+		_ = json.Unmarshal(input["Names"], &names)
+		if slices.ContainsFunc(names, func(name string) bool { return f.denied[name] }) {
+			return f.response(http.StatusBadRequest, map[string]string{"__type": "AccessDeniedException"})
+		}
+		output = f.getParameters(names)
+	case "GetParameter":
+		var name string
+		_ = json.Unmarshal(input["Name"], &name)
+		if f.denied[name] {
+			return f.response(http.StatusBadRequest, map[string]string{"__type": "AccessDeniedException"})
+		}
+		found, _ := f.find([]string{name})
+		if len(found) == 0 {
+			return f.response(http.StatusBadRequest, map[string]string{"__type": "ParameterNotFound"})
+		}
+		output = map[string]any{"Parameter": found[0]}
+	case "GetParametersByPath":
+		var path, token string
+		var maxResults int
+		_ = json.Unmarshal(input["Path"], &path)
+		_ = json.Unmarshal(input["NextToken"], &token)
+		_ = json.Unmarshal(input["MaxResults"], &maxResults)
+		output = f.getParametersByPath(path, token, maxResults)
+	case "DescribeParameters":
+		var filters []struct{ Values []string }
+		_ = json.Unmarshal(input["ParameterFilters"], &filters)
+		output = f.describeParameters(filters[0].Values)
+	default:
+		return f.response(http.StatusBadRequest, map[string]string{"__type": "InvalidAction"})
+	}
+
+	return f.response(http.StatusOK, output)
+}
+
+// fakeSSMResult is a parameter returned by fakeSSM.
+type fakeSSMResult struct {
+	Name     string
+	Selector string `json:",omitempty"`
+	Value    string
+	Version  int64
+	Type     string
+	ARN      string
+}
+
+func (f *fakeSSM) getParameters(names []string) any {
+	parameters, invalid := f.find(names)
+	return map[string]any{"Parameters": parameters, "InvalidParameters": invalid}
+}
+
+// find returns the parameters with the names, which may be followed by a version or label selector, and the names of
+// those not found.
+func (f *fakeSSM) find(names []string) (parameters []fakeSSMResult, invalid []string) {
+	for _, name := range names {
+		name, selector, _ := strings.Cut(name, ":")
+		p, ok := f.parameters[name]
+		if !ok {
+			invalid = append(invalid, name)
+			continue
+		}
+
+		value, version := p.Value, p.Version
+		if selector != "" {
+			value, ok = p.History[selector]
+			for _, label := range p.Labels {
+				if label == selector {
+					value, ok = p.Value, true
+				}
+			}
+			if !ok {
+				invalid = append(invalid, name)
+				continue
+			}
+			selector = ":" + selector
+		}
+
+		f.fetched[name]++
+		parameters = append(parameters, fakeSSMResult{
+			Name: name, Selector: selector, Value: value, Version: version, Type: "String", ARN: "arn:" + name,
+		})
+	}
+
+	return
+}
+
+// getParametersByPath returns a page of the parameters under the path, in the order of their names, starting from the
+// index given by the token.
+func (f *fakeSSM) getParametersByPath(path, token string, maxResults int) any {
+	type parameter struct {
+		Name    string
+		Value   string
+		Version int64
+		Type    string
+		ARN     string
+	}
+
+	var names []string
+	for name := range f.parameters {
+		if strings.HasPrefix(name, strings.TrimSuffix(path, "/")+"/") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	if maxResults == 0 {
+		maxResults = 10
+	}
+	start, _ := strconv.Atoi(token)
+	end := min(start+maxResults, len(names))
+
+	var parameters []parameter
+	for _, name := range names[start:end] {
+		p := f.parameters[name]
+		f.fetched[name]++
+		parameters = append(parameters, parameter{
+			Name: name, Value: p.Value, Version: p.Version, Type: "String", ARN: "arn:" + name,
+		})
+	}
+
+	output := map[string]any{"Parameters": parameters}
+	if end < len(names) {
+		output["NextToken"] = strconv.Itoa(end)
+	}
+
+	return output
+}
+
+func (f *fakeSSM) describeParameters(names []string) any {
+	type parameter struct {
+		Name    string
+		Version int64
+	}
+
+	var parameters []parameter
+	for _, name := range names {
+		if p, ok := f.parameters[name]; ok {
+			parameters = append(parameters, parameter{Name: name, Version: p.Version})
+		}
+	}
+
+	return map[string]any{"Parameters": parameters}
+}
+
+func (f *fakeSSM) response(status int, output any) (*http.Response, error) {
+	body, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func TestSSMSource(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{
+		"/path/param1": {Value: "value1", Version: 1},
+		"/path/param2": {Value: "value2", Version: 3, Labels: []string{"stable"}},
+		"/path/param3": {Value: "value3", Version: 2, History: map[string]string{"1": "old-value3"}},
+	})
+
+	source := SSMSource(fake.client(), "/path").(MetadataSource)
+
+	values, metadata, err := source.SourceWithMetadata(context.Background(),
+		[]string{"/path/param1", "/path/param2:stable", "/path/param3:1", "/path/missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"/path/param1":        "value1",
+		"/path/param2:stable": "value2",
+		"/path/param3:1":      "old-value3",
+	}, values)
+	assert.Equal(t, Metadata{Version: 1, Type: "String", ARN: "arn:/path/param1"}, metadata["/path/param1"])
+}
+
+func TestSSMSourceWithChangeDetection(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{
+		"/path/param1": {Value: "value1", Version: 1},
+		"/path/param2": {Value: "value2", Version: 1},
+	})
+
+	source := SSMSourceWithOptions(fake.client(), "/path", "ssm", WithChangeDetection())
+	keys := []string{"/path/param1", "/path/param2"}
+	want := map[string]string{"/path/param1": "value1", "/path/param2": "value2"}
+
+	// The first fetch gets all the parameters
+	values, err := source.Source(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, want, values)
+	assert.Equal(t, []string{"GetParameters"}, fake.calls)
+
+	// Nothing changed; nothing is fetched
+	fake.calls = nil
+	values, err = source.Source(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, want, values)
+	assert.Equal(t, []string{"DescribeParameters"}, fake.calls)
+	assert.Equal(t, map[string]int{"/path/param1": 1, "/path/param2": 1}, fake.fetched)
+
+	// Only the changed parameter is fetched
+	fake.calls = nil
+	fake.put("/path/param2", "new-value2")
+	values, err = source.Source(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/path/param1": "value1", "/path/param2": "new-value2"}, values)
+	assert.Equal(t, []string{"DescribeParameters", "GetParameters"}, fake.calls)
+	assert.Equal(t, map[string]int{"/path/param1": 1, "/path/param2": 2}, fake.fetched)
+
+	// Deleted parameters are no longer returned
+	delete(fake.parameters, "/path/param1")
+	values, err = source.Source(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/path/param2": "new-value2"}, values)
+}
+
+func TestRebindSSM(t *testing.T) {
+	before := newFakeSSM(map[string]*fakeSSMParameter{"/path/param1": {Value: "value1", Version: 1}})
+	after := newFakeSSM(map[string]*fakeSSMParameter{"/path/param1": {Value: "value2", Version: 1}})
+
+	source := SSMSource(before.client(), "/path")
+	cfg := &struct {
+		Param1 string `sky:"param1,refresh:1m"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "value1", cfg.Param1)
+
+	// The refresh uses the new client, keeping the state of the fields
+	assert.NoError(t, RebindSSM(source, after.client()))
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, "value2", cfg.Param1)
+	assert.Equal(t, []string{"GetParameters"}, after.calls)
+	assert.Equal(t, []string{"GetParameters"}, before.calls)
+
+	assert.ErrorIs(t, RebindSSM(&mockSource{}, after.client()), ErrNotSSMSource)
+}
+
+// hostRecorder is an http client recording the hosts of the requests, which are served by fakeSSM.
+type hostRecorder struct {
+	ssm   *fakeSSM
+	hosts []string
+}
+
+func (h *hostRecorder) Do(req *http.Request) (*http.Response, error) {
+	h.hosts = append(h.hosts, req.URL.Host)
+	return h.ssm.Do(req)
+}
+
+func TestSSMSourceWithClientOptions(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{"/path/param1": {Value: "value1", Version: 1}})
+	recorder := &hostRecorder{ssm: fake}
+	client := ssmpkg.New(ssmpkg.Options{Region: "eu-west-1", Credentials: aws.AnonymousCredentials{}})
+
+	source := SSMSourceWithOptions(client, "/path", "ssm", WithSSMClientOptions(func(o *ssmpkg.Options) {
+		o.BaseEndpoint = aws.String("http://localhost:4566")
+		o.HTTPClient = recorder
+	}))
+	values, err := source.Source(context.Background(), []string{"/path/param1"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/path/param1": "value1"}, values)
+	assert.Equal(t, []string{"localhost:4566"}, recorder.hosts)
+
+	// The client given is left untouched
+	assert.Nil(t, client.Options().BaseEndpoint)
+
+	// The options are applied to the clients the source is rebound to
+	assert.NoError(t, RebindSSM(source, ssmpkg.New(ssmpkg.Options{Region: "eu-west-2"})))
+	_, err = source.Source(context.Background(), []string{"/path/param1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost:4566", "localhost:4566"}, recorder.hosts)
+}
+
+// fakeSTS is an http client serving the AssumeRole call of the STS API, and the SSM API using fakeSSM, recording the
+// access keys the SSM calls are signed with.
+type fakeSTS struct {
+	ssm     *fakeSSM
+	m       sync.Mutex
+	assumed []string // the roles assumed
+	keys    []string // the access keys of the SSM calls
+}
+
+func (f *fakeSTS) Do(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Host, "sts.") {
+		f.m.Lock()
+		auth := req.Header.Get("Authorization")
+		key, _, _ := strings.Cut(strings.TrimPrefix(auth[strings.Index(auth, "Credential="):], "Credential="), "/")
+		f.keys = append(f.keys, key)
+		f.m.Unlock()
+		return f.ssm.Do(req)
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	f.m.Lock()
+	f.assumed = append(f.assumed, req.PostForm.Get("RoleArn"))
+	f.m.Unlock()
+
+	body := `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials>` +
+		`<AccessKeyId>ASSUMED</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+		`<Expiration>` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `</Expiration></Credentials>` +
+		`</AssumeRoleResult></AssumeRoleResponse>`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestSSMSourceAssumeRole(t *testing.T) {
+	fake := &fakeSTS{ssm: newFakeSSM(map[string]*fakeSSMParameter{"/shared/param1": {Value: "value1", Version: 1}})}
+	cfg := aws.Config{
+		Region:      "eu-west-1",
+		Credentials: credentials.NewStaticCredentialsProvider("BASE", "secret", ""),
+		HTTPClient:  fake,
+	}
+
+	source := SSMSourceAssumeRole(cfg, "arn:aws:iam::123456789012:role/shared-config", "/shared", "shared")
+	assert.Equal(t, "shared", source.ID())
+
+	for i := 0; i < 2; i++ {
+		values, err := source.Source(context.Background(), []string{"/shared/param1"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"/shared/param1": "value1"}, values)
+	}
+
+	// The role is assumed once, its credentials cached until they expire
+	assert.Equal(t, []string{"arn:aws:iam::123456789012:role/shared-config"}, fake.assumed)
+	assert.Equal(t, []string{"ASSUMED", "ASSUMED"}, fake.keys)
+}
+
+func TestSSMSourceVerbatimKeys(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{
+		"/aws/service/bottlerocket/aws-ecs-2/x86_64/latest/image_id": {Value: "ami-1", Version: 1},
+		"/aws/service/Example/Image.Id":                              {Value: "ami-2", Version: 1},
+	})
+
+	var cfg struct {
+		ImageID string `sky:"Image.Id"`
+		Latest  string `sky:"/aws/service/bottlerocket/aws-ecs-2/x86_64/latest/image_id"`
+	}
+	source := SSMSourceWithOptions(fake.client(), "/aws/service/Example", "public", WithVerbatimKeys())
+	assert.Equal(t, "/aws/service/Example/Image.Id/AmiID", source.ParameterName([]string{"Image.Id", "AmiID"}))
+
+	_, err := Parse(context.Background(), &cfg, false, source)
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-2", cfg.ImageID)
+	assert.Equal(t, "ami-1", cfg.Latest)
+
+	// Without the option, the keys are converted to snake case
+	source = SSMSource(fake.client(), "/aws/service/Example")
+	assert.Equal(t, "/aws/service/Example/image._id", source.ParameterName([]string{"Image.Id"}))
+}
+
+func TestSSMLayeredSource(t *testing.T) {
+	fake := newFakeSSM(map[string]*fakeSSMParameter{
+		"/app/defaults/log_level": {Value: "info", Version: 1},
+		"/app/defaults/db/host":   {Value: "db.default", Version: 1},
+		"/app/defaults/db/port":   {Value: "5432", Version: 1},
+		"/app/prod/db/host":       {Value: "db.prod", Version: 1},
+		"/app/staging/log_level":  {Value: "debug", Version: 1},
+	})
+
+	type config struct {
+		LogLevel string `sky:"logLevel"`
+		DB       struct {
+			Host string `sky:"host"`
+			Port int    `sky:"port"`
+		} `sky:"db"`
+	}
+
+	var prod config
+	source := SSMLayeredSource(fake.client(), "/app/", "prod")
+	assert.Equal(t, "ssm", source.ID())
+	assert.Equal(t, "[ defaults:/app/defaults/db/host < prod:/app/prod/db/host ]",
+		source.ParameterName([]string{"db", "host"}))
+
+	_, err := Parse(context.Background(), &prod, false, source)
+	assert.NoError(t, err)
+	assert.Equal(t, "info", prod.LogLevel)
+	assert.Equal(t, "db.prod", prod.DB.Host)
+	assert.Equal(t, 5432, prod.DB.Port)
+
+	var staging config
+	_, err = Parse(context.Background(), &staging, false, SSMLayeredSource(fake.client(), "/app", "staging"))
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", staging.LogLevel)
+	assert.Equal(t, "db.default", staging.DB.Host)
+
+	// The layers are rebound together
+	assert.NoError(t, RebindSSM(source, nil))
+	_, err = source.Source(context.Background(), []string{source.ParameterName([]string{"db", "host"})})
+	assert.ErrorContains(t, err, "ssm client is nil")
+
+	// Merged SSM sources may use clients of different accounts, and are not rebound
+	merged := MergeSource(SSMSource(fake.client(), "/app/defaults"), SSMSource(fake.client(), "/app/prod"))
+	assert.ErrorIs(t, RebindSSM(merged, nil), ErrNotSSMSource)
+}
@@ -0,0 +1,96 @@
+package skyconf
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// builtinDecoders decode the values of common types of the standard library, with errors describing the expected
+// format. Decoders registered using RegisterDecoder take precedence.
+var builtinDecoders = map[reflect.Type]DecoderFunc{
+	reflect.TypeOf(url.URL{}):        decodeURL,
+	reflect.TypeOf(&url.URL{}):       decodeURL,
+	reflect.TypeOf(net.IP{}):         decodeIP,
+	reflect.TypeOf(netip.Addr{}):     decodeAddr,
+	reflect.TypeOf(time.Time{}):      decodeTime,
+	reflect.TypeOf(time.Location{}):  decodeLocation,
+	reflect.TypeOf(&time.Location{}): decodeLocation,
+	reflect.TypeOf(regexp.Regexp{}):  decodeRegexp,
+	reflect.TypeOf(&regexp.Regexp{}): decodeRegexp,
+	reflect.TypeOf(big.Int{}):        decodeBigInt,
+	reflect.TypeOf(&big.Int{}):       decodeBigInt,
+}
+
+func decodeURL(value string) (interface{}, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q; expected a URL, as in https://example.com/path: %w", value, err)
+	}
+
+	return u, nil
+}
+
+func decodeIP(value string) (interface{}, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q; expected an IPv4 or IPv6 address, as in 192.0.2.1 or 2001:db8::1",
+			value)
+	}
+
+	return ip, nil
+}
+
+func decodeAddr(value string) (interface{}, error) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address %q; expected an IPv4 or IPv6 address, as in 192.0.2.1 or 2001:db8::1",
+			value)
+	}
+
+	return addr, nil
+}
+
+func decodeTime(value string) (interface{}, error) {
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid time %q; expected an RFC 3339 time or a date, as in 2006-01-02T15:04:05Z or "+
+		"2006-01-02", value)
+}
+
+func decodeLocation(value string) (interface{}, error) {
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid location %q; expected an IANA time zone name, as in Europe/London or UTC: %w",
+			value, err)
+	}
+
+	return loc, nil
+}
+
+func decodeRegexp(value string) (interface{}, error) {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q; expected RE2 syntax: %w", value, err)
+	}
+
+	return re, nil
+}
+
+func decodeBigInt(value string) (interface{}, error) {
+	i, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q; expected an integer, as in 123 or 0x7b", value)
+	}
+
+	return i, nil
+}
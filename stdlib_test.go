@@ -0,0 +1,89 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestStdlibTypes(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/endpoint": "https://example.com/api",
+			"/path/mirror":   "https://mirror.example.com",
+			"/path/ip":       "192.0.2.1",
+			"/path/addr":     "2001:db8::1",
+			"/path/since":    "2024-03-01T12:00:00Z",
+			"/path/until":    "2024-12-31",
+			"/path/zone":     "UTC",
+			"/path/pattern":  "^a+b$",
+			"/path/big":      "0x10000000000000000",
+		},
+		path: "/path/",
+	}
+
+	cfg := &struct {
+		Endpoint url.URL        `sky:"endpoint"`
+		Mirror   *url.URL       `sky:"mirror"`
+		IP       net.IP         `sky:"ip"`
+		Addr     netip.Addr     `sky:"addr"`
+		Since    time.Time      `sky:"since"`
+		Until    time.Time      `sky:"until"`
+		Zone     *time.Location `sky:"zone"`
+		Pattern  *regexp.Regexp `sky:"pattern"`
+		Big      *big.Int       `sky:"big"`
+	}{}
+
+	_, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "https://example.com/api", cfg.Endpoint.String())
+	assert.Equal(t, "mirror.example.com", cfg.Mirror.Host)
+	assert.Equal(t, "192.0.2.1", cfg.IP.String())
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), cfg.Addr)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), cfg.Since)
+	assert.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), cfg.Until)
+	assert.Equal(t, time.UTC, cfg.Zone)
+	assert.True(t, cfg.Pattern.MatchString("aab"))
+	assert.Equal(t, "18446744073709551616", cfg.Big.String())
+
+	str, err := String(cfg, false, true, source)
+	assert.NoError(t, err)
+	assert.Contains(t, str, "id:zone} = UTC")
+	assert.Contains(t, str, "id:pattern} = ^a+b$")
+
+	// Errors describe the expected format
+	tests := []struct {
+		key, value, wantErr string
+	}{
+		{"/path/ip", "192.0.2", "expected an IPv4 or IPv6 address, as in 192.0.2.1 or 2001:db8::1"},
+		{"/path/addr", "::g", "expected an IPv4 or IPv6 address"},
+		{"/path/since", "yesterday", "expected an RFC 3339 time or a date"},
+		{"/path/zone", "Mars/Olympus", "expected an IANA time zone name"},
+		{"/path/pattern", "a(", "expected RE2 syntax"},
+		{"/path/big", "1e6", "expected an integer, as in 123 or 0x7b"},
+		{"/path/endpoint", "http://[::1", "expected a URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value := source.ps[tt.key]
+			defer func() {
+				source.ps[tt.key] = value
+			}()
+
+			source.ps[tt.key] = tt.value
+			_, err := Parse(context.Background(), cfg, false, source)
+			assert.ErrorIs(t, err, ErrBadFieldValue)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
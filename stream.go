@@ -0,0 +1,187 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"strings"
+)
+
+// StreamSource is a Source that can stream the parameters it fetches, one at a time, rather than return them all in a
+// map. When a source implements StreamSource, Stream is used in place of Source and SourceWithMetadata; only the values
+// of the parameters requested are then kept, so that sources reading large trees of parameters, most of which are not
+// requested, do not need to hold them all in memory.
+type StreamSource interface {
+	Source
+	// Stream fetches the parameters from the source, calling yield with the name, value and metadata of each of those
+	// found, in any order. It stops fetching, and returns nil, as soon as yield returns false.
+	Stream(ctx context.Context, params []string, yield func(name, value string, metadata Metadata) bool) error
+}
+
+// ErrStreamLimit is returned when the values of the parameters streamed from a source exceed the limit set by
+// WithMaxStreamBytes.
+var ErrStreamLimit = errors.New("stream limit exceeded")
+
+// WithMaxStreamBytes limits the total size of the names and values of the parameters kept from each fetch of a
+// StreamSource. The fetch stops as soon as the limit is exceeded, so that no further pages are requested from the
+// source, and fails with ErrStreamLimit. The size is not limited if n is 0.
+func WithMaxStreamBytes(n int) Option {
+	return func(o *options) {
+		o.maxStreamBytes = n
+	}
+}
+
+// WithStreaming makes an SSM source stream the parameters under its path using the GetParametersByPath API, page by
+// page, rather than fetch them in batches using the GetParameters API. This takes fewer requests when most of the
+// parameters under the path are requested. The pages stop being requested once all the parameters have been found.
+// Parameters requested by version or label, or outside the path, are fetched as usual; so are all the parameters if
+// changes are detected, as set by WithChangeDetection.
+func WithStreaming() SourceOption {
+	return func(o *sourceOptions) {
+		o.streaming = true
+	}
+}
+
+// stream fetches the values of the keys from the source, keeping those requested only, within the limit set by
+// WithMaxStreamBytes.
+func (o *options) stream(ctx context.Context, source StreamSource, keys []string) (values map[string]string,
+	metadata map[string]Metadata, err error) {
+
+	requested := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		requested[key] = true
+	}
+
+	values = make(map[string]string)
+	metadata = make(map[string]Metadata)
+	var size int
+	var exceeded bool
+	err = source.Stream(ctx, keys, func(name, value string, md Metadata) bool {
+		if !requested[name] {
+			return true
+		}
+
+		if _, ok := values[name]; !ok {
+			size += len(name) + len(value)
+		}
+		if o.maxStreamBytes > 0 && size > o.maxStreamBytes {
+			exceeded = true
+			return false
+		}
+
+		values[name] = value
+		metadata[name] = md
+		return true
+	})
+	if err == nil && exceeded {
+		err = fmt.Errorf("%w: more than %d bytes of parameters", ErrStreamLimit, o.maxStreamBytes)
+	}
+	if err != nil && !errors.Is(err, ErrPartialFetch) {
+		values, metadata = nil, nil
+	}
+
+	return
+}
+
+// Stream fetches the parameters, calling yield with each of those found. If streaming is enabled using WithStreaming,
+// the parameters under the path of the source are read page by page using the GetParametersByPath API; otherwise they
+// are fetched as by SourceWithMetadata.
+func (s *ssmSource) Stream(ctx context.Context, keys []string,
+	yield func(name, value string, metadata Metadata) bool) (err error) {
+
+	// Fetch the parameters that cannot be read by path as usual
+	byPath := make(map[string]bool)
+	var others []string
+	for _, key := range keys {
+		if s.streaming && s.cache == nil && strings.HasPrefix(key, s.path) && !strings.Contains(key, ":") {
+			byPath[key] = true
+		} else {
+			others = append(others, key)
+		}
+	}
+
+	if len(others) > 0 {
+		var values map[string]string
+		var metadata map[string]Metadata
+		values, metadata, err = s.SourceWithMetadata(ctx, others)
+		for name, value := range values {
+			if !yield(name, value, metadata[name]) {
+				return
+			}
+		}
+		if err != nil {
+			// The parameters to read by path are not fetched either
+			var pe *PartialFetchError
+			if errors.As(err, &pe) {
+				pe.Unfetched = append(pe.Unfetched, unfetchedByPath(keys, byPath)...)
+			}
+			return
+		}
+	}
+
+	if len(byPath) == 0 {
+		return
+	}
+
+	// Ensure the ssm client is not nil
+	client, err := s.ssmClient()
+	if err != nil {
+		return
+	}
+
+	path := strings.TrimSuffix(s.path, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	paginator := ssmpkg.NewGetParametersByPathPaginator(client, &ssmpkg.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+
+	// Stop requesting pages once all the parameters have been found
+	for len(byPath) > 0 && paginator.HasMorePages() {
+		var output *ssmpkg.GetParametersByPathOutput
+		err = s.limiter.do(ctx, func(ctx context.Context) (err error) {
+			output, err = paginator.NextPage(ctx)
+			return
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to get parameters by path: %w", err)
+
+			// The parameters yielded so far are kept if partial results are enabled
+			if pe := s.partialFetch(ctx, unfetchedByPath(keys, byPath), err); pe != nil {
+				err = pe
+			}
+			return
+		}
+
+		for _, p := range output.Parameters {
+			name := aws.ToString(p.Name)
+			if !byPath[name] {
+				continue
+			}
+
+			delete(byPath, name)
+			if !yield(name, aws.ToString(p.Value), parameterMetadata(p)) {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// unfetchedByPath returns the keys, in order, of the parameters to read by path that have not been found yet.
+func unfetchedByPath(keys []string, byPath map[string]bool) (unfetched []string) {
+	for _, key := range keys {
+		if byPath[key] {
+			unfetched = append(unfetched, key)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package skyconf
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// mockStreamSource is a StreamSource streaming all its parameters, whichever are requested, recording the number
+// streamed.
+type mockStreamSource struct {
+	mockSource
+	streamed int
+}
+
+func (m *mockStreamSource) Stream(_ context.Context, _ []string,
+	yield func(name, value string, metadata Metadata) bool) error {
+
+	for name, value := range m.ps {
+		m.streamed++
+		if !yield(name, value, Metadata{Version: 1}) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func TestStreamSource(t *testing.T) {
+	source := &mockStreamSource{mockSource: mockSource{ps: mockParameterStore{}, path: "/app/"}}
+	for i := range 100 {
+		source.ps[fmt.Sprintf("/app/other/param%d", i)] = "value"
+	}
+	source.ps["/app/host"] = "localhost"
+	source.ps["/app/port"] = "5432"
+
+	var cfg struct {
+		Host string `sky:"host"`
+		Port int    `sky:"port"`
+	}
+	r, err := Parse(context.Background(), &cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, int64(1), r.Status()[0].Metadata.Version)
+
+	// Only the values of the parameters requested are kept
+	values, _, err := makeOptions(nil).stream(context.Background(), source, []string{"/app/host", "/app/missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/app/host": "localhost"}, values)
+
+	// The stream stops once the limit is exceeded
+	source.streamed = 0
+	_, err = ParseWithOptions(context.Background(), &cfg, []Source{source}, WithMaxStreamBytes(12))
+	assert.ErrorIs(t, err, ErrStreamLimit)
+	assert.Less(t, source.streamed, len(source.ps))
+}
+
+func TestSSMSourceWithStreaming(t *testing.T) {
+	parameters := map[string]*fakeSSMParameter{
+		"/app/db/host":   {Value: "db1", Version: 2},
+		"/app/db/port":   {Value: "5432", Version: 1},
+		"/app/log_level": {Value: "info", Version: 1, Labels: []string{"stable"}},
+		"/shared/region": {Value: "eu-west-1", Version: 1},
+	}
+	for i := range 25 {
+		parameters[fmt.Sprintf("/app/zz/unused%02d", i)] = &fakeSSMParameter{Value: "unused", Version: 1}
+	}
+	fake := newFakeSSM(parameters)
+
+	var cfg struct {
+		DB struct {
+			Host string `sky:"host"`
+			Port int    `sky:"port"`
+		} `sky:"db"`
+		LogLevel string `sky:"log_level,label:stable"`
+		Region   string `sky:"/shared/region"`
+	}
+	source := SSMSourceWithOptions(fake.client(), "/app", "ssm", WithStreaming())
+	r, err := Parse(context.Background(), &cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "db1", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+	assert.Equal(t, Metadata{Version: 2, Type: "String", ARN: "arn:/app/db/host"}, r.Status()[0].Metadata)
+
+	// The parameters requested by label, or outside the path, are fetched using GetParameters, and the pages of the
+	// path stop being requested once the others are found
+	assert.Equal(t, []string{"GetParameters", "GetParametersByPath"}, fake.calls)
+
+	// Without the option, the parameters are fetched using GetParameters
+	fake.calls = nil
+	_, err = Parse(context.Background(), &cfg, false, SSMSourceWithOptions(fake.client(), "/app", "ssm"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GetParameters"}, fake.calls)
+}
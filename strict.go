@@ -0,0 +1,92 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownParameters can be returned by the function passed to WithStrict to fail parsing when unknown parameters
+// are found.
+var ErrUnknownParameters = errors.New("unknown parameters")
+
+// UnknownParametersFunc is called with the names of the parameters found under the path of a source that map to no
+// field of the configuration struct. If it returns an error, parsing fails with it.
+type UnknownParametersFunc func(ctx context.Context, sourceID string, parameters []string) error
+
+// WithStrict makes parsing check the sources that implement KeyLister for parameters under their path that map to no
+// field of the configuration struct, which helps catching typos and orphaned parameters. The names of the unknown
+// parameters of each source are passed to the report function, which can fail parsing by returning an error, such as
+// ErrUnknownParameters. If the report function is nil, each unknown parameter is logged as a warning.
+func WithStrict(report UnknownParametersFunc) Option {
+	return func(o *options) {
+		o.strict = true
+		o.reportUnknown = report
+	}
+}
+
+// checkUnknownParameters reports the parameters under the path of each of the sources that can list them, which map
+// to none of the fields.
+func (o *options) checkUnknownParameters(ctx context.Context, fields []fieldInfo, sources []Source) (err error) {
+	for _, source := range sources {
+		lister, ok := source.(KeyLister)
+		if !ok {
+			continue
+		}
+
+		var keys []string
+		keys, err = lister.ListKeys(ctx, []string{})
+		if err != nil {
+			err = fmt.Errorf("failed to list the parameters of source '%s' : %w", source.ID(), err)
+			return
+		}
+
+		// Collect the names of the parameters of the fields that can be taken from the source, under their aliases too
+		known := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			if !field.options.fromSource(source.ID()) {
+				continue
+			}
+
+			known[field.name(source)] = true
+			for _, name := range field.aliasNames(source) {
+				// The parameters are listed without the version or label selector of the field
+				if field.options.selector != "" {
+					name = strings.TrimSuffix(name, ":"+field.options.selector)
+				}
+				known[name] = true
+			}
+		}
+
+		root := source.ParameterName([]string{})
+
+		var unknown []string
+		for _, key := range keys {
+			name := root + strings.TrimPrefix(key, "/")
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+
+		if len(unknown) == 0 {
+			continue
+		}
+
+		sort.Strings(unknown)
+
+		if o.reportUnknown == nil {
+			for _, name := range unknown {
+				o.logger.WarnContext(ctx, "unknown parameter", "source", source.ID(), "parameter", name)
+			}
+			continue
+		}
+
+		if err = o.reportUnknown(ctx, source.ID(), unknown); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,67 @@
+package skyconf
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	ps := mockParameterStore{
+		"/path/level":       "info",
+		"/path/db/host":     "localhost",
+		"/path/db/hots":     "typo",
+		"/path/old/setting": "orphaned",
+		"/other/level":      "debug",
+	}
+
+	type config struct {
+		Level string `sky:"level"`
+		DB    struct {
+			Host string `sky:"host"`
+		} `sky:"db"`
+	}
+
+	t.Run("warnings", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+		_, err := ParseWithOptions(context.Background(), &config{}, []Source{&mockSource{ps: ps, path: "/path/"}},
+			WithStrict(nil), WithLogger(logger))
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `level=WARN msg="unknown parameter" source=mock parameter=/path/db/hots`)
+		assert.Contains(t, buf.String(), `level=WARN msg="unknown parameter" source=mock parameter=/path/old/setting`)
+		assert.NotContains(t, buf.String(), "/path/level")
+	})
+
+	t.Run("report", func(t *testing.T) {
+		var reported []string
+		_, err := ParseWithOptions(context.Background(), &config{}, []Source{&mockSource{ps: ps, path: "/path/"}},
+			WithStrict(func(_ context.Context, sourceID string, parameters []string) error {
+				assert.Equal(t, "mock", sourceID)
+				reported = parameters
+				return ErrUnknownParameters
+			}))
+		assert.ErrorIs(t, err, ErrUnknownParameters)
+		assert.Equal(t, []string{"/path/db/hots", "/path/old/setting"}, reported)
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		var reported []string
+		_, err := ParseWithOptions(context.Background(), &struct {
+			Level string `sky:"level"`
+			DB    struct {
+				Host    string `sky:"host,alias:hots"`
+				Setting string `sky:"setting,alias:/path/old/setting,optional"`
+			} `sky:"db"`
+		}{}, []Source{&mockSource{ps: ps, path: "/path/"}},
+			WithStrict(func(_ context.Context, _ string, parameters []string) error {
+				reported = parameters
+				return ErrUnknownParameters
+			}))
+		assert.NoError(t, err)
+		assert.Empty(t, reported)
+	})
+}
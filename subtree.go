@@ -0,0 +1,229 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyLister is implemented by sources that can list the parameters under a path.
+type KeyLister interface {
+	// ListKeys returns the names of all the parameters under the path formed by the parts, recursively. The names are
+	// relative to the path, with their parts separated by '/'.
+	ListKeys(ctx context.Context, parts []string) (keys []string, err error)
+}
+
+// ErrListKeysNotSupported is returned when none of the sources of a field can list the keys of a subtree.
+var ErrListKeysNotSupported = errors.New("no source supports listing keys")
+
+// ErrBadSubtreeIndex is returned when a key under the path of a slice field does not start with a non-negative index,
+// or the indices do not run from 0 without gaps.
+var ErrBadSubtreeIndex = errors.New("invalid index in subtree")
+
+// isSubtree returns true if the type is a map with string keys, or a slice, with struct (or pointer to struct) values
+// that cannot deserialize themselves.
+func isSubtree(t reflect.Type) bool {
+	switch {
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+	case t.Kind() == reflect.Slice:
+	default:
+		return false
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return false
+	}
+
+	return !decodesItself(reflect.New(elem).Elem())
+}
+
+// expandSubtrees replaces the fields populated from a subtree of parameters with the fields of their entries. The
+// entries are found by listing the keys under the path of the field in each of the sources that support it; the
+// first part of each key is used as the key of a map entry, or as the index of a slice entry. Slice entries are ordered
+// by their index. The fields of the entries are extracted as configured by the options, as those of the configuration
+// struct are, and the subtrees nested in the entries are expanded in turn. The returned commit function sets the
+// entries to the fields; it must be called once the fields of the entries have been populated.
+func (o *options) expandSubtrees(ctx context.Context, fields []fieldInfo, sources []Source) (expanded []fieldInfo,
+	commit func(), err error) {
+
+	var commits []func()
+	expanded, commits, err = o.expandNested(ctx, fields, sources, 0)
+	commit = func() {
+		for _, c := range commits {
+			c()
+		}
+	}
+
+	return
+}
+
+// expandNested expands the subtrees of the fields, nested in depth structs, and returns the functions setting their
+// entries, those of the innermost subtrees first, as the entries are copied into the maps and slices of the outer ones.
+func (o *options) expandNested(ctx context.Context, fields []fieldInfo, sources []Source, depth int) (
+	expanded []fieldInfo, commits []func(), err error) {
+
+	for _, field := range fields {
+		if !field.subtree {
+			expanded = append(expanded, field)
+			continue
+		}
+
+		var children []string
+		children, err = listChildren(ctx, field, sources)
+		if err != nil {
+			return
+		}
+
+		t := field.structField.Type()
+		elem := t.Elem()
+		isPtr := elem.Kind() == reflect.Ptr
+		if isPtr {
+			elem = elem.Elem()
+		}
+
+		if t.Kind() == reflect.Slice {
+			if err = sortIndices(field, children); err != nil {
+				return
+			}
+		}
+
+		var values []reflect.Value
+		for i, child := range children {
+			// Extract the fields of the entry, using the child as a part of the name, as listed
+			entry := reflect.New(elem)
+			prefix := append(append([]string{}, field.nameParts...), verbatim(child))
+
+			// The entries are nested in the structs enclosing the field
+			nested := depth + strings.Count(field.path, ".") + 1
+			e := o.extraction()
+			e.maxDepth -= nested
+			if e.maxDepth < 1 {
+				err = fmt.Errorf("%w: field %s is nested deeper than %d structs", ErrMaxDepth, field.path, o.maxDepth)
+				return
+			}
+
+			var inner []fieldInfo
+			if inner, err = e.extract(prefix, entry.Interface(), field.options); err != nil {
+				return
+			}
+			// The fields skipped are the same for all the entries
+			if o.reportSkipped != nil && len(e.skipped) > 0 && i == 0 {
+				o.reportSkipped(e.skipped)
+			}
+
+			// The entries are set once the pointers enclosing the field are, if they are lazy
+			for i := range inner {
+				inner[i].pointers = append(slices.Clip(field.pointers), inner[i].pointers...)
+			}
+
+			var innerCommits []func()
+			if inner, innerCommits, err = o.expandNested(ctx, inner, sources, nested); err != nil {
+				return
+			}
+
+			expanded = append(expanded, inner...)
+			commits = append(commits, innerCommits...)
+
+			if isPtr {
+				values = append(values, entry)
+			} else {
+				values = append(values, entry.Elem())
+			}
+		}
+
+		structField := field.structField
+		commits = append(commits, func() {
+			var entries reflect.Value
+			if t.Kind() == reflect.Slice {
+				entries = reflect.MakeSlice(t, len(children), len(children))
+				for i := range children {
+					entries.Index(i).Set(values[i])
+				}
+			} else {
+				entries = reflect.MakeMapWithSize(t, len(children))
+				for i, child := range children {
+					entries.SetMapIndex(reflect.ValueOf(child).Convert(t.Key()), values[i])
+				}
+			}
+			structField.Set(entries)
+		})
+	}
+
+	return
+}
+
+// listChildren returns the sorted, unique first parts of the keys under the path of the field in all of its sources.
+func listChildren(ctx context.Context, field fieldInfo, sources []Source) (children []string, err error) {
+	seen := make(map[string]bool)
+	listed := false
+
+	for _, source := range sources {
+		if !field.options.fromSource(source.ID()) {
+			continue
+		}
+
+		lister, ok := source.(KeyLister)
+		if !ok {
+			continue
+		}
+		listed = true
+
+		var keys []string
+		keys, err = lister.ListKeys(ctx, append([]string{}, field.nameParts...))
+		if err != nil {
+			err = fmt.Errorf("failed to list keys from source '%s' : %w", source.ID(), err)
+			return
+		}
+
+		for _, key := range keys {
+			child, _, _ := strings.Cut(strings.TrimPrefix(key, "/"), "/")
+			if child != "" && !seen[child] {
+				seen[child] = true
+				children = append(children, child)
+			}
+		}
+	}
+
+	if !listed {
+		err = fmt.Errorf("%w for field %s", ErrListKeysNotSupported, field.options.id)
+		return
+	}
+
+	sort.Strings(children)
+	return
+}
+
+// sortIndices sorts the children of a slice field by their numeric index. The indices must be written without leading
+// zeros or signs, and run from 0 to the number of children less one, so that each entry has exactly one index.
+func sortIndices(field fieldInfo, children []string) error {
+	indices := make(map[string]int, len(children))
+	for _, child := range children {
+		idx, err := strconv.Atoi(child)
+		if err != nil || idx < 0 || strconv.Itoa(idx) != child {
+			return fmt.Errorf("%w for field %s: %q", ErrBadSubtreeIndex, field.options.id, child)
+		}
+		indices[child] = idx
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return indices[children[i]] < indices[children[j]]
+	})
+
+	for i, child := range children {
+		if indices[child] != i {
+			return fmt.Errorf("%w for field %s: missing index %d", ErrBadSubtreeIndex, field.options.id, i)
+		}
+	}
+
+	return nil
+}
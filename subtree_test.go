@@ -0,0 +1,208 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type tenantConfig struct {
+	Host string `sky:"host"`
+	Port int    `sky:"port,default:5432"`
+}
+
+func TestSubtree(t *testing.T) {
+	ps := mockParameterStore{
+		"/app/tenants/acme/host":   "acme.example.com",
+		"/app/tenants/acme/port":   "6432",
+		"/app/tenants/globex/host": "globex.example.com",
+		"/app/name":                "app",
+	}
+
+	t.Run("map of structs", func(t *testing.T) {
+		cfg := &struct {
+			Name    string                   `sky:"name"`
+			Tenants map[string]tenantConfig  `sky:"tenants"`
+			Others  map[string]*tenantConfig `sky:"others"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, &mockSource{ps: ps, path: "/app/"})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, "app", cfg.Name)
+		assert.Equal(t, map[string]tenantConfig{
+			"acme":   {Host: "acme.example.com", Port: 6432},
+			"globex": {Host: "globex.example.com", Port: 5432},
+		}, cfg.Tenants)
+		assert.Equal(t, map[string]*tenantConfig{}, cfg.Others)
+	})
+
+	t.Run("keys of the entries kept as listed", func(t *testing.T) {
+		cfg := &struct {
+			Tenants map[string]tenantConfig `sky:"tenants"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, &mockSource{ps: mockParameterStore{
+			"/app/tenants/MyTenant/host": "my.example.com",
+		}, path: "/app/"})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, map[string]tenantConfig{"MyTenant": {Host: "my.example.com", Port: 5432}}, cfg.Tenants)
+	})
+
+	t.Run("refresh map of pointers to structs", func(t *testing.T) {
+		source := &mockSource{ps: mockParameterStore{
+			"/app/tenants/acme/host": "acme.example.com",
+		}, path: "/app/", refreshable: true}
+
+		cfg := &struct {
+			Tenants map[string]*struct {
+				Host string `sky:"host,refresh:1m"`
+			} `sky:"tenants"`
+		}{}
+
+		r, err := Parse(context.Background(), cfg, false, source)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		source.set("/app/tenants/acme/host", "new.acme.example.com")
+		assert.NoError(t, r.RefreshOnce(context.Background()))
+		assert.Equal(t, "new.acme.example.com", cfg.Tenants["acme"].Host)
+	})
+
+	t.Run("missing required parameter in an entry", func(t *testing.T) {
+		cfg := &struct {
+			Tenants map[string]struct {
+				Host     string `sky:"host"`
+				Password string `sky:"password"`
+			} `sky:"tenants"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, &mockSource{ps: ps, path: "/app/"})
+		assert.ErrorIs(t, err, ErrParameterNotFound)
+	})
+
+	t.Run("entries extracted with the options", func(t *testing.T) {
+		type limits struct {
+			CPU int `sky:"cpu,optional"`
+		}
+		cfg := &struct {
+			Tenants map[string]struct {
+				Host   string    `sky:"host"`
+				Events chan bool `sky:"events"`
+				Limits *limits   `sky:"limits"`
+			} `sky:"tenants"`
+		}{}
+		source := &mockSource{ps: ps, path: "/app/"}
+
+		_, err := Parse(context.Background(), cfg, false, source)
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+
+		_, err = ParseWithOptions(context.Background(), cfg, []Source{source}, WithSkipUnsupported(), WithMaxDepth(2))
+		assert.ErrorIs(t, err, ErrMaxDepth)
+
+		var skipped []SkippedField
+		_, err = ParseWithOptions(context.Background(), cfg, []Source{source}, WithSkipUnsupported(), WithLazyPointers(),
+			WithSkippedFields(func(s []SkippedField) { skipped = append(skipped, s...) }))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "acme.example.com", cfg.Tenants["acme"].Host)
+			assert.Nil(t, cfg.Tenants["acme"].Limits)
+			assert.Len(t, skipped, 1)
+		}
+	})
+
+	t.Run("subtrees nested in the entries", func(t *testing.T) {
+		cfg := &struct {
+			Tenants map[string]struct {
+				Host     string                   `sky:"host"`
+				Replicas []tenantConfig           `sky:"replicas"`
+				Regions  map[string]*tenantConfig `sky:"regions"`
+			} `sky:"tenants"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, &mockSource{ps: mockParameterStore{
+			"/app/tenants/acme/host":            "acme.example.com",
+			"/app/tenants/acme/replicas/0/host": "r0.acme.example.com",
+			"/app/tenants/acme/replicas/1/host": "r1.acme.example.com",
+			"/app/tenants/acme/regions/eu/host": "eu.acme.example.com",
+			"/app/tenants/acme/regions/eu/port": "6432",
+			"/app/tenants/globex/host":          "globex.example.com",
+		}, path: "/app/"})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		acme := cfg.Tenants["acme"]
+		assert.Equal(t, "acme.example.com", acme.Host)
+		assert.Equal(t, []tenantConfig{
+			{Host: "r0.acme.example.com", Port: 5432},
+			{Host: "r1.acme.example.com", Port: 5432},
+		}, acme.Replicas)
+		assert.Equal(t, map[string]*tenantConfig{"eu": {Host: "eu.acme.example.com", Port: 6432}}, acme.Regions)
+		assert.Empty(t, cfg.Tenants["globex"].Replicas)
+	})
+
+	t.Run("no source supports listing keys", func(t *testing.T) {
+		cfg := &struct {
+			Tenants map[string]tenantConfig `sky:"tenants"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, MergeSource(&mockSource{ps: ps, path: "/app/"}))
+		assert.ErrorIs(t, err, ErrListKeysNotSupported)
+	})
+}
+
+func TestSubtreeSlice(t *testing.T) {
+	type endpoint struct {
+		URL    string `sky:"url"`
+		Weight int    `sky:"weight,default:1"`
+	}
+
+	t.Run("slice of structs", func(t *testing.T) {
+		ps := mockParameterStore{
+			"/app/endpoints/0/url":    "https://a.example.com",
+			"/app/endpoints/1/url":    "https://b.example.com",
+			"/app/endpoints/1/weight": "5",
+			"/app/endpoints/3/url":    "https://d.example.com",
+			"/app/endpoints/2/url":    "https://c.example.com",
+		}
+
+		cfg := &struct {
+			Endpoints []endpoint  `sky:"endpoints"`
+			Pointers  []*endpoint `sky:"pointers"`
+		}{}
+
+		_, err := Parse(context.Background(), cfg, false, &mockSource{ps: ps, path: "/app/"})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []endpoint{
+			{URL: "https://a.example.com", Weight: 1},
+			{URL: "https://b.example.com", Weight: 5},
+			{URL: "https://c.example.com", Weight: 1},
+			{URL: "https://d.example.com", Weight: 1},
+		}, cfg.Endpoints)
+		assert.Empty(t, cfg.Pointers)
+	})
+
+	t.Run("bad index", func(t *testing.T) {
+		for name, ps := range map[string]mockParameterStore{
+			"not a number":  {"/app/endpoints/first/url": "https://a.example.com"},
+			"leading zero":  {"/app/endpoints/0/url": "https://a.example.com", "/app/endpoints/01/url": "https://b.example.com"},
+			"missing index": {"/app/endpoints/0/url": "https://a.example.com", "/app/endpoints/2/url": "https://c.example.com"},
+		} {
+			cfg := &struct {
+				Endpoints []endpoint `sky:"endpoints"`
+			}{}
+
+			_, err := Parse(context.Background(), cfg, false, &mockSource{ps: ps, path: "/app/"})
+			assert.ErrorIs(t, err, ErrBadSubtreeIndex, name)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package skyconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownTagOption is returned by ParseTag and reported by AnalyzeStruct for options of the `sky` tag that are not
+// known, such as misspelt ones, which Parse ignores.
+var ErrUnknownTagOption = errors.New("unknown tag option")
+
+// ErrDuplicateID is reported by AnalyzeStruct for fields sharing the same ID, which can no longer be told apart in
+// updates, Pause, Resume and Watch.
+var ErrDuplicateID = errors.New("duplicate field id")
+
+// tagFlagOptions are the options of the `sky` tag that take no value; `deprecated` takes an optional message.
+var tagFlagOptions = []string{"optional", "flatten", "squash", "secret", "manual", "allowempty", "deprecated"}
+
+// tagValueOptions are the options of the `sky` tag that take a value.
+var tagValueOptions = []string{"default", "defaultfrom", "source", "refresh", "id", "desc", "deprecated", "ondelete", "alias",
+	"transform", "encoding", "version", "label", "when", "profiles"}
+
+// ParseTag parses the value of a `sky` tag, as Parse does, and returns the key and the options it sets; the options are
+// not inherited from enclosing structs, and the ID is only set if given by the `id` tag. Unlike Parse, which ignores
+// the options it does not know, ParseTag fails on unknown options with ErrUnknownTagOption, and on options missing
+// their value or given one they do not take, so that generated code and linters can validate the tags.
+func ParseTag(tag string) (key string, options FieldOptions, err error) {
+	var o fieldOptions
+	if key, o, err = parseTagOptions(tag, fieldOptions{}, true); err != nil {
+		return
+	}
+	if strings.HasPrefix(key, "/") {
+		o.absolute = key
+	}
+
+	options = o.export()
+	return
+}
+
+// TagProblem is a problem with the `sky` tag of a field of a configuration struct, reported by AnalyzeStruct.
+type TagProblem struct {
+	// Field is the name of the field, qualified with those of the enclosing structs, as in "DB.Port".
+	Field string
+	// Tag is the value of the `sky` tag of the field.
+	Tag string
+	// Err describes the problem.
+	Err error
+}
+
+// String describes the problem, as in "DB.Port: unknown tag option "optinal"".
+func (p TagProblem) String() string {
+	return p.Field + ": " + p.Err.Error()
+}
+
+// AnalyzeStruct checks the tags of all the fields of the configuration struct, or pointer to it, and of the structs
+// it nests, and reports all the problems found at once, in the order of the fields, rather than failing on the first
+// one as Parse does. The tags are parsed as by ParseTag. Besides, it reports fields sharing the same ID, `flatten` on
+// fields that are not structs, absolute keys of structs and subtrees, tagged unexported fields, fields of unsupported
+// types, and recursive structs. WithUntagged and WithMaxDepth apply. The fields of the structs of subtrees are checked
+// as well, their IDs being scoped to each entry.
+//
+// It only returns an error, ErrInvalidStruct, if the configuration is not a struct.
+func AnalyzeStruct(cfg interface{}, opts ...Option) (problems []TagProblem, err error) {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrInvalidStruct
+	}
+
+	o := makeOptions(opts)
+	a := &analysis{withUntagged: o.withUntagged, maxDepth: o.maxDepth, ids: make(map[string]string)}
+	a.analyze(t, fieldOptions{})
+
+	return a.problems, nil
+}
+
+// analysis holds the state of the analysis of the tags of a configuration struct.
+type analysis struct {
+	withUntagged bool
+	maxDepth     int
+	types        []reflect.Type    // the types of the structs being analysed, outermost first
+	names        []string          // the names of the fields of the structs being analysed, outermost first
+	ids          map[string]string // the paths of the fields, by their IDs
+	problems     []TagProblem
+}
+
+// analyze checks the tags of the fields of the struct type, recursing into nested structs.
+func (a *analysis) analyze(t reflect.Type, parentOptions fieldOptions) {
+	a.types = append(a.types, t)
+	defer func() {
+		a.types = a.types[:len(a.types)-1]
+	}()
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag, tagged := structField.Tag.Lookup("sky")
+		if tag == "-" || !tagged && !a.withUntagged {
+			continue
+		}
+
+		path := strings.Join(append(slices.Clip(a.names), structField.Name), ".")
+		report := func(err error) {
+			a.problems = append(a.problems, TagProblem{Field: path, Tag: tag, Err: err})
+		}
+
+		if !structField.IsExported() {
+			if tagged && !structField.Anonymous {
+				report(errors.New("unexported field cannot be set"))
+			}
+			continue
+		}
+
+		key, options := a.parseTag(tag, parentOptions, report)
+		absolute := strings.HasPrefix(key, "/")
+		if key == "" {
+			key = structField.Name
+		}
+		if options.id == "" {
+			options.id = key
+		}
+
+		ft := structField.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft.Kind() == reflect.Struct && !decodesItself(reflect.New(ft).Elem()):
+			switch {
+			case absolute:
+				report(errors.New("absolute key of a struct"))
+			case slices.Contains(a.types, ft):
+				report(fmt.Errorf("%w: field of type %s", ErrRecursiveStruct, structField.Type))
+				continue
+			case len(a.types) >= a.maxDepth:
+				report(fmt.Errorf("%w: nested deeper than %d structs", ErrMaxDepth, a.maxDepth))
+				continue
+			}
+
+			a.names = append(a.names, structField.Name)
+			a.analyze(ft, options)
+			a.names = a.names[:len(a.names)-1]
+
+		case isSubtree(structField.Type):
+			if absolute {
+				report(errors.New("absolute key of a subtree"))
+			}
+			if options.flatten {
+				report(errors.New("flatten on a subtree"))
+			}
+			a.addID(options.id, path, report)
+
+			// The fields of the entries are keyed under each entry, so their IDs only need to be unique within it
+			elem := structField.Type.Elem()
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if !slices.Contains(a.types, elem) {
+				ids := a.ids
+				a.ids = make(map[string]string)
+				a.names = append(a.names, structField.Name+"[]")
+				a.analyze(elem, options)
+				a.names = a.names[:len(a.names)-1]
+				a.ids = ids
+			}
+
+		default:
+			if options.flatten {
+				report(fmt.Errorf("flatten on a field of type %s, not a struct", structField.Type))
+			}
+			if !decodable(reflect.New(structField.Type).Elem()) {
+				report(fmt.Errorf("%w: %s", ErrUnsupportedType, structField.Type))
+			}
+			a.addID(options.id, path, report)
+		}
+	}
+}
+
+// parseTag parses the tag strictly, reporting the problems of each of its options, and returns the key and the
+// options it sets, ignoring those in error.
+func (a *analysis) parseTag(tag string, parentOptions fieldOptions, report func(err error)) (key string,
+	options fieldOptions) {
+
+	// The options set before any in error are still known
+	key, options, _ = parseTag(tag, parentOptions)
+
+	// Parse the options one by one, to report all those in error
+	parts := strings.Split(tag, ",")
+	failed := false
+	var err error
+	for _, part := range parts[1:] {
+		if _, _, err = parseTagOptions(key+","+part, parentOptions, true); err != nil {
+			report(err)
+			failed = true
+		}
+	}
+
+	// Report the conflicts between the options
+	if !failed {
+		if _, _, err = parseTagOptions(tag, parentOptions, true); err != nil {
+			report(err)
+		}
+	}
+
+	return
+}
+
+// addID records the ID of the field at the path, reporting it if another field has the same ID.
+func (a *analysis) addID(id, path string, report func(err error)) {
+	if other, ok := a.ids[id]; ok {
+		report(fmt.Errorf("%w %q, also that of %s", ErrDuplicateID, id, other))
+		return
+	}
+
+	a.ids[id] = path
+}
@@ -0,0 +1,147 @@
+package skyconf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		tag         string
+		wantKey     string
+		wantOptions FieldOptions
+		wantErr     string
+	}{
+		{
+			name:        "options",
+			tag:         "port,default:5432,refresh:1m,id:db_port,optional",
+			wantKey:     "port",
+			wantOptions: FieldOptions{ID: "db_port", Default: "5432", Refresh: time.Minute, Optional: true},
+		},
+		{
+			name:        "absolute key",
+			tag:         "/shared/region,secret",
+			wantKey:     "/shared/region",
+			wantOptions: FieldOptions{Absolute: "/shared/region", Secret: true},
+		},
+		{
+			name:        "deprecated",
+			tag:         "old,deprecated",
+			wantKey:     "old",
+			wantOptions: FieldOptions{Deprecated: "parameter is deprecated"},
+		},
+		{
+			name:    "unknown option",
+			tag:     "port,optinal",
+			wantErr: `unknown tag option "optinal"`,
+		},
+		{
+			name:    "unknown option with value",
+			tag:     "port,refesh:1m",
+			wantErr: `unknown tag option "refesh"`,
+		},
+		{
+			name:    "missing value",
+			tag:     "port,default",
+			wantErr: `tag "default" missing a value`,
+		},
+		{
+			name:    "empty value",
+			tag:     "port,source:",
+			wantErr: `tag "source" missing a value`,
+		},
+		{
+			name:    "unexpected value",
+			tag:     "port,secret:true",
+			wantErr: `tag "secret" does not take a value`,
+		},
+		{
+			name:    "bad duration",
+			tag:     "port,refresh:soon",
+			wantErr: `invalid duration "soon"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, options, err := ParseTag(tt.tag)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantOptions, options)
+		})
+	}
+
+	// Parse ignores unknown options
+	_, _, err := parseTag("port,optinal", fieldOptions{})
+	assert.NoError(t, err)
+	_, _, err = ParseTag("port,optinal")
+	assert.ErrorIs(t, err, ErrUnknownTagOption)
+}
+
+type analyzedNode struct {
+	Name  string        `sky:"name"`
+	Child *analyzedNode `sky:"child"`
+}
+
+func TestAnalyzeStruct(t *testing.T) {
+	type tenant struct {
+		Name string `sky:"name"`
+		Key  string `sky:"key,secert"`
+	}
+
+	type config struct {
+		Host string `sky:"host,optinal,refresh:soon"`
+		DB   struct {
+			Host  string `sky:"host,id:db_host"`
+			Port  int    `sky:"port,flatten"`
+			Label string `sky:"label,version:2,label:current"`
+		} `sky:"db"`
+		Port     int               `sky:"port,id:host"`
+		Tenants  map[string]tenant `sky:"tenants"`
+		Shared   struct{}          `sky:"/shared"`
+		Events   chan string       `sky:"events"`
+		Tree     analyzedNode      `sky:"tree"`
+		private  string            `sky:"private"`
+		Ignored  string            `sky:"-"`
+		Untagged string
+	}
+
+	problems, err := AnalyzeStruct(config{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{
+		`Host: unknown tag option "optinal"`,
+		`Host: invalid duration "soon": time: invalid duration "soon"`,
+		`DB.Port: flatten on a field of type int, not a struct`,
+		`DB.Label: tag "label" conflicts with a version or label`,
+		`Port: duplicate field id "host", also that of Host`,
+		`Tenants[].Key: unknown tag option "secert"`,
+		`Shared: absolute key of a struct`,
+		`Events: unsupported field type: chan string`,
+		`Tree.Child: recursive struct type: field of type *skyconf.analyzedNode`,
+		`private: unexported field cannot be set`,
+	}, got)
+	assert.ErrorIs(t, problems[0].Err, ErrUnknownTagOption)
+	assert.ErrorIs(t, problems[4].Err, ErrDuplicateID)
+	assert.Equal(t, "port,id:host", problems[4].Tag)
+
+	// The untagged fields are analysed with WithUntagged
+	problems, err = AnalyzeStruct(&struct{ Events chan string }{}, WithUntagged())
+	assert.NoError(t, err)
+	assert.Len(t, problems, 1)
+
+	_, err = AnalyzeStruct("config")
+	assert.ErrorIs(t, err, ErrInvalidStruct)
+}
@@ -0,0 +1,136 @@
+package skyconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TemplateFormat is the format of a configuration template; see ExportTemplate.
+type TemplateFormat int
+
+const (
+	// TemplateEnv formats the template as an env-file, with a KEY=value line per field.
+	TemplateEnv TemplateFormat = iota
+	// TemplateYAML formats the template as a YAML document, nesting the fields as the parameters are nested in SSM.
+	TemplateYAML
+)
+
+// ErrUnknownTemplateFormat is returned when a template format is not known.
+var ErrUnknownTemplateFormat = errors.New("unknown template format")
+
+// ExportTemplate returns a skeleton configuration document for the provided configuration struct, with an entry for
+// each field named after its parameter, set to its default value, if any, and preceded by the documentation of the
+// field given by the `desc` or `skydoc` tags as a comment. Values of fields tagged with `secret` are left empty. Fields populated
+// from subtrees of parameters are noted with a comment in env-files, and left empty in YAML documents.
+func ExportTemplate(cfg interface{}, withUntagged bool, format TemplateFormat) (str string, err error) {
+	var fields []fieldInfo
+	fields, err = extractFields(withUntagged, nil, cfg, fieldOptions{})
+	if err != nil {
+		return
+	}
+
+	switch format {
+	case TemplateEnv:
+		str = envTemplate(fields)
+	case TemplateYAML:
+		str = yamlTemplate(fields)
+	default:
+		err = fmt.Errorf("%w: %d", ErrUnknownTemplateFormat, format)
+	}
+
+	return
+}
+
+// templateKey returns the snake case parts of the name of the parameter of the field.
+func (f fieldInfo) templateKey() []string {
+	parts := make([]string, len(f.nameParts))
+	for i, part := range f.nameParts {
+		parts[i] = ToSnakeCase(part)
+	}
+
+	return parts
+}
+
+// templateValue returns the value of the field in a template; its default value, unless the field is a secret.
+func (f fieldInfo) templateValue() string {
+	if f.options.secret {
+		return ""
+	}
+
+	return f.options.defaultValue
+}
+
+// writeComment writes the documentation of the field, if any, as a comment.
+func (f fieldInfo) writeComment(sb *strings.Builder, indent string) {
+	if f.options.doc == "" {
+		return
+	}
+
+	for _, line := range strings.Split(f.options.doc, "\n") {
+		sb.WriteString(indent + "# " + line + "\n")
+	}
+}
+
+func envTemplate(fields []fieldInfo) string {
+	var sb strings.Builder
+	for _, field := range fields {
+		field.writeComment(&sb, "")
+
+		key := strings.ToUpper(strings.Join(field.templateKey(), "_"))
+		if field.subtree {
+			sb.WriteString("# " + key + ": subtree of parameters\n")
+			continue
+		}
+
+		// Quote the values that would otherwise be misread
+		value := field.templateValue()
+		if strings.ContainsAny(value, " \t#'\"\\$") {
+			value = strconv.Quote(value)
+		}
+
+		sb.WriteString(key + "=" + value + "\n")
+	}
+
+	return sb.String()
+}
+
+func yamlTemplate(fields []fieldInfo) string {
+	var sb strings.Builder
+
+	// The parts of the key of the previous field, to only open the mappings that are not already open
+	var open []string
+	for _, field := range fields {
+		parts := field.templateKey()
+
+		// Find the number of mappings shared with the previous field
+		common := 0
+		for common < len(open) && common < len(parts)-1 && open[common] == parts[common] {
+			common++
+		}
+
+		for i := common; i < len(parts)-1; i++ {
+			sb.WriteString(strings.Repeat("  ", i) + parts[i] + ":\n")
+		}
+		open = parts[:len(parts)-1]
+
+		indent := strings.Repeat("  ", len(parts)-1)
+		field.writeComment(&sb, indent)
+
+		var value string
+		switch {
+		case field.subtree && field.structField.Kind() == reflect.Map:
+			value = "{}"
+		case field.subtree:
+			value = "[]"
+		default:
+			value = strconv.Quote(field.templateValue())
+		}
+
+		sb.WriteString(indent + parts[len(parts)-1] + ": " + value + "\n")
+	}
+
+	return sb.String()
+}
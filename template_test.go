@@ -0,0 +1,76 @@
+package skyconf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExportTemplate(t *testing.T) {
+	type backend struct {
+		URL string `sky:"url"`
+	}
+
+	cfg := &struct {
+		Level string `sky:",default:info" skydoc:"Log level; one of debug, info, warn or error."`
+		DB    struct {
+			Host     string `sky:",default:localhost"`
+			Password string `sky:",default:changeme,secret" skydoc:"Password of the database user."`
+			Options  string `sky:",default:sslmode=require connect_timeout=5"`
+		} `sky:"db"`
+		Backends map[string]backend `sky:"backends"`
+		Replicas []backend          `sky:"replicas"`
+	}{}
+
+	tests := []struct {
+		name    string
+		format  TemplateFormat
+		wantStr string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "env",
+			format: TemplateEnv,
+			wantStr: "# Log level; one of debug, info, warn or error.\n" +
+				"LEVEL=info\n" +
+				"DB_HOST=localhost\n" +
+				"# Password of the database user.\n" +
+				"DB_PASSWORD=\n" +
+				"DB_OPTIONS=\"sslmode=require connect_timeout=5\"\n" +
+				"# BACKENDS: subtree of parameters\n" +
+				"# REPLICAS: subtree of parameters\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "yaml",
+			format: TemplateYAML,
+			wantStr: "# Log level; one of debug, info, warn or error.\n" +
+				"level: \"info\"\n" +
+				"db:\n" +
+				"  host: \"localhost\"\n" +
+				"  # Password of the database user.\n" +
+				"  password: \"\"\n" +
+				"  options: \"sslmode=require connect_timeout=5\"\n" +
+				"backends: {}\n" +
+				"replicas: []\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "unknown format",
+			format: TemplateFormat(42),
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrUnknownTemplateFormat)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStr, err := ExportTemplate(cfg, false, tt.format)
+			if !tt.wantErr(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.wantStr, gotStr)
+		})
+	}
+}
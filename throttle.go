@@ -0,0 +1,97 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/smithy-go"
+	"time"
+)
+
+const (
+	// defaultThrottleRetries is the number of times a throttled request is retried, unless set with
+	// WithThrottleRetries.
+	defaultThrottleRetries = 5
+	// minThrottleInterval is the interval between requests a source slows down to when first throttled, unless it is
+	// already paced more slowly.
+	minThrottleInterval = 100 * time.Millisecond
+	// maxThrottleInterval caps the interval between requests a throttled source slows down to.
+	maxThrottleInterval = 10 * time.Second
+)
+
+// WithThrottleRetries sets the number of times a request throttled by the source is retried before failing; 5 by
+// default, 0 to fail on the first throttling error. Whether retried or not, each throttling error slows down the pace
+// of the requests made by the source, across Parse and refreshes, which speeds up again as requests succeed.
+//
+// Throttling is detected for SSM sources, from the ThrottlingException errors of the SSM API.
+func WithThrottleRetries(n int) SourceOption {
+	return func(o *sourceOptions) {
+		o.throttleRetries = max(n, 0)
+	}
+}
+
+// ThrottleMetrics is implemented by Metrics that also record the requests throttled by the sources, to tell when the
+// configuration outgrows the quotas of the sources.
+type ThrottleMetrics interface {
+	// Throttled records that a request to the source was throttled, and the interval between requests the source
+	// slowed down to.
+	Throttled(sourceID string, interval time.Duration)
+}
+
+// isSSMThrottling returns true if the error is a ThrottlingException of the SSM API.
+func isSSMThrottling(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "ThrottlingException"
+}
+
+// throttleReporterKey is the context key of the function reporting the throttled requests of a fetch.
+type throttleReporterKey struct{}
+
+// withThrottleReporter returns a context reporting the requests throttled while fetching from the source to the
+// metrics and the logger.
+func (o *options) withThrottleReporter(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, throttleReporterKey{}, func(interval time.Duration, err error) {
+		o.logger.WarnContext(ctx, "source throttled, slowing down",
+			"source", source.ID(), "interval", interval, "error", err)
+		if m, ok := o.metrics.(ThrottleMetrics); ok {
+			m.Throttled(source.ID(), interval)
+		}
+	})
+}
+
+// reportThrottled reports a throttled request, and the interval between requests slowed down to, using the reporter of
+// the context, if any.
+func reportThrottled(ctx context.Context, interval time.Duration, err error) {
+	if report, ok := ctx.Value(throttleReporterKey{}).(func(time.Duration, error)); ok {
+		report(interval, err)
+	}
+}
+
+// throttled slows down the pace of the requests after one was throttled, doubling the interval between them, and
+// returns the new interval.
+func (l *limiter) throttled() time.Duration {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.interval = min(max(2*l.interval, minThrottleInterval), maxThrottleInterval)
+
+	// Hold back the next request for the new interval, to let the quota of the source recover
+	l.next = time.Now().Add(l.interval)
+
+	return l.interval
+}
+
+// succeeded speeds up the pace of the requests after one succeeded, shortening the interval between them by a tenth
+// until it is back to the one set with WithMaxQPS.
+func (l *limiter) succeeded() {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if l.interval == l.base {
+		return
+	}
+
+	l.interval -= l.interval / 10
+	if l.interval < max(l.base, minThrottleInterval) {
+		l.interval = l.base
+	}
+}
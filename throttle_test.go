@@ -0,0 +1,130 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmpkg "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// throttlingSSM is an http client throttling the first calls to the SSM API, serving the following ones using fakeSSM.
+type throttlingSSM struct {
+	*fakeSSM
+	throttle atomic.Int32 // the number of calls left to throttle
+}
+
+func (f *throttlingSSM) Do(req *http.Request) (*http.Response, error) {
+	if f.throttle.Add(-1) >= 0 {
+		return f.response(http.StatusBadRequest, map[string]string{
+			"__type":  "ThrottlingException",
+			"message": "Rate exceeded",
+		})
+	}
+
+	return f.fakeSSM.Do(req)
+}
+
+func (f *throttlingSSM) client() *ssmpkg.Client {
+	return ssmpkg.New(ssmpkg.Options{
+		Region:      "eu-west-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  f,
+		Retryer:     aws.NopRetryer{},
+	})
+}
+
+func TestLimiterThrottling(t *testing.T) {
+	throttling := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+	newThrottledLimiter := func(opts ...SourceOption) *limiter {
+		l := newLimiter(makeSourceOptions(opts))
+		l.throttling = isSSMThrottling
+		return l
+	}
+
+	t.Run("retries and slows down", func(t *testing.T) {
+		l := newThrottledLimiter()
+
+		var reported []time.Duration
+		ctx := context.WithValue(context.Background(), throttleReporterKey{}, func(d time.Duration, err error) {
+			assert.ErrorIs(t, err, throttling)
+			reported = append(reported, d)
+		})
+
+		calls := 0
+		start := time.Now()
+		err := l.do(ctx, func(ctx context.Context) error {
+			if calls++; calls <= 2 {
+				return throttling
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, reported)
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+
+		// The success speeds the requests up again
+		assert.Equal(t, 180*time.Millisecond, l.interval)
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		l := newThrottledLimiter(WithThrottleRetries(1))
+		calls := 0
+		err := l.do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return throttling
+		})
+		assert.ErrorIs(t, err, throttling)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		l := newThrottledLimiter()
+		calls := 0
+		err := l.do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 1, calls)
+		assert.Zero(t, l.interval)
+	})
+
+	t.Run("recovers the max qps", func(t *testing.T) {
+		l := newThrottledLimiter(WithMaxQPS(5))
+		assert.Equal(t, 400*time.Millisecond, l.throttled())
+		assert.Equal(t, maxThrottleInterval, func() time.Duration {
+			for i := 0; i < 10; i++ {
+				l.throttled()
+			}
+			return l.interval
+		}())
+
+		for i := 0; i < 100; i++ {
+			l.succeeded()
+		}
+		assert.Equal(t, 200*time.Millisecond, l.interval)
+	})
+}
+
+func TestSSMThrottling(t *testing.T) {
+	fake := &throttlingSSM{fakeSSM: newFakeSSM(map[string]*fakeSSMParameter{"/path/param1": {Value: "value1"}})}
+	fake.throttle.Store(2)
+
+	metrics := &mockMetrics{}
+	var cfg struct {
+		Param1 string `sky:"param1"`
+	}
+	_, err := ParseWithOptions(context.Background(), &cfg, []Source{SSMSource(fake.client(), "/path")},
+		WithMetrics(metrics))
+	assert.NoError(t, err)
+	assert.Equal(t, "value1", cfg.Param1)
+	assert.Equal(t, map[string][]time.Duration{"ssm": {100 * time.Millisecond, 200 * time.Millisecond}},
+		metrics.throttle)
+}
@@ -0,0 +1,42 @@
+package skyconf
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/redmatter/go-skyconf"
+
+// Span attribute keys.
+const (
+	attrSourceID = attribute.Key("skyconf.source.id")
+	attrKeyCount = attribute.Key("skyconf.keys.count")
+)
+
+// WithTracerProvider sets the OpenTelemetry TracerProvider used to create spans around Parse and each fetch from a
+// source. No spans are recorded unless a TracerProvider is provided.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracer = tp.Tracer(tracerName)
+	}
+}
+
+func defaultTracer() trace.Tracer {
+	return noop.NewTracerProvider().Tracer(tracerName)
+}
+
+// startSpan starts a new span, returning a function that ends it after recording the error, if any.
+func (o *options) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
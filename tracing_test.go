@@ -0,0 +1,47 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	cfg := &struct {
+		Param1 string `sky:"param1"`
+		Param2 string `sky:"an_error,source:failing"`
+	}{}
+
+	_, err := ParseWithOptions(context.Background(), cfg, []Source{
+		&mockSource{ps: mockParameterStore{"/path/param1": "value1"}, path: "/path/", id: "working"},
+		&mockSource{ps: mockParameterStore{}, path: "/path/", id: "failing"},
+	}, WithTracerProvider(tp))
+	assert.ErrorIs(t, err, errMockSourceError)
+
+	spans := sr.Ended()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+
+	// Spans end in order; fetches first, then the parse
+	working, failing, parse := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "skyconf.Source", working.Name())
+	assert.Contains(t, working.Attributes(), attrSourceID.String("working"))
+	assert.Contains(t, working.Attributes(), attrKeyCount.Int(1))
+	assert.Equal(t, codes.Unset, working.Status().Code)
+	assert.Equal(t, parse.SpanContext().SpanID(), working.Parent().SpanID())
+
+	assert.Equal(t, "skyconf.Source", failing.Name())
+	assert.Contains(t, failing.Attributes(), attrSourceID.String("failing"))
+	assert.Equal(t, codes.Error, failing.Status().Code)
+
+	assert.Equal(t, "skyconf.Parse", parse.Name())
+	assert.Equal(t, codes.Error, parse.Status().Code)
+}
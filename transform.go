@@ -0,0 +1,123 @@
+package skyconf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ValueTransformer transforms a value obtained from a source before it is set to a field; for example, to decode or
+// decrypt it.
+type ValueTransformer interface {
+	Transform(ctx context.Context, value string) (string, error)
+}
+
+// TransformerFunc is a function that implements ValueTransformer.
+type TransformerFunc func(ctx context.Context, value string) (string, error)
+
+// Transform calls f(ctx, value).
+func (f TransformerFunc) Transform(ctx context.Context, value string) (string, error) {
+	return f(ctx, value)
+}
+
+// ErrUnknownTransformer is returned when a field is tagged with a transformer that has not been registered.
+var ErrUnknownTransformer = errors.New("unknown transformer")
+
+// ErrTransformValue is returned when a value could not be transformed.
+var ErrTransformValue = errors.New("failed to transform value")
+
+// builtinTransformers are the transformers available to the `transform` tag without registration.
+var builtinTransformers = map[string]ValueTransformer{
+	"base64": TransformerFunc(func(_ context.Context, value string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(value)
+		return string(b), err
+	}),
+	"hex": TransformerFunc(func(_ context.Context, value string) (string, error) {
+		b, err := hex.DecodeString(value)
+		return string(b), err
+	}),
+	"gzip": TransformerFunc(func(_ context.Context, value string) (string, error) {
+		r, err := gzip.NewReader(bytes.NewReader([]byte(value)))
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		return string(b), err
+	}),
+}
+
+// WithValueTransformer sets a transformer applied to every value obtained from the sources, before any transformers
+// specified using the `transform` tag.
+func WithValueTransformer(t ValueTransformer) Option {
+	return func(o *options) {
+		o.transformer = t
+	}
+}
+
+// WithNamedTransformer registers a transformer that fields can refer to by name using the `transform` tag. Registered
+// transformers take precedence over the built-in ones: base64, hex and gzip.
+func WithNamedTransformer(name string, t ValueTransformer) Option {
+	return func(o *options) {
+		if o.transformers == nil {
+			o.transformers = make(map[string]ValueTransformer)
+		}
+		o.transformers[name] = t
+	}
+}
+
+// namedTransformer returns the transformer registered under the name.
+func (o *options) namedTransformer(name string) (ValueTransformer, error) {
+	if t, ok := o.transformers[name]; ok {
+		return t, nil
+	}
+
+	if t, ok := builtinTransformers[name]; ok {
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownTransformer, name)
+}
+
+// checkTransformers ensures all the transformers the fields refer to are available.
+func (o *options) checkTransformers(fields []fieldInfo) error {
+	for _, field := range fields {
+		for _, name := range field.options.transform {
+			if _, err := o.namedTransformer(name); err != nil {
+				return fmt.Errorf("field %s: %w", field.options.id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// transform applies the global transformer, followed by the transformers of the field, to the value.
+func (o *options) transform(ctx context.Context, field fieldInfo, value string) (string, error) {
+	var err error
+
+	if o.transformer != nil {
+		if value, err = o.transformer.Transform(ctx, value); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrTransformValue, err)
+		}
+	}
+
+	for _, name := range field.options.transform {
+		var t ValueTransformer
+		if t, err = o.namedTransformer(name); err != nil {
+			return "", err
+		}
+
+		if value, err = t.Transform(ctx, value); err != nil {
+			return "", fmt.Errorf("%w using %s: %w", ErrTransformValue, name, err)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,127 @@
+package skyconf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransform(t *testing.T) {
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte("compressed"))
+	_ = w.Close()
+
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/pem":        base64.StdEncoding.EncodeToString([]byte(pem)),
+			"/path/compressed": base64.StdEncoding.EncodeToString(gz.Bytes()),
+			"/path/hex":        "68656c6c6f",
+			"/path/shout":      "quiet",
+			"/path/bad":        "not base64!",
+		},
+		path: "/path/",
+	}
+
+	upper := TransformerFunc(func(_ context.Context, value string) (string, error) {
+		return strings.ToUpper(value), nil
+	})
+
+	t.Run("built-in and named transformers", func(t *testing.T) {
+		cfg := &struct {
+			PEM        string `sky:"pem,transform:base64"`
+			Compressed string `sky:"compressed,transform:base64|gzip"`
+			Hex        string `sky:"hex,transform:hex"`
+			Shout      string `sky:"shout,transform:upper"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithNamedTransformer("upper", upper))
+		if assert.NoError(t, err) {
+			assert.Equal(t, pem, cfg.PEM)
+			assert.Equal(t, "compressed", cfg.Compressed)
+			assert.Equal(t, "hello", cfg.Hex)
+			assert.Equal(t, "QUIET", cfg.Shout)
+		}
+	})
+
+	t.Run("global transformer", func(t *testing.T) {
+		cfg := &struct {
+			Shout string `sky:"shout"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithValueTransformer(upper))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "QUIET", cfg.Shout)
+		}
+	})
+
+	t.Run("unknown transformer", func(t *testing.T) {
+		cfg := &struct {
+			Shout string `sky:"shout,transform:upper"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{source})
+		assert.ErrorIs(t, err, ErrUnknownTransformer)
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		cfg := &struct {
+			Bad string `sky:"bad,transform:base64"`
+		}{}
+
+		_, err := ParseWithOptions(context.Background(), cfg, []Source{source})
+		assert.ErrorIs(t, err, ErrTransformValue)
+		assert.ErrorIs(t, err, ErrBadFieldValue)
+	})
+}
+
+func TestTransformWithoutLocks(t *testing.T) {
+	source := &mockSource{ps: mockParameterStore{"/path/secret": "v1"}, path: "/path/", refreshable: true}
+
+	// The transformer blocks until released once the value changes
+	release := make(chan struct{})
+	decrypt := TransformerFunc(func(_ context.Context, value string) (string, error) {
+		if value != "v1" {
+			<-release
+		}
+		return value, nil
+	})
+
+	cfg := &struct {
+		Secret string `sky:"secret,refresh:1m,transform:decrypt"`
+	}{}
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithNamedTransformer("decrypt", decrypt))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	source.set("/path/secret", "v2")
+	done := make(chan error)
+	go func() {
+		done <- r.RefreshOnce(context.Background())
+	}()
+
+	// The state of the fields can be read while the value is transformed
+	time.Sleep(10 * time.Millisecond)
+	status := make(chan []FieldStatus)
+	go func() {
+		status <- r.Status()
+	}()
+	select {
+	case s := <-status:
+		assert.Equal(t, "secret", s[0].ID)
+	case <-time.After(time.Second):
+		assert.Fail(t, "status blocked by the transformer")
+	}
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, "v2", cfg.Secret)
+}
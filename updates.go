@@ -0,0 +1,95 @@
+package skyconf
+
+import (
+	"sync"
+)
+
+// WithLosslessUpdates makes the Refresher queue update notifications until they are received, instead of dropping
+// them when there is no receiver ready. Notifications for a field already pending delivery are coalesced, so the queue
+// never holds more than one notification per field.
+func WithLosslessUpdates() Option {
+	return func(o *options) {
+		o.losslessUpdates = true
+	}
+}
+
+// updateQueue is an unbounded queue of field IDs that coalesces the IDs pending delivery.
+type updateQueue struct {
+	m       sync.Mutex
+	ids     []string
+	pending map[string]bool
+	signal  chan struct{} // signalled when an ID is pushed
+	quit    chan struct{} // closed to stop the delivery
+	done    chan struct{} // closed when the delivery has stopped
+}
+
+func newUpdateQueue() *updateQueue {
+	return &updateQueue{
+		pending: make(map[string]bool),
+		signal:  make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// push adds the ID to the queue, unless it is already pending delivery.
+func (q *updateQueue) push(id string) {
+	q.m.Lock()
+	if !q.pending[id] {
+		q.pending[id] = true
+		q.ids = append(q.ids, id)
+	}
+	q.m.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the ID at the front of the queue.
+func (q *updateQueue) pop() (id string, ok bool) {
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	if len(q.ids) == 0 {
+		return
+	}
+
+	id, ok = q.ids[0], true
+	q.ids = q.ids[1:]
+	delete(q.pending, id)
+
+	return
+}
+
+// deliver sends the queued IDs to the channel, in order, until stop is called.
+func (q *updateQueue) deliver(out chan<- string) {
+	defer close(q.done)
+
+	for {
+		id, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.signal:
+				continue
+			case <-q.quit:
+				return
+			}
+		}
+
+		select {
+		case out <- id:
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+// stop stops the delivery, waiting for it to return if it was started.
+func (q *updateQueue) stop(started bool) {
+	close(q.quit)
+	if started {
+		<-q.done
+	}
+}
@@ -0,0 +1,75 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUpdateQueue(t *testing.T) {
+	q := newUpdateQueue()
+	q.push("a")
+	q.push("b")
+	q.push("a") // coalesced with the pending "a"
+
+	out := make(chan string)
+	go q.deliver(out)
+
+	assert.Equal(t, "a", <-out)
+	assert.Equal(t, "b", <-out)
+
+	q.push("a")
+	assert.Equal(t, "a", <-out)
+
+	q.stop(true)
+}
+
+func TestLosslessUpdates(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/param1": "value1",
+			"/path/param2": "value2",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1m"`
+		Param2 string `sky:",refresh:1m"`
+	}{}
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithLosslessUpdates())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Updates made before Refresh is called are delivered too
+	source.set("/path/param1", "new-value1")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	updates := r.Refresh(context.Background(), nil)
+
+	source.set("/path/param2", "new-value2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	// Nobody has been listening for a while
+	time.Sleep(10 * time.Millisecond)
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		select {
+		case id := <-updates:
+			got = append(got, id)
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for updates")
+			return
+		}
+	}
+	assert.Equal(t, []string{"Param1", "Param2"}, got)
+
+	assert.NoError(t, r.Close())
+	_, ok := <-updates
+	assert.False(t, ok)
+}
@@ -0,0 +1,167 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// ErrValidation is returned when a value found on refresh is rejected by a validator; see WithValidator.
+var ErrValidation = errors.New("value rejected by validator")
+
+// Validator checks the values of a group of fields found on refresh before they are set; for example, that a new
+// database host can be connected to. The values are keyed by the IDs of the fields, and decoded to their types.
+type Validator interface {
+	Validate(ctx context.Context, values map[string]any) error
+}
+
+// ValidatorFunc is a function that implements Validator.
+type ValidatorFunc func(ctx context.Context, values map[string]any) error
+
+// Validate calls f(ctx, values).
+func (f ValidatorFunc) Validate(ctx context.Context, values map[string]any) error {
+	return f(ctx, values)
+}
+
+// WithValidator registers the validator for the fields with the given IDs, validated together. When any of them
+// changes on refresh, including the values applied using Refresher.Receive, pushed by a PushSource, or staged and
+// applied using Refresher.Apply, the validator is called with the new values of the fields, and the current values of
+// those that have not changed. The changes are only set if the validator succeeds; otherwise, the fields keep their
+// values, and the error is reported to the error callback of Refresh, or returned, wrapping ErrValidation. Parse
+// returns an error wrapping ErrFieldNotFound if any of the IDs is not that of a field.
+//
+// Validators are called from the refresh goroutines, concurrently with each other and with the readers of the
+// configuration, which keep reading the previous values until the validation succeeds.
+func WithValidator(v Validator, ids ...string) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, validator{v: v, ids: ids})
+	}
+}
+
+// validator is a Validator registered with WithValidator, with the IDs of the fields it validates.
+type validator struct {
+	v   Validator
+	ids []string
+}
+
+// checkValidators checks that the fields the validators are registered for exist.
+func (o *options) checkValidators(fields []fieldInfo) error {
+	for _, v := range o.validators {
+		for _, id := range v.ids {
+			if !slices.ContainsFunc(fields, func(f fieldInfo) bool { return f.options.id == id }) {
+				return fmt.Errorf("validator: %w: %s", ErrFieldNotFound, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// candidate is a value found on refresh for a field, to be validated before it is set.
+type candidate struct {
+	f      *refreshedField
+	source Source
+	key    string
+	value  string
+}
+
+// validate calls the validators of the fields whose values have changed, and returns the errors of the fields whose
+// changes are rejected.
+func (u *updater) validate(ctx context.Context, candidates []candidate) (rejected map[*refreshedField]error) {
+	if len(u.opts.validators) == 0 {
+		return
+	}
+
+	// Decode the values that have changed; those that fail to decode are left for set to report
+	changed := make(map[string]any)
+	changes := make(map[string]candidate)
+	for _, c := range candidates {
+		u.m.Lock()
+		unchanged := u.opts.hashValue(c.value) == c.f.valueHash
+		u.m.Unlock()
+		if unchanged || u.isRejected(c.f, c.value) {
+			continue
+		}
+
+		decoded, err := u.opts.transform(ctx, c.f.field, c.value)
+		if err != nil {
+			continue
+		}
+		updated := reflect.New(c.f.field.structField.Type()).Elem()
+		if err = decodeFieldValue(false, decoded, updated, c.f.field.options); err != nil {
+			continue
+		}
+
+		id := c.f.field.options.id
+		changed[id] = updated.Interface()
+		changes[id] = c
+	}
+
+	// Call the validators of the fields that have changed concurrently
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	for _, v := range u.opts.validators {
+		if !slices.ContainsFunc(v.ids, func(id string) bool { _, ok := changed[id]; return ok }) {
+			continue
+		}
+
+		values := make(map[string]any, len(v.ids))
+		for _, id := range v.ids {
+			if value, ok := changed[id]; ok {
+				values[id] = value
+			} else if f := u.field(id); f != nil {
+				f.rlocker.Lock()
+				values[id] = f.field.structField.Interface()
+				f.rlocker.Unlock()
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := v.v.Validate(ctx, values)
+			if err == nil {
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+
+			if rejected == nil {
+				rejected = make(map[*refreshedField]error)
+			}
+			for _, id := range v.ids {
+				c, ok := changes[id]
+				if !ok || rejected[c.f] != nil {
+					continue
+				}
+
+				u.opts.logger.WarnContext(ctx, "refreshed field value rejected by validator", "field", id,
+					"source", c.source.ID(), "parameter", c.key, "value", c.f.field.logValue(c.value), "error", err)
+				rejected[c.f] = c.f.field.fieldError(StageRefresh, c.source, c.key, fmt.Errorf("%w: %w",
+					ErrValidation, err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	return
+}
+
+// field returns the field with the given ID, or nil if there is none.
+func (u *updater) field(id string) *refreshedField {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	for _, f := range u.fields {
+		if f.field.options.id == id {
+			return f
+		}
+	}
+
+	return nil
+}
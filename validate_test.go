@@ -0,0 +1,136 @@
+package skyconf
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestWithValidator(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/app/host": "db1", "/app/port": "5432", "/app/timeout": "30"},
+		path:        "/app/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Host    string `sky:"host,refresh:1m"`
+		Port    int    `sky:"port,refresh:1m"`
+		Timeout int    `sky:"timeout,refresh:1m"`
+	}{}
+
+	errUnreachable := errors.New("unreachable")
+	var m sync.Mutex
+	var validated []map[string]any
+	connect := ValidatorFunc(func(_ context.Context, values map[string]any) error {
+		m.Lock()
+		defer m.Unlock()
+
+		validated = append(validated, values)
+		if values["host"] == "bad" {
+			return errUnreachable
+		}
+		return nil
+	})
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithValidator(connect, "host", "port"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The validator is not called when parsing, nor for other fields
+	source.set("/app/timeout", "60")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 60, cfg.Timeout)
+	assert.Empty(t, validated)
+
+	// The validator is given the new values, and the current values of the fields that have not changed
+	source.set("/app/port", "5433")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Equal(t, 5433, cfg.Port)
+	assert.Equal(t, []map[string]any{{"host": "db1", "port": 5433}}, validated)
+
+	// The fields rejected by the validator keep their values
+	source.set("/app/host", "bad")
+	source.set("/app/port", "5434")
+	source.set("/app/timeout", "90")
+	err = r.RefreshOnce(context.Background())
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, err, errUnreachable)
+	assert.Equal(t, "db1", cfg.Host)
+	assert.Equal(t, 5433, cfg.Port)
+	assert.Equal(t, map[string]any{"host": "bad", "port": 5434}, validated[1])
+
+	var fe *FieldError
+	if assert.ErrorAs(t, err, &fe) {
+		assert.Equal(t, StageRefresh, fe.Stage)
+	}
+
+	assert.ErrorIs(t, r.RefreshNow(context.Background()), ErrValidation)
+	assert.Equal(t, "db1", cfg.Host)
+
+	// The changes are set once validated
+	source.set("/app/host", "db2")
+	assert.NoError(t, r.RefreshNow(context.Background()))
+	assert.Equal(t, "db2", cfg.Host)
+	assert.Equal(t, 5434, cfg.Port)
+	assert.Equal(t, 90, cfg.Timeout)
+}
+
+func TestWithValidatorPaths(t *testing.T) {
+	source := &mockSource{
+		ps:          mockParameterStore{"/app/host": "db1", "/app/region": "eu"},
+		path:        "/app/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Host   string `sky:"host,refresh:1m"`
+		Region string `sky:"region,refresh:1m,manual"`
+	}{}
+
+	var m sync.Mutex
+	bad := "bad"
+	notBad := ValidatorFunc(func(_ context.Context, values map[string]any) error {
+		m.Lock()
+		defer m.Unlock()
+
+		for _, v := range values {
+			if v == bad {
+				return errors.New("bad value")
+			}
+		}
+		return nil
+	})
+
+	// Validators of unknown fields are reported
+	_, err := ParseWithOptions(context.Background(), cfg, []Source{source}, WithValidator(notBad, "hots"))
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+
+	r, err := ParseWithOptions(context.Background(), cfg, []Source{source},
+		WithValidator(notBad, "host"), WithValidator(notBad, "region"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The values received are validated
+	assert.ErrorIs(t, r.Receive(context.Background(), "mock", map[string]string{"/app/host": "bad"}), ErrValidation)
+	assert.Equal(t, "db1", cfg.Host)
+	assert.NoError(t, r.Receive(context.Background(), "mock", map[string]string{"/app/host": "db2"}))
+	assert.Equal(t, "db2", cfg.Host)
+
+	// The staged changes are validated again when applied, and remain staged if rejected
+	source.set("/app/region", "us")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+	assert.Len(t, r.Pending(), 1)
+
+	m.Lock()
+	bad = "us"
+	m.Unlock()
+
+	assert.ErrorIs(t, r.Apply("region"), ErrValidation)
+	assert.Equal(t, "eu", cfg.Region)
+	assert.Len(t, r.Pending(), 1)
+}
@@ -0,0 +1,88 @@
+package skyconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrFieldNotFound is returned when no field with the specified ID exists in the configuration struct.
+var ErrFieldNotFound = errors.New("field not found")
+
+// ErrFieldType is returned when a field is not of the requested type.
+var ErrFieldType = errors.New("field is not of the requested type")
+
+// Watch returns a channel that receives the new value of the field with the given ID each time the field is updated
+// by the Refresher. The value is read while holding the lock of the configuration struct, if it is lockable; its read
+// lock if it implements RLocker. If the receiver falls behind, intermediate values are skipped in favour of the latest
+// one. The channel is closed when the context passed to Refresh is cancelled.
+func Watch[T any](r Refresher, id string) (<-chan T, error) {
+	u, ok := r.(*updater)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFieldNotFound, id)
+	}
+
+	// Find the field using its ID
+	var field *refreshedField
+	for _, f := range u.fields {
+		if f.field.options.id == id {
+			field = f
+			break
+		}
+	}
+
+	if field == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFieldNotFound, id)
+	}
+
+	// Ensure the value of the field can be converted to the requested type
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if !field.field.structField.Type().AssignableTo(t) {
+		return nil, fmt.Errorf("%w: %s is %s, not %s", ErrFieldType, id, field.field.structField.Type(), t)
+	}
+
+	out := make(chan T)
+
+	// Register the watcher with the updater
+	w := make(chan struct{}, 1)
+	u.updatesM.Lock()
+	if u.closed {
+		u.updatesM.Unlock()
+		close(out)
+		return out, nil
+	}
+	if u.watchers == nil {
+		u.watchers = make(map[string][]chan struct{})
+	}
+	u.watchers[id] = append(u.watchers[id], w)
+	u.updatesM.Unlock()
+
+	value := func() T {
+		field.rlocker.Lock()
+		defer field.rlocker.Unlock()
+
+		// Convert the value, as a field of a named type is assignable to its unnamed underlying type, but not asserted
+		return field.field.structField.Convert(t).Interface().(T)
+	}
+
+	go func() {
+		defer close(out)
+
+		for range w {
+			// Send the latest value; if the field is updated again meanwhile, send the newer value instead.
+			for pending := true; pending; {
+				pending = false
+				select {
+				case out <- value():
+				case _, ok := <-w:
+					if !ok {
+						return
+					}
+					pending = true
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,110 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	source := &mockSource{
+		ps: mockParameterStore{
+			"/path/param1": "value1",
+			"/path/param2": "1",
+		},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Param1 string `sky:",refresh:1m"`
+		Param2 int    `sky:",refresh:1m,id:count"`
+		sync.Mutex
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = Watch[string](r, "unknown")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+
+	_, err = Watch[string](r, "count")
+	assert.ErrorIs(t, err, ErrFieldType)
+
+	counts, err := Watch[int](r, "count")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Refresh(ctx, nil)
+
+	source.set("/path/param2", "2")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	select {
+	case v := <-counts:
+		assert.Equal(t, 2, v)
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for the value")
+	}
+
+	// The channel is closed when the refresh stops
+	cancel()
+	select {
+	case _, ok := <-counts:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for the channel to close")
+	}
+
+	// Watching after the refresh has stopped returns a closed channel
+	closed, err := Watch[string](r, "Param1")
+	if assert.NoError(t, err) {
+		_, ok := <-closed
+		assert.False(t, ok)
+	}
+}
+
+func TestWatchNamedType(t *testing.T) {
+	type hosts []string
+
+	source := &mockSource{
+		ps:          mockParameterStore{"/path/hosts": "a;b"},
+		path:        "/path/",
+		refreshable: true,
+	}
+
+	cfg := &struct {
+		Hosts hosts `sky:"hosts,refresh:1m"`
+	}{}
+
+	r, err := Parse(context.Background(), cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// A field of a named type can be watched as its underlying type
+	updates, err := Watch[[]string](r, "hosts")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Refresh(ctx, nil)
+
+	source.set("/path/hosts", "c")
+	assert.NoError(t, r.RefreshOnce(context.Background()))
+
+	select {
+	case v := <-updates:
+		assert.Equal(t, []string{"c"}, v)
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for the value")
+	}
+}
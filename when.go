@@ -0,0 +1,116 @@
+package skyconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrCondition is returned when the `when` tag of a field refers to no other field, compares it with a value that is
+// not of its type, or when fields are conditional on each other in a cycle.
+var ErrCondition = errors.New("invalid when condition")
+
+// condition is the condition on the value of another field for a field to be fetched; see the `when` tag.
+type condition struct {
+	field  int           // the index of the field the condition is on
+	value  reflect.Value // the value the field is compared with
+	negate bool          // the condition holds if the values differ
+}
+
+// holds returns true if the condition holds for the current value of the field it is on.
+func (c condition) holds(fields []fieldInfo) bool {
+	return reflect.DeepEqual(fields[c.field].structField.Interface(), c.value.Interface()) != c.negate
+}
+
+// parseCondition splits the value of a `when` tag into the name of the field and the value it is compared with, and
+// tells whether they must differ, as in "TLSEnabled!=false". ok is false if the condition is malformed.
+func parseCondition(when string) (name, value string, negate, ok bool) {
+	if name, value, ok = strings.Cut(when, "!="); ok {
+		negate = true
+	} else {
+		name, value, ok = strings.Cut(when, "=")
+	}
+
+	name = strings.TrimSpace(name)
+	return name, value, negate, ok && name != ""
+}
+
+// fieldConditions resolves the conditions of the fields tagged with `when`, keyed by the index of the field, and
+// returns the round each field is fetched in: 0 for the fields without a condition, and the round after that of the
+// field its condition is on otherwise, so that the conditions are evaluated once the values they depend on are known.
+// The field a condition is on is named relative to the struct enclosing the field, as in "TLSEnabled" or
+// "TLS.Enabled".
+func fieldConditions(fields []fieldInfo) (conditions map[int]condition, rounds []int, err error) {
+	paths := make(map[string]int, len(fields))
+	for i, field := range fields {
+		paths[field.path] = i
+	}
+
+	conditions = make(map[int]condition)
+	for i, field := range fields {
+		if field.options.when == "" {
+			continue
+		}
+
+		name, value, negate, _ := parseCondition(field.options.when)
+		if dot := strings.LastIndex(field.path, "."); dot >= 0 {
+			name = field.path[:dot+1] + name
+		}
+
+		c := condition{negate: negate}
+		var ok bool
+		if c.field, ok = paths[name]; !ok || c.field == i {
+			err = fmt.Errorf("%w: field %s is conditional on unknown field %s", ErrCondition, field.path, name)
+			return
+		}
+
+		on := fields[c.field]
+		c.value = reflect.New(on.structField.Type()).Elem()
+		if e := decodeFieldValue(false, value, c.value, on.options); e != nil {
+			err = fmt.Errorf("%w: field %s compares %s with %q: %w", ErrCondition, field.path, name, value, e)
+			return
+		}
+
+		conditions[i] = c
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(fields))
+	rounds = make([]int, len(fields))
+
+	var visit func(i int, chain []string) error
+	visit = func(i int, chain []string) error {
+		c, conditional := conditions[i]
+		switch {
+		case state[i] == visited:
+			return nil
+		case state[i] == visiting:
+			return fmt.Errorf("%w: cycle %s", ErrCondition, strings.Join(append(chain, fields[i].path), " -> "))
+		case !conditional:
+			state[i] = visited
+			return nil
+		}
+
+		state[i] = visiting
+		if err := visit(c.field, append(chain, fields[i].path)); err != nil {
+			return err
+		}
+		state[i] = visited
+
+		rounds[i] = rounds[c.field] + 1
+		return nil
+	}
+
+	for i := range fields {
+		if err = visit(i, nil); err != nil {
+			return
+		}
+	}
+
+	return
+}
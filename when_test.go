@@ -0,0 +1,88 @@
+package skyconf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWhen(t *testing.T) {
+	type tls struct {
+		Enabled  bool   `sky:"enabled"`
+		CertFile string `sky:"cert_file,when:Enabled=true"`
+		KeyFile  string `sky:"key_file,when:Enabled=true"`
+		Mode     string `sky:"mode,when:CertFile!=,default:strict"`
+	}
+	type config struct {
+		Auth       string `sky:"auth"`
+		TLS        tls    `sky:"tls"`
+		AuthSecret string `sky:"auth_secret,when:Auth=token"`
+	}
+
+	// The conditional fields are required when their conditions hold
+	source := &mockSource{id: "ssm", path: "/", ps: mockParameterStore{
+		"/auth":          "token",
+		"/auth_secret":   "s3cr3t",
+		"/tls/enabled":   "true",
+		"/tls/cert_file": "/etc/tls/cert.pem",
+		"/tls/key_file":  "/etc/tls/key.pem",
+		"/tls/mode":      "lax",
+	}}
+	var cfg config
+	_, err := Parse(context.Background(), &cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, config{
+		Auth:       "token",
+		TLS:        tls{Enabled: true, CertFile: "/etc/tls/cert.pem", KeyFile: "/etc/tls/key.pem", Mode: "lax"},
+		AuthSecret: "s3cr3t",
+	}, cfg)
+
+	delete(source.ps, "/tls/key_file")
+	_, err = Parse(context.Background(), &config{}, false, source)
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+
+	// Otherwise, they are neither required nor fetched, keeping their defaults
+	source.ps["/auth"] = "none"
+	source.ps["/tls/enabled"] = "false"
+	cfg = config{}
+	r, err := Parse(context.Background(), &cfg, false, source)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, config{Auth: "none", TLS: tls{Mode: "strict"}}, cfg)
+	assert.Equal(t, ProvenanceZero, r.Status()[2].Provenance)
+
+	_, options, err := ParseTag("cert_file,when:Enabled=true")
+	assert.NoError(t, err)
+	assert.Equal(t, "Enabled=true", options.When)
+}
+
+func TestWhenErrors(t *testing.T) {
+	source := &mockSource{id: "ssm", path: "/", ps: mockParameterStore{}}
+
+	var unknown struct {
+		A string `sky:"a,optional,when:Missing=true"`
+	}
+	_, err := Parse(context.Background(), &unknown, false, source)
+	assert.ErrorIs(t, err, ErrCondition)
+
+	var mismatched struct {
+		Enabled bool   `sky:"enabled,optional"`
+		A       string `sky:"a,optional,when:Enabled=yes please"`
+	}
+	_, err = Parse(context.Background(), &mismatched, false, source)
+	assert.ErrorIs(t, err, ErrCondition)
+
+	var cycle struct {
+		A string `sky:"a,optional,when:B=x"`
+		B string `sky:"b,optional,when:A=y"`
+	}
+	_, err = Parse(context.Background(), &cycle, false, source)
+	assert.ErrorIs(t, err, ErrCondition)
+	assert.ErrorContains(t, err, "cycle A -> B -> A")
+
+	_, _, err = ParseTag("a,when:Enabled")
+	assert.ErrorContains(t, err, `invalid condition "Enabled"`)
+}